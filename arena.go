@@ -0,0 +1,237 @@
+package jsonutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var arenaPool = sync.Pool{
+	New: func() interface{} {
+		return &Arena{}
+	},
+}
+
+// Arena decode JSON into interface{} (the same value as json.Unmarshal produce),
+// but the maps and slices backing storage is reused across calls.
+// Decoded value is only valid until Release is called, after that it must not be used anymore.
+type Arena struct {
+	maps     []map[string]interface{}
+	usedMaps int
+	elems    []interface{}
+	stack    []interface{}
+	buf      bytes.Buffer
+
+	useNumber bool // decode number as json.Number, see Config.UseNumber
+}
+
+// AcquireArena return Arena from the pool, call Release when done.
+func AcquireArena() *Arena {
+	return arenaPool.Get().(*Arena)
+}
+
+// Release clear the arena and put it back to the pool.
+func (a *Arena) Release() {
+	for i := 0; i < a.usedMaps; i++ {
+		for key := range a.maps[i] {
+			delete(a.maps[i], key)
+		}
+	}
+
+	for i := range a.elems {
+		a.elems[i] = nil
+	}
+
+	a.usedMaps = 0
+	a.elems = a.elems[:0]
+	a.stack = a.stack[:0]
+	a.buf.Reset()
+	a.useNumber = false
+	arenaPool.Put(a)
+}
+
+// Decode decode doc using storage from the arena.
+// Number is decoded as float64, same as json.Unmarshal into interface{}.
+func (a *Arena) Decode(doc []byte) (interface{}, error) {
+	start, err := scanDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	v, _, err := a.decode(doc, start)
+	return v, err
+}
+
+func (a *Arena) decode(data []byte, i int) (interface{}, int, error) {
+	switch data[i] {
+	case '{':
+		m := a.newMap()
+		var err error
+		end, scanErr := scanObject(data, i, func(key string, member objectMember) bool {
+			m[key], _, err = a.decode(data, member.valueStart)
+			return err == nil
+		})
+		if scanErr != nil {
+			return nil, end, scanErr
+		}
+		return m, end, err
+
+	case '[':
+		base := len(a.stack)
+		var err error
+		end, scanErr := scanArray(data, i, func(idx, start, end int) bool {
+			var v interface{}
+			v, _, err = a.decode(data, start)
+			a.stack = append(a.stack, v)
+			return err == nil
+		})
+
+		s := a.newSlice(len(a.stack) - base)
+		copy(s, a.stack[base:])
+		for n := base; n < len(a.stack); n++ {
+			a.stack[n] = nil
+		}
+		a.stack = a.stack[:base]
+
+		if scanErr != nil {
+			return nil, end, scanErr
+		}
+		return s, end, err
+
+	case '"':
+		end, err := scanString(data, i)
+		if err != nil {
+			return nil, end, err
+		}
+
+		str, err := unquote(data[i:end])
+		return str, end, err
+
+	case 't':
+		return true, i + 4, nil
+
+	case 'f':
+		return false, i + 5, nil
+
+	case 'n':
+		return nil, i + 4, nil
+	}
+
+	end, err := scanNumber(data, i)
+	if err != nil {
+		return nil, end, err
+	}
+
+	if a.useNumber {
+		return json.Number(data[i:end]), end, nil
+	}
+
+	f, err := strconv.ParseFloat(string(data[i:end]), 64)
+	return f, end, err
+}
+
+func (a *Arena) newMap() map[string]interface{} {
+	if a.usedMaps == len(a.maps) {
+		a.maps = append(a.maps, make(map[string]interface{}))
+	}
+
+	m := a.maps[a.usedMaps]
+	a.usedMaps++
+	return m
+}
+
+// newSlice return slice of length n from the arena backing storage.
+// When the storage is full, a new (larger) one is used, previously returned slices still point to the old one.
+func (a *Arena) newSlice(n int) []interface{} {
+	if n == 0 {
+		// must not be nil, otherwise it is encoded as null
+		return []interface{}{}
+	}
+
+	size := len(a.elems)
+	if size+n > cap(a.elems) {
+		a.elems = make([]interface{}, 0, 2*cap(a.elems)+n)
+		size = 0
+	}
+
+	a.elems = a.elems[:size+n]
+	return a.elems[size : size+n : size+n]
+}
+
+// PooledBytes is the output of Transformer.TransformBytesPooled.
+type PooledBytes struct {
+	arena *Arena
+}
+
+// Bytes return the transformed JSON, it is only valid until Release is called.
+func (p *PooledBytes) Bytes() []byte {
+	return p.arena.buf.Bytes()
+}
+
+// Release put the memory back to the pool.
+func (p *PooledBytes) Release() {
+	p.arena.Release()
+	p.arena = nil
+}
+
+// TransformBytesPooled is like TransformBytes but decode and encode using pooled memory from Arena,
+// for services which call it many times per second and spend most of the time in GC.
+// Config.UseNumber, Metrics and Stats apply the same as in TransformBytes.
+// When Config.JSONMarshal or JSONUnmarshal is set, it is used instead of the arena, and only the output buffer is pooled.
+// Call Release on the result when the bytes is no longer needed.
+func (m *Transformer) TransformBytesPooled(ctx context.Context, b []byte) (*PooledBytes, error) {
+	if _, nop := m.Config.Metrics.(NopMetrics); m.Config.Metrics == nil || nop {
+		return m.transformBytesPooled(ctx, b)
+	}
+
+	begin := time.Now()
+	changed := 0
+	out, err := m.counting(&changed).transformBytesPooled(ctx, b)
+	observe(m.Config.Metrics, b, begin, changed, 0, err)
+	return out, err
+}
+
+func (m *Transformer) transformBytesPooled(ctx context.Context, b []byte) (*PooledBytes, error) {
+	arena := AcquireArena()
+	if err := m.transformArena(ctx, arena, b); err != nil {
+		arena.Release()
+		return nil, err
+	}
+
+	return &PooledBytes{arena: arena}, nil
+}
+
+// transformArena transform b and write the output into arena.buf.
+func (m *Transformer) transformArena(ctx context.Context, arena *Arena, b []byte) error {
+	if m.codec {
+		out, err := m.transformBytes(ctx, b)
+		if err != nil {
+			return err
+		}
+
+		_, err = arena.buf.Write(out)
+		return err
+	}
+
+	arena.useNumber = m.Config.UseNumber
+	data, err := arena.Decode(b)
+	if err != nil {
+		return err
+	}
+
+	out, err := m.inPlace().transform(ctx, data)
+	if err != nil {
+		return err
+	}
+
+	if err = json.NewEncoder(&arena.buf).Encode(out); err != nil {
+		return err
+	}
+
+	// Encoder always add newline after the value
+	arena.buf.Truncate(arena.buf.Len() - 1)
+	return nil
+}
@@ -0,0 +1,120 @@
+package jsonutil_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestArena_Decode(t *testing.T) {
+	for _, in := range []string{largeArray, allJSONType, nestedObject100, `[[1,[2,[]]],{"a":[{}]}]`, `"str"`, `null`} {
+		var expected interface{}
+		assert.NoError(t, json.Unmarshal([]byte(in), &expected))
+
+		arena := jsonutil.AcquireArena()
+		actual, err := arena.Decode([]byte(in))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+		arena.Release()
+	}
+
+	arena := jsonutil.AcquireArena()
+	defer arena.Release()
+
+	_, err := arena.Decode([]byte(`{"a":}`))
+	assert.Error(t, err)
+
+	// the error of nested value is not lost
+	for _, in := range []string{`{"a":1e400}`, `[1,[1e400]]`} {
+		_, err = arena.Decode([]byte(in))
+		assert.Error(t, err, in)
+	}
+}
+
+func TestTransformer_TransformBytesPooled(t *testing.T) {
+	mask := jsonutil.NewTransformer(jsonutil.Config{StringTransformer: transformer([]string{"email", "handle"})})
+
+	for i := 0; i < 3; i++ {
+		expected, err := mask.TransformBytes(context.Background(), []byte(largeArray))
+		assert.NoError(t, err)
+
+		out, err := mask.TransformBytesPooled(context.Background(), []byte(largeArray))
+		assert.NoError(t, err)
+		assert.Equal(t, string(expected), string(out.Bytes()))
+		out.Release()
+	}
+
+	_, err := mask.TransformBytesPooled(context.Background(), []byte(`[`))
+	assert.Error(t, err)
+}
+
+func TestTransformer_TransformBytesPooled_Config(t *testing.T) {
+	const doc = `{"id":9007199254740993,"email":"a@b.c"}`
+	metrics := &testMetrics{}
+	mask := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: transformer([]string{"email"}),
+		UseNumber:         true,
+		Metrics:           metrics,
+	})
+
+	expected, err := mask.TransformBytes(context.Background(), []byte(doc))
+	assert.NoError(t, err)
+
+	out, err := mask.TransformBytesPooled(context.Background(), []byte(doc))
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(out.Bytes()))
+	assert.Contains(t, string(out.Bytes()), `"id":9007199254740993`)
+	out.Release()
+
+	_, err = mask.TransformBytesPooled(context.Background(), []byte(`[`))
+	assert.Error(t, err)
+
+	// TransformBytes and TransformBytesPooled each report one document
+	assert.Equal(t, 2, metrics.documents)
+	assert.Equal(t, 2, metrics.masked)
+	assert.Len(t, metrics.errors, 1)
+
+	// the custom decoder is used instead of the arena
+	calls := 0
+	custom := jsonutil.NewTransformer(jsonutil.Config{
+		JSONUnmarshal: func(data []byte, v interface{}) error {
+			calls++
+			return json.Unmarshal(data, v)
+		},
+	})
+
+	out, err = custom.TransformBytesPooled(context.Background(), []byte(doc))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"email":"a@b.c","id":9007199254740992}`, string(out.Bytes()))
+	assert.Equal(t, 1, calls)
+	out.Release()
+}
+
+func BenchmarkTransformer_TransformBytesPooled(b *testing.B) {
+	mask := jsonutil.NewTransformer(jsonutil.Config{StringTransformer: transformer([]string{"email", "handle"})})
+	in := []byte(largeArray)
+
+	b.Run("TransformBytes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, err := mask.TransformBytes(context.Background(), in)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("TransformBytesPooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			out, err := mask.TransformBytesPooled(context.Background(), in)
+			if err != nil {
+				b.Fatal(err)
+			}
+			out.Release()
+		}
+	})
+}
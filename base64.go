@@ -0,0 +1,125 @@
+package jsonutil
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"unicode/utf8"
+)
+
+// DecodeBase64Fields return Processor which decode base64 string value of keys (in any level).
+// When the decoded content is JSON object or array, it is inlined as the real value, so the next processor
+// (i.e: masking or truncation) can see inside it, otherwise it is replaced with the decoded text.
+// Decoded scalar such as "123" or "true" is kept as text, the same as Config.DecodeBase64JSON.
+// Value which is not valid base64 or not valid UTF-8 after decoded is kept as is.
+// Use EncodeBase64Fields with the same keys to wrap it back.
+func DecodeBase64Fields(keys ...string) Processor {
+	set := keySet(keys)
+	return ProcessorFunc(func(ctx context.Context, doc []byte) ([]byte, error) {
+		var data interface{}
+		if err := decodeDocument(doc, &data); err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(walkKeys(data, set, decodeBase64Value))
+	})
+}
+
+// EncodeBase64Fields return Processor which encode the value of keys (in any level) as base64 string.
+// String value is encoded as is, any other value except null is encoded in its JSON form.
+func EncodeBase64Fields(keys ...string) Processor {
+	set := keySet(keys)
+	return ProcessorFunc(func(ctx context.Context, doc []byte) ([]byte, error) {
+		var data interface{}
+		if err := decodeDocument(doc, &data); err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(walkKeys(data, set, encodeBase64Value))
+	})
+}
+
+func keySet(keys []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		set[key] = struct{}{}
+	}
+
+	return set
+}
+
+// walkKeys replace the value of every object member which key in keys using fn.
+// The replaced value is not walked further.
+func walkKeys(data interface{}, keys map[string]struct{}, fn func(v interface{}) interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if _, ok := keys[key]; ok {
+				v[key] = fn(val)
+				continue
+			}
+
+			v[key] = walkKeys(val, keys, fn)
+		}
+
+	case []interface{}:
+		for i, val := range v {
+			v[i] = walkKeys(val, keys, fn)
+		}
+	}
+
+	return data
+}
+
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding,
+}
+
+func decodeBase64Value(v interface{}) interface{} {
+	str, ok := v.(string)
+	if !ok {
+		return v
+	}
+
+	for _, enc := range base64Encodings {
+		b, err := enc.DecodeString(str)
+		if err != nil {
+			continue
+		}
+
+		var nested interface{}
+		if isContainer(b) && decodeDocument(b, &nested) == nil {
+			return nested
+		}
+
+		if utf8.Valid(b) {
+			return string(b)
+		}
+
+		return v
+	}
+
+	return v
+}
+
+// isContainer return true when doc starts with JSON object or array, after whitespace.
+func isContainer(doc []byte) bool {
+	start := skipSpace(doc, 0)
+	return start < len(doc) && (doc[start] == '{' || doc[start] == '[')
+}
+
+func encodeBase64Value(v interface{}) interface{} {
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return base64.StdEncoding.EncodeToString([]byte(val))
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+
+	return base64.StdEncoding.EncodeToString(b)
+}
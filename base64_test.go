@@ -0,0 +1,72 @@
+package jsonutil_test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestBase64Fields(t *testing.T) {
+	nested := base64.StdEncoding.EncodeToString([]byte(`{"password":"secret","id":12345678901234567890}`))
+	text := base64.RawURLEncoding.EncodeToString([]byte(`hello world`))
+	scalar := base64.StdEncoding.EncodeToString([]byte(`123`))
+	in := []byte(`{"data":"` + nested + `","items":[{"data":"` + text + `"}],"other":"` + nested + `","bad":{"data":"not base64!"},"num":{"data":1},"scalar":{"data":"` + scalar + `"}}`)
+
+	p := jsonutil.NewPipeline(
+		jsonutil.DecodeBase64Fields("data"),
+		jsonutil.NewSanitizer(jsonutil.SanitizerConfig{MaskKeys: []string{"password"}}),
+	)
+
+	out, err := p.Process(context.Background(), in)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"data": {"password":"***","id":12345678901234567890},
+		"items": [{"data":"hello world"}],
+		"other": "`+nested+`",
+		"bad": {"data":"not base64!"},
+		"num": {"data":1},
+		"scalar": {"data":"123"}
+	}`, string(out))
+
+	out, err = jsonutil.EncodeBase64Fields("data").Process(context.Background(), out)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"data": "`+base64.StdEncoding.EncodeToString([]byte(`{"id":12345678901234567890,"password":"***"}`))+`",
+		"items": [{"data":"`+base64.StdEncoding.EncodeToString([]byte(`hello world`))+`"}],
+		"other": "`+nested+`",
+		"bad": {"data":"`+base64.StdEncoding.EncodeToString([]byte(`not base64!`))+`"},
+		"num": {"data":"MQ=="},
+		"scalar": {"data":"`+scalar+`"}
+	}`, string(out))
+
+	_, err = jsonutil.DecodeBase64Fields("data").Process(context.Background(), []byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestTransformer_DecodeBase64JSON(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		Keys: map[string]jsonutil.StringTransformer{
+			"password": func(ctx context.Context, info jsonutil.KVInfo) string { return "xxx" },
+		},
+		Paths: map[string]jsonutil.StringTransformer{
+			"$.data.name": func(ctx context.Context, info jsonutil.KVInfo) string { return "nnn" },
+		},
+		DecodeBase64JSON: true,
+	})
+
+	std := base64.StdEncoding.EncodeToString([]byte(`{"password":"a","name":"b","id":12345678901234567890}`))
+	rawURL := base64.RawURLEncoding.EncodeToString([]byte(`[{"password":"??>"}]`))
+	unchanged := base64.StdEncoding.EncodeToString([]byte(`{"id":1}`))
+	in := `{"data":"` + std + `","list":["` + rawURL + `"],"same":"` + unchanged + `","word":"eyes","text":"Wow"}`
+
+	out, err := tr.TransformBytes(context.Background(), []byte(in))
+	assert.NoError(t, err)
+
+	expect := `{"data":"` + base64.StdEncoding.EncodeToString([]byte(`{"id":12345678901234567890,"name":"nnn","password":"xxx"}`)) + `",` +
+		`"list":["` + base64.RawURLEncoding.EncodeToString([]byte(`[{"password":"xxx"}]`)) + `"],` +
+		`"same":"` + unchanged + `","word":"eyes","text":"Wow"}`
+	assert.JSONEq(t, expect, string(out))
+}
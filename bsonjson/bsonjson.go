@@ -0,0 +1,131 @@
+package bsonjson
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/yusufsyaifudin/jsonutil"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// Masker apply the jsonutil.RuleSet rules to BSON document directly, without converting it through JSON,
+// so the value which is not matched by any rule (ObjectID, date, binary, decimal, etc) is kept as is, byte by byte.
+// The rules are applied the same way as RuleSet.Process:
+// mask replace the value with the placeholder string, hash replace it with "sha256:<hex>"
+// (of the string, or of the raw BSON value bytes for other type), drop remove the element,
+// and truncate apply to every string value under the matched path.
+type Masker struct {
+	rules *jsonutil.RuleSet
+}
+
+// NewMasker return Masker using rules.
+func NewMasker(rules *jsonutil.RuleSet) *Masker {
+	return &Masker{rules: rules}
+}
+
+// MaskBSON return the sanitized copy of raw, i.e: the full document of MongoDB change stream event.
+func (m *Masker) MaskBSON(ctx context.Context, raw bson.Raw) (bson.Raw, error) {
+	if err := raw.Validate(); err != nil {
+		return nil, err
+	}
+
+	idx, dst := bsoncore.AppendDocumentStart(nil)
+	dst, err := m.appendElements(dst, bsoncore.Document(raw), true, []string{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dst, err = bsoncore.AppendDocumentEnd(dst, idx)
+	return bson.Raw(dst), err
+}
+
+// appendElements append the sanitized elements of doc (document or array) into dst.
+// Array element is renumbered, so dropping an element doesn't leave a hole.
+func (m *Masker) appendElements(dst []byte, doc bsoncore.Document, isDoc bool, segments []string, inherited *jsonutil.Rule) ([]byte, error) {
+	elems, err := doc.Elements()
+	if err != nil {
+		return nil, err
+	}
+
+	n := 0
+	for i, elem := range elems {
+		key := elem.Key()
+		if !isDoc {
+			key = strconv.Itoa(i)
+		}
+
+		outKey := key
+		if !isDoc {
+			outKey = strconv.Itoa(n)
+		}
+
+		var drop bool
+		dst, drop, err = m.appendValue(dst, outKey, elem.Value(), isDoc, append(segments, key), inherited)
+		if err != nil {
+			return nil, err
+		}
+
+		if !drop {
+			n++
+		}
+	}
+
+	return dst, nil
+}
+
+func (m *Masker) appendValue(dst []byte, key string, v bsoncore.Value, isKey bool, segments []string, inherited *jsonutil.Rule) ([]byte, bool, error) {
+	str, isString := v.StringValueOK()
+
+	if rule, ok := m.rules.Match(segments, isKey, isString); ok {
+		switch rule.Action {
+		case jsonutil.ActionDrop:
+			return dst, true, nil
+
+		case jsonutil.ActionMask:
+			return bsoncore.AppendStringElement(dst, key, rule.Placeholder), false, nil
+
+		case jsonutil.ActionHash:
+			b := v.Data
+			if isString {
+				b = []byte(str)
+			}
+
+			sum := sha256.Sum256(b)
+			return bsoncore.AppendStringElement(dst, key, "sha256:"+hex.EncodeToString(sum[:])), false, nil
+
+		case jsonutil.ActionTruncate:
+			inherited = &rule
+		}
+	}
+
+	switch v.Type {
+	case bsontype.EmbeddedDocument, bsontype.Array:
+		isDoc := v.Type == bsontype.EmbeddedDocument
+
+		var idx int32
+		if isDoc {
+			idx, dst = bsoncore.AppendDocumentElementStart(dst, key)
+		} else {
+			idx, dst = bsoncore.AppendArrayElementStart(dst, key)
+		}
+
+		dst, err := m.appendElements(dst, bsoncore.Document(v.Data), isDoc, segments, inherited)
+		if err != nil {
+			return nil, false, err
+		}
+
+		dst, err = bsoncore.AppendDocumentEnd(dst, idx)
+		return dst, false, err
+
+	case bsontype.String:
+		if inherited != nil {
+			return bsoncore.AppendStringElement(dst, key, jsonutil.TruncateString(str, inherited.MaxChars)), false, nil
+		}
+	}
+
+	return bsoncore.AppendValueElement(dst, key, v), false, nil
+}
@@ -0,0 +1,87 @@
+package bsonjson_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"github.com/yusufsyaifudin/jsonutil/bsonjson"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMasker_MaskBSON(t *testing.T) {
+	rules, err := jsonutil.NewRuleSet([]jsonutil.Rule{
+		{ID: "credentials", Keys: []string{"password"}, Action: jsonutil.ActionMask},
+		{ID: "email", Paths: []string{"users.*.email"}, Action: jsonutil.ActionHash},
+		{ID: "debug", Keys: []string{"debug"}, Action: jsonutil.ActionDrop},
+		{ID: "body", Paths: []string{"body"}, Action: jsonutil.ActionTruncate, MaxChars: 3},
+	})
+	assert.NoError(t, err)
+
+	id := primitive.NewObjectID()
+	createdAt := primitive.NewDateTimeFromTime(time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC))
+	bin := primitive.Binary{Subtype: 0x80, Data: []byte{1, 2, 3}}
+
+	raw, err := bson.Marshal(bson.D{
+		{Key: "_id", Value: id},
+		{Key: "password", Value: "secret"},
+		{Key: "users", Value: bson.A{
+			bson.D{{Key: "email", Value: "alice@example.com"}, {Key: "created_at", Value: createdAt}},
+		}},
+		{Key: "body", Value: bson.D{{Key: "text", Value: "hello"}, {Key: "list", Value: bson.A{"world", bin}}}},
+		{Key: "tags", Value: bson.A{bson.D{{Key: "debug", Value: true}}, "a"}},
+		{Key: "debug", Value: bson.D{{Key: "stack", Value: "trace"}}},
+	})
+	assert.NoError(t, err)
+
+	out, err := bsonjson.NewMasker(rules).MaskBSON(context.Background(), raw)
+	assert.NoError(t, err)
+
+	var got bson.D
+	assert.NoError(t, bson.Unmarshal(out, &got))
+	assert.Equal(t, bson.D{
+		{Key: "_id", Value: id},
+		{Key: "password", Value: "***"},
+		{Key: "users", Value: bson.A{
+			bson.D{
+				{Key: "email", Value: "sha256:ff8d9819fc0e12bf0d24892e45987e249a28dce836a85cad60e28eaaa8c6d976"},
+				{Key: "created_at", Value: createdAt},
+			},
+		}},
+		{Key: "body", Value: bson.D{
+			{Key: "text", Value: jsonutil.TruncateString("hello", 3)},
+			{Key: "list", Value: bson.A{jsonutil.TruncateString("world", 3), bin}},
+		}},
+		{Key: "tags", Value: bson.A{bson.D{}, "a"}},
+	}, got)
+}
+
+func TestMasker_MaskBSON_DropArrayElement(t *testing.T) {
+	rules, err := jsonutil.NewRuleSet([]jsonutil.Rule{
+		{ID: "second", Paths: []string{"list.1"}, Action: jsonutil.ActionDrop},
+	})
+	assert.NoError(t, err)
+
+	raw, err := bson.Marshal(bson.D{{Key: "list", Value: bson.A{"a", "b", "c"}}})
+	assert.NoError(t, err)
+
+	out, err := bsonjson.NewMasker(rules).MaskBSON(context.Background(), raw)
+	assert.NoError(t, err)
+
+	var got struct {
+		List []string `bson:"list"`
+	}
+	assert.NoError(t, bson.Unmarshal(out, &got))
+	assert.Equal(t, []string{"a", "c"}, got.List)
+}
+
+func TestMasker_MaskBSON_Invalid(t *testing.T) {
+	rules, err := jsonutil.NewRuleSet(nil)
+	assert.NoError(t, err)
+
+	_, err = bsonjson.NewMasker(rules).MaskBSON(context.Background(), bson.Raw{1, 2, 3})
+	assert.Error(t, err)
+}
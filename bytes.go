@@ -0,0 +1,316 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrPathNotFound is returned when the path does not exist in the document.
+var ErrPathNotFound = errors.New("jsonutil: path not found")
+
+// GetBytes return the value on path without decoding the whole document.
+// It scans doc and stops as soon as the value is found, so the rest of document is not validated.
+// Path is in dotted form (items.0.name) or JSON Pointer (/items/0/name), see SplitPath.
+func GetBytes(doc []byte, path string) (Value, error) {
+	raw, err := GetRawBytes(doc, path)
+	if err != nil {
+		return Value{}, err
+	}
+
+	var v Value
+	err = v.UnmarshalJSON(raw)
+	return v, err
+}
+
+// GetRawBytes is like GetBytes but return the raw JSON bytes of the value.
+// The returned slice shares the memory with doc.
+func GetRawBytes(doc []byte, path string) ([]byte, error) {
+	start, end, err := locate(doc, SplitPath(path))
+	if err != nil {
+		return nil, err
+	}
+
+	return doc[start:end], nil
+}
+
+// Coalesce return the value of the first path which exists and is not null,
+// useful when the same data appears under different (legacy) field names, i.e: Coalesce(doc, "user_id", "userId", "uid").
+// It returns ErrPathNotFound when none of the paths has non-null value.
+func Coalesce(doc []byte, paths ...string) (Value, error) {
+	for _, path := range paths {
+		raw, err := GetRawBytes(doc, path)
+		if err == ErrPathNotFound || (err == nil && string(raw) == "null") {
+			continue
+		}
+
+		if err != nil {
+			return Value{}, err
+		}
+
+		var v Value
+		err = v.UnmarshalJSON(raw)
+		return v, err
+	}
+
+	return Value{}, ErrPathNotFound
+}
+
+// locate return the start and end offset of value on segments.
+func locate(doc []byte, segments []string) (start, end int, err error) {
+	start = skipSpace(doc, 0)
+	if len(segments) == 0 {
+		end, err = scanValue(doc, start)
+		return
+	}
+
+	if start >= len(doc) {
+		return 0, 0, syntaxErr(doc, start, "")
+	}
+
+	for _, seg := range segments {
+		start, end, err = locateChild(doc, start, seg)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// locateChild find segment seg inside the object or array value starts at offset i.
+// Any other value type has no child, so it always return ErrPathNotFound.
+func locateChild(doc []byte, i int, seg string) (start, end int, err error) {
+	found := false
+	switch doc[i] {
+	case '{':
+		_, err = scanObject(doc, i, func(key string, m objectMember) bool {
+			if key == seg {
+				start, end, found = m.valueStart, m.valueEnd, true
+			}
+			return !found
+		})
+
+	case '[':
+		idx, convErr := strconv.Atoi(seg)
+		if convErr != nil || idx < 0 {
+			return 0, 0, ErrPathNotFound
+		}
+
+		_, err = scanArray(doc, i, func(n, s, e int) bool {
+			if n == idx {
+				start, end, found = s, e, true
+			}
+			return !found
+		})
+	}
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if !found {
+		return 0, 0, ErrPathNotFound
+	}
+
+	return start, end, nil
+}
+
+// SetBytes set value on path and return the new document.
+// Only the part of the document on path is rewritten, so the formatting and key order of the rest is preserved.
+// Missing object keys are created along the way, use index "-1" or index equal to array length to append into array.
+// Missing "-1" segment create a new array, every other missing segment (including "0") create an object key.
+// When value is json.RawMessage, it is inserted as is after validated, otherwise it is encoded using json.Marshal.
+func SetBytes(doc []byte, path string, value interface{}) ([]byte, error) {
+	raw, err := marshalRaw(value)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := SplitPath(path)
+	start, end, depth, err := locateDeepest(doc, segments)
+	if err != nil {
+		return nil, err
+	}
+
+	// path exists, replace the value
+	if depth == len(segments) {
+		return splice(doc, start, end, raw), nil
+	}
+
+	// build the missing part from the innermost, i.e: set a.b.c into {} produce {"a":{"b":{"c":value}}}
+	for i := len(segments) - 1; i > depth; i-- {
+		if isAppendIndex(segments[i]) {
+			raw = append(append([]byte("["), raw...), ']')
+			continue
+		}
+
+		key, _ := marshalRaw(segments[i])
+		raw = append(append(append([]byte("{"), key...), ':'), append(raw[:len(raw):len(raw)], '}')...)
+	}
+
+	seg := segments[depth]
+	closing := end - 1
+	isEmpty := skipSpace(doc, start+1) == closing
+
+	var insert []byte
+	switch doc[start] {
+	case '{':
+		key, _ := marshalRaw(seg)
+		insert = append(append(key, ':'), raw...)
+
+	case '[':
+		length := 0
+		_, _ = scanArray(doc, start, func(idx, s, e int) bool {
+			length = idx + 1
+			return true
+		})
+
+		if seg != "-1" && seg != strconv.Itoa(length) {
+			return nil, fmt.Errorf("jsonutil: array index %s out of range on path %q, array length is %d", seg, path, length)
+		}
+
+		insert = raw
+
+	default:
+		return nil, fmt.Errorf("jsonutil: cannot set %q, parent value is not object or array", path)
+	}
+
+	if !isEmpty {
+		insert = append([]byte(","), insert...)
+	}
+
+	return splice(doc, closing, closing, insert), nil
+}
+
+// locateDeepest locate the deepest existing value on segments.
+// The depth is the number of segments found, equal to len(segments) when the whole path exists.
+func locateDeepest(doc []byte, segments []string) (start, end, depth int, err error) {
+	start = skipSpace(doc, 0)
+	end, err = scanValue(doc, start)
+	if err != nil {
+		return
+	}
+
+	for depth < len(segments) {
+		s, e, _err := locateChild(doc, start, segments[depth])
+		if _err == ErrPathNotFound {
+			return
+		}
+
+		if _err != nil {
+			err = _err
+			return
+		}
+
+		start, end = s, e
+		depth++
+	}
+
+	return
+}
+
+// isAppendIndex return true when the missing segment seg create a new array instead of object.
+func isAppendIndex(seg string) bool {
+	return seg == "-1"
+}
+
+func marshalRaw(value interface{}) ([]byte, error) {
+	if raw, ok := value.(json.RawMessage); ok {
+		if !json.Valid(raw) {
+			return nil, fmt.Errorf("jsonutil: invalid json.RawMessage value")
+		}
+
+		return raw, nil
+	}
+
+	return json.Marshal(value)
+}
+
+// splice return new slice of doc with doc[start:end] replaced by insert.
+func splice(doc []byte, start, end int, insert []byte) []byte {
+	out := make([]byte, 0, len(doc)-(end-start)+len(insert))
+	out = append(out, doc[:start]...)
+	out = append(out, insert...)
+	out = append(out, doc[end:]...)
+	return out
+}
+
+// ExistsBytes return true if path exists in doc.
+// Invalid doc is treated as path not exists.
+func ExistsBytes(doc []byte, path string) bool {
+	_, _, err := locate(doc, SplitPath(path))
+	return err == nil
+}
+
+// DeleteBytes remove the value on path (including its key when inside an object) and return the new document.
+// Same as SetBytes, the rest of the document is kept as is.
+// When the path does not exist, doc is returned unchanged without error.
+func DeleteBytes(doc []byte, path string) ([]byte, error) {
+	segments := SplitPath(path)
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("jsonutil: cannot delete the whole document")
+	}
+
+	parentStart, _, err := locate(doc, segments[:len(segments)-1])
+	if err == ErrPathNotFound {
+		return doc, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	seg := segments[len(segments)-1]
+	found := false
+	start, end := 0, 0
+	switch doc[parentStart] {
+	case '{':
+		_, err = scanObject(doc, parentStart, func(key string, m objectMember) bool {
+			if key == seg {
+				start, end, found = m.keyStart, m.valueEnd, true
+			}
+			return !found
+		})
+
+	case '[':
+		idx, convErr := strconv.Atoi(seg)
+		if convErr != nil {
+			return doc, nil
+		}
+
+		_, err = scanArray(doc, parentStart, func(n, s, e int) bool {
+			if n == idx {
+				start, end, found = s, e, true
+			}
+			return !found
+		})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return doc, nil
+	}
+
+	// remove the separator comma too: prefer the trailing one, otherwise the leading one
+	if next := skipSpace(doc, end); next < len(doc) && doc[next] == ',' {
+		end = skipSpace(doc, next+1)
+	} else if prev := skipSpaceBackward(doc, start-1); doc[prev] == ',' {
+		start = prev
+	}
+
+	return splice(doc, start, end, nil), nil
+}
+
+// skipSpaceBackward return the first offset from i going backward which is not a whitespace.
+func skipSpaceBackward(data []byte, i int) int {
+	for i > 0 && isSpace(data[i]) {
+		i--
+	}
+
+	return i
+}
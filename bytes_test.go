@@ -0,0 +1,246 @@
+package jsonutil_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+const sampleBytesDoc = `{
+	"user": {"name": "alice", "a.b": true, "esc\"key": 1},
+	"items": [{"id": 10}, {"id": 20, "tags": ["x", "y"]}],
+	"null": null
+}`
+
+func TestGetBytes(t *testing.T) {
+	type testCase struct {
+		Path   string
+		Expect interface{}
+	}
+
+	testCases := []testCase{
+		{Path: "user.name", Expect: "alice"},
+		{Path: `user.a\.b`, Expect: true},
+		{Path: `user.esc"key`, Expect: float64(1)},
+		{Path: "items.1.id", Expect: float64(20)},
+		{Path: "/items/1/tags/0", Expect: "x"},
+		{Path: "null", Expect: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Path, func(t *testing.T) {
+			v, err := jsonutil.GetBytes([]byte(sampleBytesDoc), tc.Path)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expect, v.Interface())
+		})
+	}
+
+	t.Run("whole document", func(t *testing.T) {
+		raw, err := jsonutil.GetRawBytes([]byte(` [1, 2] `), "")
+		assert.NoError(t, err)
+		assert.Equal(t, `[1, 2]`, string(raw))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		for _, path := range []string{"user.email", "items.2", "items.x", "user.name.first"} {
+			_, err := jsonutil.GetBytes([]byte(sampleBytesDoc), path)
+			assert.Equal(t, jsonutil.ErrPathNotFound, err, path)
+		}
+	})
+
+	t.Run("stop scanning after found", func(t *testing.T) {
+		v, err := jsonutil.GetBytes([]byte(`{"a":1,"b":`), "a")
+		assert.NoError(t, err)
+		assert.Equal(t, "1", v.String())
+	})
+
+	t.Run("invalid document", func(t *testing.T) {
+		_, err := jsonutil.GetBytes([]byte(`{"a":tru}`), "a")
+		assert.Error(t, err)
+
+		_, err = jsonutil.GetBytes([]byte(``), "a")
+		assert.Error(t, err)
+	})
+}
+
+func TestSetBytes(t *testing.T) {
+	type testCase struct {
+		Name   string
+		Doc    string
+		Path   string
+		Value  interface{}
+		Expect string
+	}
+
+	testCases := []testCase{
+		{
+			Name:   "replace preserving formatting",
+			Doc:    "{\n  \"b\": 1,\n  \"a\": \"old\"\n}",
+			Path:   "a",
+			Value:  "new",
+			Expect: "{\n  \"b\": 1,\n  \"a\": \"new\"\n}",
+		},
+		{
+			Name:   "add key to object",
+			Doc:    `{"b": 1}`,
+			Path:   "a",
+			Value:  []int{1},
+			Expect: `{"b": 1,"a":[1]}`,
+		},
+		{
+			Name:   "add key to empty object",
+			Doc:    `{ }`,
+			Path:   "a",
+			Value:  true,
+			Expect: `{ "a":true}`,
+		},
+		{
+			Name:   "create missing parents",
+			Doc:    `{"a":{}}`,
+			Path:   "a.b.c",
+			Value:  1,
+			Expect: `{"a":{"b":{"c":1}}}`,
+		},
+		{
+			Name:   "append into array",
+			Doc:    `{"a":[1,2]}`,
+			Path:   "a.-1",
+			Value:  3,
+			Expect: `{"a":[1,2,3]}`,
+		},
+		{
+			Name:   "append into array using length",
+			Doc:    `{"a":[]}`,
+			Path:   "a.0",
+			Value:  map[string]string{"x": "y"},
+			Expect: `{"a":[{"x":"y"}]}`,
+		},
+		{
+			Name:   "create missing array",
+			Doc:    `{}`,
+			Path:   "a.-1.b",
+			Value:  1,
+			Expect: `{"a":[{"b":1}]}`,
+		},
+		{
+			Name:   "missing index 0 is object key",
+			Doc:    `{}`,
+			Path:   "a.0",
+			Value:  1,
+			Expect: `{"a":{"0":1}}`,
+		},
+		{
+			Name:   "replace array element",
+			Doc:    `[1, 2, 3]`,
+			Path:   "1",
+			Value:  json.RawMessage(`{"raw": null}`),
+			Expect: `[1, {"raw": null}, 3]`,
+		},
+		{
+			Name:   "replace whole document",
+			Doc:    `{"a":1}`,
+			Path:   "",
+			Value:  "x",
+			Expect: `"x"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			out, err := jsonutil.SetBytes([]byte(tc.Doc), tc.Path, tc.Value)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expect, string(out))
+			assert.True(t, json.Valid(out))
+		})
+	}
+
+	t.Run("value buffer is not modified", func(t *testing.T) {
+		// spare capacity after the value must not be written
+		buf := make([]byte, 0, 16)
+		buf = append(buf, `"v"`...)
+		value := json.RawMessage(buf)
+
+		out, err := jsonutil.SetBytes([]byte(`{}`), "a.b", value)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":{"b":"v"}}`, string(out))
+		assert.Equal(t, byte(0), buf[:cap(buf)][len(buf)])
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := jsonutil.SetBytes([]byte(`{"a":[1]}`), "a.5", 1)
+		assert.Error(t, err)
+
+		_, err = jsonutil.SetBytes([]byte(`{"a":1}`), "a.b", 1)
+		assert.Error(t, err)
+
+		_, err = jsonutil.SetBytes([]byte(`{"a":1}`), "a", json.RawMessage(`{`))
+		assert.Error(t, err)
+
+		_, err = jsonutil.SetBytes([]byte(`{"a":1`), "a", 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestCoalesce(t *testing.T) {
+	v, err := jsonutil.Coalesce([]byte(sampleBytesDoc), "user.email", "null", "user.name", "items.0.id")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", v.Interface())
+
+	v, err = jsonutil.Coalesce([]byte(sampleBytesDoc), "/items/1/id")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(20), v.Interface())
+
+	_, err = jsonutil.Coalesce([]byte(sampleBytesDoc), "user.email", "null")
+	assert.Equal(t, jsonutil.ErrPathNotFound, err)
+
+	_, err = jsonutil.Coalesce([]byte(sampleBytesDoc))
+	assert.Equal(t, jsonutil.ErrPathNotFound, err)
+
+	_, err = jsonutil.Coalesce([]byte(`{"a":null,"b":tru}`), "a", "b")
+	assert.Error(t, err)
+}
+
+func TestExistsBytes(t *testing.T) {
+	assert.True(t, jsonutil.ExistsBytes([]byte(sampleBytesDoc), "items.1.tags.1"))
+	assert.True(t, jsonutil.ExistsBytes([]byte(sampleBytesDoc), "null"))
+	assert.False(t, jsonutil.ExistsBytes([]byte(sampleBytesDoc), "items.1.tags.2"))
+	assert.False(t, jsonutil.ExistsBytes([]byte(`{"a":`), "a"))
+}
+
+func TestDeleteBytes(t *testing.T) {
+	type testCase struct {
+		Name   string
+		Doc    string
+		Path   string
+		Expect string
+	}
+
+	testCases := []testCase{
+		{Name: "first key", Doc: `{"a": 1, "b": 2}`, Path: "a", Expect: `{"b": 2}`},
+		{Name: "last key", Doc: `{"a": 1, "b": 2}`, Path: "b", Expect: `{"a": 1}`},
+		{Name: "only key", Doc: `{ "a": {"x": [1]} }`, Path: "a", Expect: `{  }`},
+		{Name: "nested key", Doc: `{"a":{"b":1,"c":2,"d":3}}`, Path: "a.c", Expect: `{"a":{"b":1,"d":3}}`},
+		{Name: "array element", Doc: `[1, 2, 3]`, Path: "1", Expect: `[1, 3]`},
+		{Name: "last array element", Doc: `[1, 2, 3]`, Path: "/2", Expect: `[1, 2]`},
+		{Name: "not found", Doc: `{"a":1}`, Path: "b.c", Expect: `{"a":1}`},
+		{Name: "not found index", Doc: `[1]`, Path: "x", Expect: `[1]`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			out, err := jsonutil.DeleteBytes([]byte(tc.Doc), tc.Path)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expect, string(out))
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		_, err := jsonutil.DeleteBytes([]byte(`{"a":1}`), "")
+		assert.Error(t, err)
+
+		_, err = jsonutil.DeleteBytes([]byte(`{"a":1,`), "b")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,166 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Case is the naming convention used by ConvertKeys.
+type Case int
+
+const (
+	SnakeCase  Case = iota // snake_case
+	CamelCase              // camelCase
+	PascalCase             // PascalCase
+	KebabCase              // kebab-case
+)
+
+// ConvertKeys rewrites every object key in doc into the given naming convention.
+// Keys listed in exclude are kept as is, and so are their object values,
+// this is useful when some part of the payload (such as free form metadata) must not be touched.
+// Number values are kept as is, so int64 IDs will not lose precision.
+// When two keys of the same object become the same key, i.e: "user_id" and "userId" in SnakeCase,
+// ErrKeyCollision is returned instead of keeping one of the values.
+func ConvertKeys(doc []byte, convention Case, exclude ...string) ([]byte, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[string]struct{}, len(exclude))
+	for _, key := range exclude {
+		excluded[key] = struct{}{}
+	}
+
+	converted, err := convertKeys(data, convention, excluded, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(converted)
+}
+
+func convertKeys(data interface{}, convention Case, excluded map[string]struct{}, path []string) (interface{}, error) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		newMap := make(map[string]interface{}, len(v))
+		origin := make(map[string]string, len(v)) // new key to the original key
+		for key, val := range v {
+			newKey := key
+			if _, skip := excluded[key]; !skip {
+				newKey = ToCase(key, convention)
+
+				var err error
+				if val, err = convertKeys(val, convention, excluded, append(path, key)); err != nil {
+					return nil, err
+				}
+			}
+
+			if other, exist := origin[newKey]; exist {
+				// sorted, so the error is the same regardless of the map iteration order
+				first, second := other, key
+				if first > second {
+					first, second = second, first
+				}
+
+				return nil, fmt.Errorf("%w: %s and %s renamed to %q",
+					ErrKeyCollision, JoinPath(append(path[:len(path):len(path)], first)), JoinPath(append(path[:len(path):len(path)], second)), newKey)
+			}
+
+			origin[newKey] = key
+			newMap[newKey] = val
+		}
+
+		return newMap, nil
+
+	case []interface{}:
+		newSlices := make([]interface{}, len(v))
+		for i, val := range v {
+			converted, err := convertKeys(val, convention, excluded, append(path, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+
+			newSlices[i] = converted
+		}
+
+		return newSlices, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// ToCase converts single string into the given naming convention.
+// Word boundaries are detected on underscore, dash, space, dot and the change of letter case,
+// i.e: "userID", "user_id", "user-id" and "UserId" is all become "user_id" in SnakeCase.
+func ToCase(str string, convention Case) string {
+	words := splitWords(str)
+	if len(words) == 0 {
+		return str
+	}
+
+	switch convention {
+	case SnakeCase:
+		return strings.Join(words, "_")
+
+	case KebabCase:
+		return strings.Join(words, "-")
+
+	case CamelCase, PascalCase:
+		var sb strings.Builder
+		for i, word := range words {
+			if i == 0 && convention == CamelCase {
+				sb.WriteString(word)
+				continue
+			}
+
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			sb.WriteString(string(runes))
+		}
+
+		return sb.String()
+	}
+
+	return str
+}
+
+// splitWords split str into lowercase words.
+func splitWords(str string) []string {
+	runes := []rune(str)
+	words := make([]string, 0)
+	current := make([]rune, 0, len(runes))
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ' || r == '.':
+			flush()
+			continue
+
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			// "userID" split before I, "HTTPServer" split before S
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+
+		current = append(current, r)
+	}
+
+	flush()
+	return words
+}
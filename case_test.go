@@ -0,0 +1,67 @@
+package jsonutil_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestToCase(t *testing.T) {
+	type testCase struct {
+		Input  string
+		Case   jsonutil.Case
+		Expect string
+	}
+
+	testCases := []testCase{
+		{Input: "user_id", Case: jsonutil.CamelCase, Expect: "userId"},
+		{Input: "userID", Case: jsonutil.SnakeCase, Expect: "user_id"},
+		{Input: "HTTPServer", Case: jsonutil.KebabCase, Expect: "http-server"},
+		{Input: "user-name", Case: jsonutil.PascalCase, Expect: "UserName"},
+		{Input: "UserName", Case: jsonutil.CamelCase, Expect: "userName"},
+		{Input: "address2line", Case: jsonutil.SnakeCase, Expect: "address2line"},
+		{Input: "_", Case: jsonutil.SnakeCase, Expect: "_"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Input, func(t *testing.T) {
+			assert.Equal(t, tc.Expect, jsonutil.ToCase(tc.Input, tc.Case))
+		})
+	}
+}
+
+func TestConvertKeys(t *testing.T) {
+	t.Run("nested object and array", func(t *testing.T) {
+		in := `{"user_id":9007199254740993,"user_profile":{"first_name":"a"},"items":[{"item_name":"b"}]}`
+		out, err := jsonutil.ConvertKeys([]byte(in), jsonutil.CamelCase)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"items":[{"itemName":"b"}],"userId":9007199254740993,"userProfile":{"firstName":"a"}}`, string(out))
+	})
+
+	t.Run("excluded key", func(t *testing.T) {
+		in := `{"userId":1,"rawMeta":{"someKey":"a"}}`
+		out, err := jsonutil.ConvertKeys([]byte(in), jsonutil.SnakeCase, "rawMeta")
+		assert.NoError(t, err)
+		assert.Equal(t, `{"rawMeta":{"someKey":"a"},"user_id":1}`, string(out))
+	})
+
+	t.Run("key collision", func(t *testing.T) {
+		for _, in := range []string{`{"user_id":1,"userId":2}`, `{"userId":2,"user_id":1}`} {
+			out, err := jsonutil.ConvertKeys([]byte(in), jsonutil.SnakeCase)
+			assert.True(t, errors.Is(err, jsonutil.ErrKeyCollision), in)
+			assert.EqualError(t, err, `jsonutil: key collision: userId and user_id renamed to "user_id"`)
+			assert.Nil(t, out)
+		}
+
+		_, err := jsonutil.ConvertKeys([]byte(`{"items":[{"a":1},{"a-b":1,"a_b":2}]}`), jsonutil.CamelCase)
+		assert.EqualError(t, err, `jsonutil: key collision: items.1.a-b and items.1.a_b renamed to "aB"`)
+	})
+
+	t.Run("invalid json", func(t *testing.T) {
+		out, err := jsonutil.ConvertKeys([]byte(`{`), jsonutil.SnakeCase)
+		assert.Error(t, err)
+		assert.Nil(t, out)
+	})
+}
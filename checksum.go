@@ -0,0 +1,131 @@
+package jsonutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// DefaultChecksumField is the default field name of the checksum.
+const DefaultChecksumField = "_checksum"
+
+// ErrChecksumMismatch is returned by Checksum.Verify when the document is changed after the checksum is added.
+var ErrChecksumMismatch = errors.New("jsonutil: checksum mismatch")
+
+type ChecksumConfig struct {
+	// Field is the top-level field to store the hex checksum, default to DefaultChecksumField.
+	Field string
+
+	// Paths is the selected paths included in the checksum, missing path is skipped.
+	// Empty means the whole document except the checksum field itself.
+	Paths []string
+
+	// Hash is the hash function, default to sha256.New. Any hash.Hash can be used, i.e: xxhash for speed.
+	Hash func() hash.Hash
+}
+
+// Checksum add integrity checksum field into JSON object, so tampering can be detected downstream using Verify.
+// The checksum is computed over the canonical form (see Canonicalize) of the selected paths,
+// so it is not affected by key order and whitespace.
+type Checksum struct {
+	field string
+	paths []string
+	hash  func() hash.Hash
+}
+
+func NewChecksum(conf ChecksumConfig) *Checksum {
+	if conf.Field == "" {
+		conf.Field = DefaultChecksumField
+	}
+
+	if conf.Hash == nil {
+		conf.Hash = sha256.New
+	}
+
+	return &Checksum{field: conf.Field, paths: conf.Paths, hash: conf.Hash}
+}
+
+// Process set the checksum field on doc, replacing the old one if any. The top-level value must be an object.
+func (c *Checksum) Process(ctx context.Context, doc []byte) ([]byte, error) {
+	sum, err := c.Sum(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return SetBytes(doc, JoinPointer([]string{c.field}), sum)
+}
+
+// Verify return nil when the checksum field of doc matches its content, otherwise ErrChecksumMismatch.
+// Like Sum, document with duplicate keys is rejected with ErrDuplicateKey.
+func (c *Checksum) Verify(doc []byte) error {
+	expected, err := GetBytes(doc, JoinPointer([]string{c.field}))
+	if err == ErrPathNotFound {
+		return fmt.Errorf("%w: field %q not found", ErrChecksumMismatch, c.field)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	sum, err := c.Sum(doc)
+	if err != nil {
+		return err
+	}
+
+	if sum != expected.String() {
+		return ErrChecksumMismatch
+	}
+
+	return nil
+}
+
+// Sum return the hex checksum of doc.
+// Document with duplicate keys is rejected with ErrDuplicateKey, because the reader may see the other value
+// than the one included in the checksum.
+func (c *Checksum) Sum(doc []byte) (string, error) {
+	var data interface{}
+	if err := decodeUnambiguous(doc, &data); err != nil {
+		return "", err
+	}
+
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("jsonutil: checksum requires object, got %s", typeOf(data))
+	}
+
+	selected := obj
+	if len(c.paths) > 0 {
+		selected = make(map[string]interface{}, len(c.paths))
+		for _, path := range c.paths {
+			raw, err := GetRawBytes(doc, path)
+			if err == ErrPathNotFound {
+				continue
+			}
+
+			if err != nil {
+				return "", err
+			}
+
+			var v interface{}
+			if err = decodeDocument(raw, &v); err != nil {
+				return "", err
+			}
+
+			selected[path] = v
+		}
+	} else {
+		delete(selected, c.field)
+	}
+
+	canonical, err := canonicalJSON(selected)
+	if err != nil {
+		return "", err
+	}
+
+	h := c.hash()
+	h.Write(canonical)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
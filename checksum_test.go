@@ -0,0 +1,81 @@
+package jsonutil_test
+
+import (
+	"context"
+	"crypto/md5"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestChecksum(t *testing.T) {
+	c := jsonutil.NewChecksum(jsonutil.ChecksumConfig{})
+
+	out, err := c.Process(context.Background(), []byte(`{"user":"alice","action":"delete","at":1700000000}`))
+	assert.NoError(t, err)
+
+	sum, err := jsonutil.GetBytes(out, jsonutil.DefaultChecksumField)
+	assert.NoError(t, err)
+	assert.Len(t, sum.String(), 64)
+	assert.NoError(t, c.Verify(out))
+
+	// processing again replaces the checksum with the same value
+	again, err := c.Process(context.Background(), out)
+	assert.NoError(t, err)
+	assert.Equal(t, string(out), string(again))
+
+	// reformatting does not change the checksum
+	reordered, err := jsonutil.SetBytes([]byte(`{"at": 1700000000, "action": "delete", "user": "alice"}`), "_checksum", sum.String())
+	assert.NoError(t, err)
+	assert.NoError(t, c.Verify(reordered))
+
+	tampered, err := jsonutil.SetBytes(out, "action", "read")
+	assert.NoError(t, err)
+	assert.ErrorIs(t, c.Verify(tampered), jsonutil.ErrChecksumMismatch)
+
+	assert.ErrorIs(t, c.Verify([]byte(`{"user":"alice"}`)), jsonutil.ErrChecksumMismatch)
+
+	_, err = c.Process(context.Background(), []byte(`[1]`))
+	assert.EqualError(t, err, "jsonutil: checksum requires object, got array")
+}
+
+func TestChecksum_Paths(t *testing.T) {
+	c := jsonutil.NewChecksum(jsonutil.ChecksumConfig{Field: "sig", Paths: []string{"user.id", "amount", "missing"}, Hash: md5.New})
+
+	out, err := c.Process(context.Background(), []byte(`{"user":{"id":1,"name":"alice"},"amount":100,"note":"x"}`))
+	assert.NoError(t, err)
+
+	sum, err := jsonutil.GetBytes(out, "sig")
+	assert.NoError(t, err)
+	assert.Len(t, sum.String(), 32)
+
+	// field outside the selected paths can change
+	changed, err := jsonutil.SetBytes(out, "note", "y")
+	assert.NoError(t, err)
+	assert.NoError(t, c.Verify(changed))
+
+	changed, err = jsonutil.SetBytes(out, "user.id", 2)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, c.Verify(changed), jsonutil.ErrChecksumMismatch)
+}
+
+func TestChecksum_DuplicateKey(t *testing.T) {
+	c := jsonutil.NewChecksum(jsonutil.ChecksumConfig{Paths: []string{"amount"}})
+
+	out, err := c.Process(context.Background(), []byte(`{"amount":1}`))
+	assert.NoError(t, err)
+
+	sum, err := jsonutil.GetBytes(out, jsonutil.DefaultChecksumField)
+	assert.NoError(t, err)
+
+	// json.Unmarshal read the last amount, while the checksum would cover the first one
+	tampered := []byte(`{"amount":1,"_checksum":"` + sum.String() + `","amount":1000}`)
+	assert.ErrorIs(t, c.Verify(tampered), jsonutil.ErrDuplicateKey)
+
+	_, err = c.Sum(tampered)
+	assert.ErrorIs(t, err, jsonutil.ErrDuplicateKey)
+
+	_, err = jsonutil.NewChecksum(jsonutil.ChecksumConfig{}).Process(context.Background(), []byte(`{"a":{"b":1,"b":2}}`))
+	assert.EqualError(t, err, "jsonutil: duplicate key: a.b")
+}
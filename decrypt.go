@@ -0,0 +1,142 @@
+package jsonutil
+
+import (
+	"context"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrDecrypt is returned when the encrypted value cannot be decrypted by any of the keys.
+var ErrDecrypt = errors.New("jsonutil: cannot decrypt value")
+
+type DecryptorConfig struct {
+	// KeySet is every key version which may be used by Encryptor.
+	KeySet KeySet
+
+	// KeepOnError keep the value encrypted when it cannot be decrypted, instead of returning error.
+	KeepOnError bool
+}
+
+// Decryptor restore the value encrypted by Encryptor.
+// Every string value in the document which starts with "enc:" is decrypted, so it does not need the keys or paths config.
+// The key version is found by trying every key from KeySet, AES-GCM authentication rejects the wrong one.
+// The value must stay on the path where it was encrypted, otherwise it cannot be decrypted.
+type Decryptor struct {
+	keySet      KeySet
+	keepOnError bool
+}
+
+func NewDecryptor(conf DecryptorConfig) (*Decryptor, error) {
+	if conf.KeySet == nil {
+		return nil, fmt.Errorf("jsonutil: decryptor key set is required")
+	}
+
+	return &Decryptor{keySet: conf.KeySet, keepOnError: conf.KeepOnError}, nil
+}
+
+// Process decrypt every encrypted value in doc.
+func (d *Decryptor) Process(ctx context.Context, doc []byte) ([]byte, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, err
+	}
+
+	keys, err := d.keySet.Keys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	aeads := make([]cipher.AEAD, len(keys))
+	for i, key := range keys {
+		if aeads[i], err = newGCM(key); err != nil {
+			return nil, err
+		}
+	}
+
+	out, err := d.walk(aeads, data, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(out)
+}
+
+func (d *Decryptor) walk(aeads []cipher.AEAD, data interface{}, segments []string) (interface{}, error) {
+	var err error
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			if v[key], err = d.walk(aeads, val, append(segments, key)); err != nil {
+				return nil, err
+			}
+		}
+
+	case []interface{}:
+		for i, val := range v {
+			if v[i], err = d.walk(aeads, val, append(segments, strconv.Itoa(i))); err != nil {
+				return nil, err
+			}
+		}
+
+	case string:
+		if !strings.HasPrefix(v, "enc:") {
+			return v, nil
+		}
+
+		plain, err := decrypt(aeads, v, []byte(JoinPointer(segments)))
+		if err != nil && d.keepOnError {
+			return v, nil
+		}
+
+		return plain, err
+	}
+
+	return data, nil
+}
+
+// decrypt return the decoded value of the encrypted string, pointer is the path where it was encrypted.
+func decrypt(aeads []cipher.AEAD, str string, pointer []byte) (interface{}, error) {
+	if !strings.HasPrefix(str, EncryptedPrefix) {
+		return nil, fmt.Errorf("%w: unknown envelope version %q", ErrDecrypt, strings.SplitN(str, ":", 3)[1])
+	}
+
+	parts := strings.Split(str[len(EncryptedPrefix):], ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("%w: malformed envelope", ErrDecrypt)
+	}
+
+	nonce, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed nonce", ErrDecrypt)
+	}
+
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed ciphertext", ErrDecrypt)
+	}
+
+	for _, aead := range aeads {
+		if len(nonce) != aead.NonceSize() {
+			continue
+		}
+
+		plaintext, err := aead.Open(nil, nonce, ciphertext, pointer)
+		if err != nil {
+			continue
+		}
+
+		var v interface{}
+		if err = decodeDocument(plaintext, &v); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+		}
+
+		return v, nil
+	}
+
+	return nil, ErrDecrypt
+}
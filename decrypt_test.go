@@ -0,0 +1,93 @@
+package jsonutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestDecryptor(t *testing.T) {
+	oldKey := []byte("old-key-old-key-old-key-old-key!")
+	newKey := []byte("new-key-new-key-new-key-new-key!")
+
+	in := []byte(`{"id":12345678901234567890,"ssn":"123-45-6789","card":{"number":"4111","exp":{"m":1,"y":30}}}`)
+
+	encOld, err := jsonutil.NewEncryptor(jsonutil.EncryptorConfig{Keys: []string{"ssn"}, KeyProvider: jsonutil.StaticKey(oldKey)})
+	assert.NoError(t, err)
+
+	encNew, err := jsonutil.NewEncryptor(jsonutil.EncryptorConfig{Paths: []string{"card.exp"}, KeyProvider: jsonutil.KeyRing{oldKey, newKey}})
+	assert.NoError(t, err)
+
+	encrypted, err := jsonutil.NewPipeline(encOld, encNew).Process(context.Background(), in)
+	assert.NoError(t, err)
+
+	dec, err := jsonutil.NewDecryptor(jsonutil.DecryptorConfig{KeySet: jsonutil.KeyRing{oldKey, newKey}})
+	assert.NoError(t, err)
+
+	out, err := dec.Process(context.Background(), encrypted)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(in), string(out))
+
+	// consumer without the old key is not authorized to read ssn
+	dec, err = jsonutil.NewDecryptor(jsonutil.DecryptorConfig{KeySet: jsonutil.StaticKey(newKey)})
+	assert.NoError(t, err)
+
+	_, err = dec.Process(context.Background(), encrypted)
+	assert.ErrorIs(t, err, jsonutil.ErrDecrypt)
+
+	dec, err = jsonutil.NewDecryptor(jsonutil.DecryptorConfig{KeySet: jsonutil.StaticKey(newKey), KeepOnError: true})
+	assert.NoError(t, err)
+
+	out, err = dec.Process(context.Background(), encrypted)
+	assert.NoError(t, err)
+
+	ssn, err := jsonutil.GetBytes(encrypted, "ssn")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":12345678901234567890,"ssn":"`+ssn.String()+`","card":{"number":"4111","exp":{"m":1,"y":30}}}`, string(out))
+}
+
+func TestDecryptor_MovedValue(t *testing.T) {
+	enc, err := jsonutil.NewEncryptor(jsonutil.EncryptorConfig{Keys: []string{"ssn"}, KeyProvider: testKey})
+	assert.NoError(t, err)
+
+	encrypted, err := enc.Process(context.Background(), []byte(`{"ssn":"123-45-6789","users":[{"ssn":"987-65-4321"}]}`))
+	assert.NoError(t, err)
+
+	dec, err := jsonutil.NewDecryptor(jsonutil.DecryptorConfig{KeySet: testKey})
+	assert.NoError(t, err)
+
+	out, err := dec.Process(context.Background(), encrypted)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"ssn":"123-45-6789","users":[{"ssn":"987-65-4321"}]}`, string(out))
+
+	// the value copied into another field is rejected by the authentication
+	ssn, err := jsonutil.GetBytes(encrypted, "ssn")
+	assert.NoError(t, err)
+
+	for _, path := range []string{"users.0.ssn", "note"} {
+		moved, err := jsonutil.SetBytes(encrypted, path, ssn)
+		assert.NoError(t, err)
+
+		_, err = dec.Process(context.Background(), moved)
+		assert.ErrorIs(t, err, jsonutil.ErrDecrypt, path)
+	}
+}
+
+func TestDecryptor_Malformed(t *testing.T) {
+	dec, err := jsonutil.NewDecryptor(jsonutil.DecryptorConfig{KeySet: testKey})
+	assert.NoError(t, err)
+
+	for _, in := range []string{`"enc:v2:a:b"`, `"enc:v1:abc"`, `"enc:v1:!!:abc"`, `"enc:v1:AAAAAAAAAAAAAAAA:abc"`} {
+		_, err = dec.Process(context.Background(), []byte(in))
+		assert.ErrorIs(t, err, jsonutil.ErrDecrypt, in)
+	}
+
+	out, err := dec.Process(context.Background(), []byte(`["plain","encoded"]`))
+	assert.NoError(t, err)
+	assert.Equal(t, `["plain","encoded"]`, string(out))
+
+	_, err = jsonutil.NewDecryptor(jsonutil.DecryptorConfig{})
+	assert.Error(t, err)
+}
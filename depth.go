@@ -0,0 +1,185 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// MaxDepth is the maximum nesting of object and array accepted by every function in this package
+// which read JSON bytes, such as TransformBytes, Sanitizer, RuleSet, GetBytes, Paths and Stats.
+// Document nested deeper than this is rejected with ErrMaxDepthExceeded, so the recursion is always bounded.
+var MaxDepth = 10000
+
+// ErrMaxDepthExceeded is returned when the document nesting is deeper than MaxDepth.
+var ErrMaxDepthExceeded = errors.New("jsonutil: exceeded max nesting depth")
+
+// ErrCyclicData is returned by Transform when the map or slice contains itself.
+var ErrCyclicData = errors.New("jsonutil: cyclic data")
+
+func depthErr(i int) error {
+	return fmt.Errorf("%w %d at offset %d", ErrMaxDepthExceeded, MaxDepth, i)
+}
+
+// checkDepth return ErrMaxDepthExceeded when doc is nested deeper than MaxDepth.
+// It only count the brackets outside of string, doc is not validated.
+func checkDepth(doc []byte) error {
+	depth := 0
+	for i := 0; i < len(doc); i++ {
+		switch doc[i] {
+		case '"':
+			end, err := scanString(doc, i)
+			if err != nil {
+				// let the decoder report the syntax error
+				return nil
+			}
+			i = end - 1
+
+		case '{', '[':
+			depth++
+			if depth > MaxDepth {
+				return depthErr(i)
+			}
+
+		case '}', ']':
+			depth--
+		}
+	}
+
+	return nil
+}
+
+// checkCycle return ErrCyclicData when the map or slice in v is one of its own ancestors.
+// Decoded JSON (map[string]interface{} and []interface{}) is walked without reflect.
+func checkCycle(v interface{}, ancestors map[uintptr]struct{}) error {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if len(val) == 0 {
+			return nil
+		}
+
+		ptr := reflect.ValueOf(v).Pointer()
+		if _, ok := ancestors[ptr]; ok {
+			return ErrCyclicData
+		}
+
+		ancestors[ptr] = struct{}{}
+		for _, child := range val {
+			if err := checkCycle(child, ancestors); err != nil {
+				return err
+			}
+		}
+
+		delete(ancestors, ptr)
+		return nil
+
+	case []interface{}:
+		if len(val) == 0 {
+			return nil
+		}
+
+		ptr := reflect.ValueOf(v).Pointer()
+		if _, ok := ancestors[ptr]; ok {
+			return ErrCyclicData
+		}
+
+		ancestors[ptr] = struct{}{}
+		for _, child := range val {
+			if err := checkCycle(child, ancestors); err != nil {
+				return err
+			}
+		}
+
+		delete(ancestors, ptr)
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice:
+		if rv.Len() == 0 {
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	ptr := rv.Pointer()
+	if _, ok := ancestors[ptr]; ok {
+		return ErrCyclicData
+	}
+
+	ancestors[ptr] = struct{}{}
+	defer delete(ancestors, ptr)
+
+	if rv.Kind() == reflect.Map {
+		iter := rv.MapRange()
+		for iter.Next() {
+			if err := checkCycle(iter.Value().Interface(), ancestors); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		if err := checkCycle(rv.Index(i).Interface(), ancestors); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// decodeDocument decode doc into data using json.Number for numbers, after checking MaxDepth.
+// Anything other than whitespace after the value is an error.
+func decodeDocument(doc []byte, data *interface{}) error {
+	if err := checkDepth(doc); err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	err := dec.Decode(data)
+
+	var jsonErr *json.SyntaxError
+	switch {
+	case errors.As(err, &jsonErr):
+		// json.SyntaxError offset is after the invalid byte was read
+		return newSyntaxError(doc, int(jsonErr.Offset)-1, jsonErr.Error())
+	case err == io.ErrUnexpectedEOF || err == io.EOF:
+		return syntaxErr(doc, len(doc), "")
+	case err != nil:
+		return err
+	}
+
+	if end := skipSpace(doc, int(dec.InputOffset())); end < len(doc) {
+		return syntaxErr(doc, end, "after top-level value")
+	}
+
+	return nil
+}
+
+// unmarshalUseNumber is json.Unmarshal which decode numbers as json.Number, see Config.UseNumber.
+func unmarshalUseNumber(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		if err == io.EOF {
+			return syntaxErr(data, len(data), "")
+		}
+
+		return err
+	}
+
+	// the same as json.Unmarshal, nothing but whitespace is allowed after the value
+	if i := skipSpace(data, int(dec.InputOffset())); i < len(data) {
+		return syntaxErr(data, i, "after top-level value")
+	}
+
+	return nil
+}
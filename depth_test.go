@@ -0,0 +1,159 @@
+package jsonutil_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestMaxDepth(t *testing.T) {
+	defer func(old int) { jsonutil.MaxDepth = old }(jsonutil.MaxDepth)
+	jsonutil.MaxDepth = 3
+
+	ok := []byte(`{"a":[{"b":"[[[[ {{{{"}]}`)
+	deep := []byte(`{"a":[{"b":["c"]}]}`)
+
+	sanitizer := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{})
+	transformer := jsonutil.NewTransformer(jsonutil.Config{})
+	ruleSet, err := jsonutil.NewRuleSet(nil)
+	assert.NoError(t, err)
+
+	entryPoints := map[string]func(doc []byte) error{
+		"TransformBytes": func(doc []byte) error {
+			_, err := transformer.TransformBytes(context.Background(), doc)
+			return err
+		},
+		"TransformBytesPooled": func(doc []byte) error {
+			out, err := transformer.TransformBytesPooled(context.Background(), doc)
+			if err == nil {
+				out.Release()
+			}
+			return err
+		},
+		"Sanitize": func(doc []byte) error {
+			_, err := sanitizer.Sanitize(context.Background(), doc)
+			return err
+		},
+		"RuleSet": func(doc []byte) error {
+			_, err := ruleSet.Process(context.Background(), doc)
+			return err
+		},
+		"ConvertKeys": func(doc []byte) error {
+			_, err := jsonutil.ConvertKeys(doc, jsonutil.SnakeCase)
+			return err
+		},
+		"Paths": func(doc []byte) error {
+			_, err := jsonutil.Paths(doc)
+			return err
+		},
+		"Shape": func(doc []byte) error {
+			_, err := jsonutil.Shape(doc)
+			return err
+		},
+		"Stats": func(doc []byte) error {
+			_, err := jsonutil.Stats(doc)
+			return err
+		},
+		"GetBytes": func(doc []byte) error {
+			_, err := jsonutil.GetBytes(doc, "a")
+			return err
+		},
+		"SetBytes": func(doc []byte) error {
+			_, err := jsonutil.SetBytes(doc, "x", 1)
+			return err
+		},
+	}
+
+	for name, fn := range entryPoints {
+		assert.NoError(t, fn(ok), name)
+
+		err := fn(deep)
+		assert.ErrorIs(t, err, jsonutil.ErrMaxDepthExceeded, name)
+	}
+
+	_, err = jsonutil.GetBytes(deep, "a.0.b")
+	assert.EqualError(t, err, "jsonutil: exceeded max nesting depth 3 at offset 11")
+}
+
+func TestMaxDepth_Default(t *testing.T) {
+	deep := []byte(strings.Repeat("[", jsonutil.MaxDepth+1) + strings.Repeat("]", jsonutil.MaxDepth+1))
+
+	_, err := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{}).Sanitize(context.Background(), deep)
+	assert.ErrorIs(t, err, jsonutil.ErrMaxDepthExceeded)
+}
+
+func TestTransformer_MaxDepth(t *testing.T) {
+	doc := []byte(`{"a":"x","b":{"c":"x","d":{"e":"x","f":["x"]}}}`)
+	mask := func(ctx context.Context, info jsonutil.KVInfo) string { return "***" }
+
+	tr := jsonutil.NewTransformer(jsonutil.Config{StringTransformer: mask, MaxDepth: 2})
+	out, err := tr.TransformBytes(context.Background(), doc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":"***","b":{"c":"***","d":{"e":"x","f":["x"]}}}`, string(out))
+
+	tr = jsonutil.NewTransformer(jsonutil.Config{StringTransformer: mask, MaxDepth: 2, MaxDepthError: true})
+	_, err = tr.TransformBytes(context.Background(), doc)
+	assert.ErrorIs(t, err, jsonutil.ErrMaxDepthExceeded)
+	assert.Contains(t, err.Error(), "b.d")
+
+	tr = jsonutil.NewTransformer(jsonutil.Config{StringTransformer: mask, MaxDepth: 4, MaxDepthError: true})
+	out, err = tr.TransformBytes(context.Background(), doc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":"***","b":{"c":"***","d":{"e":"***","f":["***"]}}}`, string(out))
+
+	// TransformStream follows the same limit
+	var buf bytes.Buffer
+	tr = jsonutil.NewTransformer(jsonutil.Config{StringTransformer: mask, MaxDepth: 2})
+	err = tr.TransformStream(context.Background(), bytes.NewReader(doc), &buf)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":"***","b":{"c":"***","d":{"e":"x","f":["x"]}}}`, buf.String())
+
+	buf.Reset()
+	tr = jsonutil.NewTransformer(jsonutil.Config{StringTransformer: mask, MaxDepth: 2, MaxDepthError: true})
+	err = tr.TransformStream(context.Background(), bytes.NewReader(doc), &buf)
+	assert.ErrorIs(t, err, jsonutil.ErrMaxDepthExceeded)
+	assert.Contains(t, err.Error(), "b.d")
+}
+
+func TestTransformer_CyclicData(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{})
+
+	m := map[string]interface{}{"a": "b"}
+	m["self"] = map[string]interface{}{"parent": m}
+	_, err := tr.Transform(context.Background(), m)
+	assert.ErrorIs(t, err, jsonutil.ErrCyclicData)
+
+	s := []interface{}{"a", nil}
+	s[1] = s
+	_, err = tr.Transform(context.Background(), s)
+	assert.ErrorIs(t, err, jsonutil.ErrCyclicData)
+
+	// the same map on two places is not a cycle
+	shared := map[string]interface{}{"a": "b"}
+	out, err := tr.Transform(context.Background(), map[string]interface{}{"x": shared, "y": []interface{}{shared}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"x": shared, "y": []interface{}{shared}}, out)
+}
+
+func TestDecodeDocument_TrailingData(t *testing.T) {
+	for _, doc := range []string{`{"a":1} garbage`, `{"a":1}{"a":2}`, `[1] ]`, `"a" "b"`} {
+		var syntaxErr *jsonutil.SyntaxError
+		_, err := jsonutil.Paths([]byte(doc))
+		assert.True(t, errors.As(err, &syntaxErr), doc)
+
+		_, err = jsonutil.ParseDocument([]byte(doc))
+		assert.Error(t, err, doc)
+
+		_, err = jsonutil.Shape([]byte(doc))
+		assert.Error(t, err, doc)
+	}
+
+	paths, err := jsonutil.Paths([]byte(" \n{\"a\":1} \r\n\t"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, paths)
+}
@@ -0,0 +1,112 @@
+package jsonutil
+
+import (
+	"net"
+	"regexp"
+	"strings"
+)
+
+// Detector find the sensitive data inside any string value regardless of its key, such as email in free-text field.
+type Detector interface {
+	// Name is the kind of data detected, i.e: email.
+	Name() string
+
+	// Mask return value with every detected data replaced, or value as is when nothing is detected.
+	Mask(value string) string
+}
+
+type regexDetector struct {
+	name     string
+	regex    *regexp.Regexp
+	validate func(match string) bool
+}
+
+// RegexDetector return Detector which replace every match of expr with "[name]", i.e: "mail me at [email]".
+// When validate is not nil, only the match for which it returns true is replaced, i.e: Luhn check for credit card.
+func RegexDetector(name, expr string, validate func(match string) bool) (Detector, error) {
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &regexDetector{name: name, regex: regex, validate: validate}, nil
+}
+
+func mustRegexDetector(name, expr string, validate func(match string) bool) Detector {
+	d, err := RegexDetector(name, expr, validate)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func (d *regexDetector) Name() string {
+	return d.name
+}
+
+func (d *regexDetector) Mask(value string) string {
+	return d.regex.ReplaceAllStringFunc(value, func(match string) string {
+		if d.validate != nil && !d.validate(match) {
+			return match
+		}
+
+		return "[" + d.name + "]"
+	})
+}
+
+// The builtin detectors, see DefaultDetectors.
+var (
+	DetectEmail        = mustRegexDetector("email", `[A-Za-z0-9._%+\-]+@[A-Za-z0-9\-]+(?:\.[A-Za-z0-9\-]+)*\.[A-Za-z]{2,}`, nil)
+	DetectIPv4         = mustRegexDetector("ipv4", `\b(?:(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\.){3}(?:25[0-5]|2[0-4]\d|1\d\d|[1-9]?\d)\b`, nil)
+	DetectIPv6         = mustRegexDetector("ipv6", `[0-9A-Za-z.]*:[0-9A-Za-z.:]*[0-9A-Za-z:]`, isIPv6)
+	DetectCreditCard   = mustRegexDetector("credit_card", `\b\d(?:[ \-]?\d){12,18}\b`, isLuhn)
+	DetectJWT          = mustRegexDetector("jwt", `\beyJ[A-Za-z0-9_\-]+\.eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*`, nil)
+	DetectAWSAccessKey = mustRegexDetector("aws_access_key", `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`, nil)
+)
+
+// DefaultDetectors return all builtin detectors, JWT first since its payload may contain the other kinds once decoded.
+func DefaultDetectors() []Detector {
+	return []Detector{DetectJWT, DetectAWSAccessKey, DetectEmail, DetectCreditCard, DetectIPv4, DetectIPv6}
+}
+
+// isIPv6 return true if match is IPv6 address with at least 2 groups,
+// the DetectIPv6 regex match the whole word so "std::vector" or "Foo::Bar" is never cut into a valid one.
+func isIPv6(match string) bool {
+	if net.ParseIP(match) == nil || !strings.Contains(match, ":") {
+		return false
+	}
+
+	groups := 0
+	for _, group := range strings.Split(match, ":") {
+		if group != "" {
+			groups++
+		}
+	}
+
+	return groups >= 2
+}
+
+// isLuhn return true if the digits in match pass the Luhn checksum.
+func isLuhn(match string) bool {
+	sum, n := 0, 0
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+
+		sum += d
+		n++
+	}
+
+	return n >= 13 && sum%10 == 0
+}
@@ -0,0 +1,134 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Document is a parsed JSON document which cannot be modified after created.
+// The decoded data is never exposed directly: Get return a copy of the value and Mutate return a copy of the whole data,
+// so one Document can be shared between goroutines without aliasing the same map or slice.
+type Document struct {
+	data interface{}
+}
+
+var _ json.Marshaler = (*Document)(nil)
+
+// ParseDocument return Document of JSON doc.
+func ParseDocument(doc []byte) (*Document, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, err
+	}
+
+	return &Document{data: data}, nil
+}
+
+// NewDocument return Document of v, i.e: the result of Transformer.Transform.
+// v is encoded and decoded again, so later modification of v does not change the Document.
+func NewDocument(v interface{}) (*Document, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseDocument(b)
+}
+
+// Get return a copy of the value on path, or ErrPathNotFound. See GetBytes for the path format.
+func (d *Document) Get(path string) (Value, error) {
+	v, ok := d.lookup(SplitPath(path))
+	if !ok {
+		return Value{}, ErrPathNotFound
+	}
+
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return Value{}, err
+	}
+
+	var val Value
+	err = val.UnmarshalJSON(raw)
+	return val, err
+}
+
+// Paths return every leaf path in dotted form, see Paths.
+func (d *Document) Paths() []string {
+	paths := make([]string, 0)
+	walkLeaves(d.data, []string{}, func(segments []string, v interface{}) {
+		paths = append(paths, JoinPath(segments))
+	})
+
+	return paths
+}
+
+// Len return the number of members when the root is an object, the number of elements when it is an array,
+// and zero for any other value.
+func (d *Document) Len() int {
+	switch v := d.data.(type) {
+	case map[string]interface{}:
+		return len(v)
+	case []interface{}:
+		return len(v)
+	}
+
+	return 0
+}
+
+// Mutate return a deep copy of the data which the caller is free to modify,
+// numbers is json.Number. Use NewDocument to make a Document of the modified data.
+func (d *Document) Mutate() interface{} {
+	return deepCopy(d.data)
+}
+
+// MarshalJSON return the JSON encoding of the document.
+func (d *Document) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.data)
+}
+
+func (d *Document) lookup(segments []string) (interface{}, bool) {
+	cur := d.data
+	for _, seg := range segments {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			child, ok := v[seg]
+			if !ok {
+				return nil, false
+			}
+			cur = child
+
+		case []interface{}:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(v) {
+				return nil, false
+			}
+			cur = v[idx]
+
+		default:
+			return nil, false
+		}
+	}
+
+	return cur, true
+}
+
+// deepCopy return a copy of decoded JSON data which shares no map or slice with data.
+func deepCopy(data interface{}) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = deepCopy(val)
+		}
+		return m
+
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = deepCopy(val)
+		}
+		return s
+	}
+
+	return data
+}
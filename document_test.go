@@ -0,0 +1,100 @@
+package jsonutil_test
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestDocument(t *testing.T) {
+	doc, err := jsonutil.ParseDocument([]byte(`{"user":{"name":"john","tags":["a","b"]},"id":12345678901234567890}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, doc.Len())
+	assert.Equal(t, []string{"id", "user.name", "user.tags.0", "user.tags.1"}, doc.Paths())
+
+	v, err := doc.Get("user.name")
+	assert.NoError(t, err)
+	assert.Equal(t, "john", v.String())
+
+	v, err = doc.Get("/user/tags/1")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", v.String())
+
+	_, err = doc.Get("user.tags.2")
+	assert.Equal(t, jsonutil.ErrPathNotFound, err)
+
+	_, err = doc.Get("user.name.first")
+	assert.Equal(t, jsonutil.ErrPathNotFound, err)
+
+	// modifying the returned value does not change the document
+	v, err = doc.Get("user")
+	assert.NoError(t, err)
+	v.Interface().(map[string]interface{})["name"] = "changed"
+
+	data := doc.Mutate().(map[string]interface{})
+	data["user"].(map[string]interface{})["tags"].([]interface{})[0] = "changed"
+	delete(data, "id")
+
+	out, err := json.Marshal(doc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"user":{"name":"john","tags":["a","b"]},"id":12345678901234567890}`, string(out))
+	assert.Contains(t, string(out), "12345678901234567890")
+
+	mutated, err := jsonutil.NewDocument(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user.name", "user.tags.0", "user.tags.1"}, mutated.Paths())
+
+	v, err = mutated.Get("user.tags.0")
+	assert.NoError(t, err)
+	assert.Equal(t, "changed", v.String())
+}
+
+func TestNewDocument(t *testing.T) {
+	data := map[string]interface{}{"a": []interface{}{1, 2}}
+	doc, err := jsonutil.NewDocument(data)
+	assert.NoError(t, err)
+
+	data["a"].([]interface{})[0] = 100
+	data["b"] = true
+
+	out, err := doc.MarshalJSON()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":[1,2]}`, string(out))
+
+	scalar, err := jsonutil.ParseDocument([]byte(`"str"`))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, scalar.Len())
+	assert.Equal(t, []string{""}, scalar.Paths())
+
+	_, err = jsonutil.ParseDocument([]byte(`{`))
+	assert.Error(t, err)
+
+	_, err = jsonutil.NewDocument(make(chan int))
+	assert.Error(t, err)
+}
+
+func TestDocument_Concurrent(t *testing.T) {
+	doc, err := jsonutil.ParseDocument([]byte(`{"a":{"b":[1,2,3]}}`))
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data := doc.Mutate().(map[string]interface{})
+			data["a"].(map[string]interface{})["b"] = nil
+			_, _ = doc.Get("a.b.0")
+			_ = doc.Paths()
+		}()
+	}
+	wg.Wait()
+
+	v, err := doc.Get("a.b.2")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", v.String())
+}
@@ -0,0 +1,190 @@
+package jsonutil
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// EncryptedPrefix is the prefix of encrypted value, the full format is enc:v1:<nonce>:<ciphertext>
+// where nonce and ciphertext is encoded using unpadded base64 URL encoding.
+const EncryptedPrefix = "enc:v1:"
+
+// KeyProvider provide the AES key (16, 24 or 32 bytes for AES-128, AES-192 or AES-256) to encrypt the value.
+// Implement it to fetch the key from KMS or secret manager.
+type KeyProvider interface {
+	Key(ctx context.Context) ([]byte, error)
+}
+
+// KeySet provide every version of the key which may have been used to encrypt the value,
+// i.e: the current and previous keys during key rotation. Newer key should come first.
+type KeySet interface {
+	Keys(ctx context.Context) ([][]byte, error)
+}
+
+// StaticKey is KeyProvider and KeySet which always return the same key.
+type StaticKey []byte
+
+func (k StaticKey) Key(ctx context.Context) ([]byte, error) {
+	return k, nil
+}
+
+func (k StaticKey) Keys(ctx context.Context) ([][]byte, error) {
+	return [][]byte{k}, nil
+}
+
+// KeyRing is KeyProvider and KeySet of key versions, ordered from the oldest to the newest.
+// The newest key is used to encrypt, and every version can decrypt,
+// so a new key can be appended without breaking the already encrypted data.
+type KeyRing [][]byte
+
+func (k KeyRing) Key(ctx context.Context) ([]byte, error) {
+	if len(k) == 0 {
+		return nil, fmt.Errorf("jsonutil: key ring is empty")
+	}
+
+	return k[len(k)-1], nil
+}
+
+func (k KeyRing) Keys(ctx context.Context) ([][]byte, error) {
+	keys := make([][]byte, len(k))
+	for i, key := range k {
+		keys[len(k)-1-i] = key
+	}
+
+	return keys, nil
+}
+
+type EncryptorConfig struct {
+	// Keys is the object keys (in any level) which value is encrypted.
+	Keys []string
+
+	// Paths is the dotted paths which value is encrypted, "*" match any single segment, i.e: users.*.email
+	Paths []string
+
+	KeyProvider KeyProvider
+}
+
+// Encryptor encrypt the value of configured keys and paths using AES-GCM,
+// so sensitive field is protected at rest while the rest of the document is still queryable.
+// Any value type can be encrypted, the plaintext is the JSON encoding of the value so the type is restored on decryption.
+// The JSON Pointer of the field is authenticated as additional data,
+// so the encrypted value copied into another field cannot be decrypted.
+type Encryptor struct {
+	keys        map[string]struct{}
+	paths       [][]string
+	keyProvider KeyProvider
+	rand        io.Reader
+}
+
+func NewEncryptor(conf EncryptorConfig) (*Encryptor, error) {
+	if conf.KeyProvider == nil {
+		return nil, fmt.Errorf("jsonutil: encryptor key provider is required")
+	}
+
+	e := &Encryptor{
+		keys:        keySet(conf.Keys),
+		keyProvider: conf.KeyProvider,
+		rand:        rand.Reader,
+	}
+
+	for _, path := range conf.Paths {
+		e.paths = append(e.paths, SplitPath(path))
+	}
+
+	return e, nil
+}
+
+// Process encrypt the configured fields in doc.
+func (e *Encryptor) Process(ctx context.Context, doc []byte) ([]byte, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, err
+	}
+
+	key, err := e.keyProvider.Key(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := e.walk(aead, data, []string{})
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(out)
+}
+
+func (e *Encryptor) walk(aead cipher.AEAD, data interface{}, segments []string) (interface{}, error) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			var err error
+			v[key], err = e.walkMember(aead, key, true, val, append(segments, key))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+	case []interface{}:
+		for i, val := range v {
+			var err error
+			v[i], err = e.walkMember(aead, "", false, val, append(segments, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return data, nil
+}
+
+func (e *Encryptor) walkMember(aead cipher.AEAD, key string, isKey bool, val interface{}, segments []string) (interface{}, error) {
+	if _, ok := e.keys[key]; ok && isKey {
+		return e.encrypt(aead, val, segments)
+	}
+
+	for _, path := range e.paths {
+		if matchSegments(path, segments) {
+			return e.encrypt(aead, val, segments)
+		}
+	}
+
+	return e.walk(aead, val, segments)
+}
+
+// encrypt return the encrypted val on segments path.
+func (e *Encryptor) encrypt(aead cipher.AEAD, val interface{}, segments []string) (interface{}, error) {
+	plaintext, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(e.rand, nonce); err != nil {
+		return nil, err
+	}
+
+	ciphertext := aead.Seal(nil, nonce, plaintext, []byte(JoinPointer(segments)))
+	return EncryptedPrefix + base64.RawURLEncoding.EncodeToString(nonce) + ":" + base64.RawURLEncoding.EncodeToString(ciphertext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("jsonutil: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
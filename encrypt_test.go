@@ -0,0 +1,74 @@
+package jsonutil_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+var testKey = jsonutil.StaticKey("0123456789abcdef0123456789abcdef")
+
+func TestEncryptor(t *testing.T) {
+	enc, err := jsonutil.NewEncryptor(jsonutil.EncryptorConfig{
+		Keys:        []string{"ssn"},
+		Paths:       []string{"users.*.email"},
+		KeyProvider: testKey,
+	})
+	assert.NoError(t, err)
+
+	in := []byte(`{"id":1,"ssn":"123-45-6789","users":[{"email":"a@b.c","name":"alice"}],"card":{"ssn":{"n":1}}}`)
+	out, err := enc.Process(context.Background(), in)
+	assert.NoError(t, err)
+
+	for _, path := range []string{"ssn", "users.0.email", "card.ssn"} {
+		v, err := jsonutil.GetBytes(out, path)
+		assert.NoError(t, err)
+
+		parts := strings.Split(v.String(), ":")
+		assert.Len(t, parts, 4, path)
+		assert.True(t, strings.HasPrefix(v.String(), jsonutil.EncryptedPrefix), path)
+
+		nonce, err := base64.RawURLEncoding.DecodeString(parts[2])
+		assert.NoError(t, err)
+		assert.Len(t, nonce, 12)
+	}
+
+	for path, expected := range map[string]string{"id": "1", "users.0.name": "alice"} {
+		v, err := jsonutil.GetBytes(out, path)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, v.String())
+	}
+
+	// random nonce: encrypting the same value twice produces different output
+	out2, err := enc.Process(context.Background(), in)
+	assert.NoError(t, err)
+	assert.NotEqual(t, string(out), string(out2))
+}
+
+type failingKeyProvider struct{}
+
+func (failingKeyProvider) Key(ctx context.Context) ([]byte, error) {
+	return nil, errors.New("kms unavailable")
+}
+
+func TestEncryptor_Error(t *testing.T) {
+	_, err := jsonutil.NewEncryptor(jsonutil.EncryptorConfig{Keys: []string{"ssn"}})
+	assert.Error(t, err)
+
+	enc, err := jsonutil.NewEncryptor(jsonutil.EncryptorConfig{Keys: []string{"ssn"}, KeyProvider: failingKeyProvider{}})
+	assert.NoError(t, err)
+
+	_, err = enc.Process(context.Background(), []byte(`{"ssn":"1"}`))
+	assert.EqualError(t, err, "kms unavailable")
+
+	enc, err = jsonutil.NewEncryptor(jsonutil.EncryptorConfig{Keys: []string{"ssn"}, KeyProvider: jsonutil.StaticKey("short")})
+	assert.NoError(t, err)
+
+	_, err = enc.Process(context.Background(), []byte(`{"ssn":"1"}`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,208 @@
+package envmap
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ArrayStrategy is how an overlay array is merged into the base array.
+type ArrayStrategy int
+
+const (
+	// ArrayReplace replace the base array with the overlay array.
+	ArrayReplace ArrayStrategy = iota
+	// ArrayAppend append the overlay elements after the base elements.
+	ArrayAppend
+	// ArrayMergeByIndex deep merge the elements on the same index, extra overlay elements are appended.
+	ArrayMergeByIndex
+	// ArrayMergeByKey deep merge the object elements with the same MergeConfig.ArrayKey value (i.e: "name"),
+	// unmatched overlay elements are appended. Array with element without the key is replaced.
+	ArrayMergeByKey
+)
+
+// MergeConfig configure Merger.
+type MergeConfig struct {
+	// Arrays is the default strategy for every array.
+	Arrays ArrayStrategy
+
+	// ArrayKey is the object key used by ArrayMergeByKey.
+	ArrayKey string
+
+	// PathArrays override Arrays for the array on path, in dotted form, i.e: "spec.containers".
+	PathArrays map[string]ArrayStrategy
+}
+
+// Merger layer the environment specific config overlays on top of the base config.
+type Merger struct {
+	conf MergeConfig
+}
+
+// NewMerger return Merger using conf.
+func NewMerger(conf MergeConfig) *Merger {
+	return &Merger{conf: conf}
+}
+
+// MergeOverlays deep merge the JSON overlays in order on top of base using ArrayReplace strategy,
+// see Merger.Merge. It is meant to be called before ReplaceEnvVariables, so the overlay can use variables too.
+func MergeOverlays(base []byte, overlays ...[]byte) ([]byte, error) {
+	return NewMerger(MergeConfig{}).Merge(base, overlays...)
+}
+
+// Merge deep merge the JSON overlays in order on top of base:
+// object is merged key by key, null in the overlay delete the key from the result,
+// array is merged according to the array strategy, and any other value in the overlay replace the base value.
+// The result is encoded with sorted keys.
+func (m *Merger) Merge(base []byte, overlays ...[]byte) ([]byte, error) {
+	result, err := decodeJSON(base)
+	if err != nil {
+		return nil, fmt.Errorf("envmap: base: %w", err)
+	}
+
+	for i, overlay := range overlays {
+		v, err := decodeJSON(overlay)
+		if err != nil {
+			return nil, fmt.Errorf("envmap: overlay %d: %w", i, err)
+		}
+
+		result = m.merge(result, v, "")
+	}
+
+	return json.Marshal(result)
+}
+
+func (m *Merger) merge(base, overlay interface{}, path string) interface{} {
+	switch over := overlay.(type) {
+	case map[string]interface{}:
+		baseObj, ok := base.(map[string]interface{})
+		if !ok {
+			return dropNulls(over)
+		}
+
+		for key, val := range over {
+			if val == nil {
+				delete(baseObj, key)
+				continue
+			}
+
+			baseObj[key] = m.merge(baseObj[key], val, joinPath(path, key))
+		}
+
+		return baseObj
+
+	case []interface{}:
+		baseArr, ok := base.([]interface{})
+		if !ok {
+			return over
+		}
+
+		return m.mergeArray(baseArr, over, path)
+	}
+
+	return overlay
+}
+
+func (m *Merger) mergeArray(base, overlay []interface{}, path string) []interface{} {
+	strategy, ok := m.conf.PathArrays[path]
+	if !ok {
+		strategy = m.conf.Arrays
+	}
+
+	switch strategy {
+	case ArrayAppend:
+		return append(base, overlay...)
+
+	case ArrayMergeByIndex:
+		for i, val := range overlay {
+			if i < len(base) {
+				base[i] = m.merge(base[i], val, joinPath(path, fmt.Sprint(i)))
+			} else {
+				base = append(base, val)
+			}
+		}
+		return base
+
+	case ArrayMergeByKey:
+		index := map[string]int{}
+		for i, val := range base {
+			key, ok := elementKey(val, m.conf.ArrayKey)
+			if !ok {
+				return overlay
+			}
+			index[key] = i
+		}
+
+		for _, val := range overlay {
+			key, ok := elementKey(val, m.conf.ArrayKey)
+			if !ok {
+				return overlay
+			}
+
+			if i, found := index[key]; found {
+				base[i] = m.merge(base[i], val, joinPath(path, key))
+			} else {
+				index[key] = len(base)
+				base = append(base, val)
+			}
+		}
+		return base
+	}
+
+	return overlay
+}
+
+// elementKey return the string form of the key value inside object element.
+func elementKey(v interface{}, key string) (string, bool) {
+	obj, ok := v.(map[string]interface{})
+	if !ok || key == "" {
+		return "", false
+	}
+
+	val, ok := obj[key]
+	if !ok || val == nil {
+		return "", false
+	}
+
+	return fmt.Sprint(val), true
+}
+
+// dropNulls remove the null delete markers from overlay object which has nothing to merge with.
+func dropNulls(obj map[string]interface{}) map[string]interface{} {
+	for key, val := range obj {
+		if val == nil {
+			delete(obj, key)
+			continue
+		}
+
+		if child, ok := val.(map[string]interface{}); ok {
+			obj[key] = dropNulls(child)
+		}
+	}
+
+	return obj
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func decodeJSON(b []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid data after top-level value")
+	}
+
+	return v, nil
+}
@@ -0,0 +1,95 @@
+package envmap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeOverlays(t *testing.T) {
+	base := `{
+		"name": "app",
+		"log": {"level": "info", "format": "json"},
+		"db": {"host": "localhost", "port": 5432, "debug": true},
+		"features": ["a", "b"]
+	}`
+
+	production := `{
+		"log": {"level": "warn"},
+		"db": {"host": "${DB_HOST}", "debug": null},
+		"features": ["c"]
+	}`
+
+	region := `{"db": {"port": 6432}, "region": {"name": "eu", "legacy": null}}`
+
+	out, err := MergeOverlays([]byte(base), []byte(production), []byte(region))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "app",
+		"log": {"level": "warn", "format": "json"},
+		"db": {"host": "${DB_HOST}", "port": 6432},
+		"features": ["c"],
+		"region": {"name": "eu"}
+	}`, string(out))
+
+	// overlay is applied before variable replacement
+	replaced := ReplaceEnvVariables(out, map[string]string{"DB_HOST": "db.internal"})
+	assert.Contains(t, string(replaced), `"host":"db.internal"`)
+
+	out, err = MergeOverlays([]byte(base))
+	assert.NoError(t, err)
+	assert.JSONEq(t, base, string(out))
+}
+
+func TestMerger_ArrayStrategy(t *testing.T) {
+	base := `{"tags": ["a"], "containers": [{"name": "app", "image": "app:1", "env": ["A=1"]}, {"name": "sidecar", "image": "proxy:1"}]}`
+	overlay := `{"tags": ["b"], "containers": [{"name": "app", "image": "app:2"}, {"name": "metrics", "image": "exporter:1"}]}`
+
+	testCases := []struct {
+		Name   string
+		Conf   MergeConfig
+		Expect string
+	}{
+		{
+			Name:   "replace",
+			Conf:   MergeConfig{},
+			Expect: `{"tags": ["b"], "containers": [{"name": "app", "image": "app:2"}, {"name": "metrics", "image": "exporter:1"}]}`,
+		},
+		{
+			Name:   "append",
+			Conf:   MergeConfig{Arrays: ArrayAppend},
+			Expect: `{"tags": ["a", "b"], "containers": [{"name": "app", "image": "app:1", "env": ["A=1"]}, {"name": "sidecar", "image": "proxy:1"}, {"name": "app", "image": "app:2"}, {"name": "metrics", "image": "exporter:1"}]}`,
+		},
+		{
+			Name:   "merge by index",
+			Conf:   MergeConfig{Arrays: ArrayMergeByIndex},
+			Expect: `{"tags": ["b"], "containers": [{"name": "app", "image": "app:2", "env": ["A=1"]}, {"name": "metrics", "image": "exporter:1"}]}`,
+		},
+		{
+			Name:   "merge by key with path override",
+			Conf:   MergeConfig{Arrays: ArrayAppend, ArrayKey: "name", PathArrays: map[string]ArrayStrategy{"containers": ArrayMergeByKey}},
+			Expect: `{"tags": ["a", "b"], "containers": [{"name": "app", "image": "app:2", "env": ["A=1"]}, {"name": "sidecar", "image": "proxy:1"}, {"name": "metrics", "image": "exporter:1"}]}`,
+		},
+		{
+			Name:   "merge by key without key replace the array",
+			Conf:   MergeConfig{Arrays: ArrayMergeByKey, ArrayKey: "name"},
+			Expect: `{"tags": ["b"], "containers": [{"name": "app", "image": "app:2", "env": ["A=1"]}, {"name": "sidecar", "image": "proxy:1"}, {"name": "metrics", "image": "exporter:1"}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			out, err := NewMerger(tc.Conf).Merge([]byte(base), []byte(overlay))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.Expect, string(out))
+		})
+	}
+}
+
+func TestMergeOverlays_Error(t *testing.T) {
+	_, err := MergeOverlays([]byte(`{`))
+	assert.EqualError(t, err, "envmap: base: unexpected EOF")
+
+	_, err = MergeOverlays([]byte(`{}`), []byte(`{}`), []byte(`{} x`))
+	assert.EqualError(t, err, "envmap: overlay 1: invalid data after top-level value")
+}
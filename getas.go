@@ -0,0 +1,26 @@
+//go:build go1.18
+// +build go1.18
+
+package jsonutil
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// GetAs return the value on path decoded into T, i.e: GetAs[[]string](doc, "tags").
+// Like GetBytes, it stops scanning as soon as the value is found.
+// It returns ErrPathNotFound when the path does not exist, and error when the value cannot be decoded into T.
+func GetAs[T any](doc []byte, path string) (T, error) {
+	var v T
+	raw, err := GetRawBytes(doc, path)
+	if err != nil {
+		return v, err
+	}
+
+	if err = json.Unmarshal(raw, &v); err != nil {
+		return v, fmt.Errorf("jsonutil: cannot decode %q as %T: %w", path, v, err)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,47 @@
+//go:build go1.18
+// +build go1.18
+
+package jsonutil_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestGetAs(t *testing.T) {
+	doc := []byte(`{"tags": ["a", "b"], "user": {"name": "alice", "age": 30, "admin": true}, "items": [{"price": 1.5}]}`)
+
+	tags, err := jsonutil.GetAs[[]string](doc, "tags")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, tags)
+
+	age, err := jsonutil.GetAs[int](doc, "user.age")
+	assert.NoError(t, err)
+	assert.Equal(t, 30, age)
+
+	price, err := jsonutil.GetAs[float64](doc, "/items/0/price")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, price)
+
+	type user struct {
+		Name  string `json:"name"`
+		Admin bool   `json:"admin"`
+	}
+
+	u, err := jsonutil.GetAs[user](doc, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, user{Name: "alice", Admin: true}, u)
+}
+
+func TestGetAs_Error(t *testing.T) {
+	doc := []byte(`{"tags": ["a", "b"], "age": "30"}`)
+
+	_, err := jsonutil.GetAs[string](doc, "missing")
+	assert.ErrorIs(t, err, jsonutil.ErrPathNotFound)
+
+	age, err := jsonutil.GetAs[int](doc, "age")
+	assert.EqualError(t, err, `jsonutil: cannot decode "age" as int: json: cannot unmarshal string into Go value of type int`)
+	assert.Equal(t, 0, age)
+}
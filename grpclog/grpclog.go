@@ -0,0 +1,140 @@
+package grpclog
+
+import (
+	"context"
+	"time"
+
+	"github.com/yusufsyaifudin/jsonutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Entry is one gRPC log entry.
+// Request and Response is the sanitized JSON of the message, nil when the message is not a proto.Message
+// or cannot be sanitized, the original message is never passed to the log.
+//
+// For unary call, one Entry is logged with both Request and Response.
+// For streaming call, one Entry is logged for each message received (Request) or sent (Response),
+// and one final Entry with Done set to true when the handler returned.
+type Entry struct {
+	FullMethod string
+	Request    []byte
+	Response   []byte
+	Done       bool
+	Code       string // Code is the gRPC status code name, only set when the call is done.
+	Latency    time.Duration
+}
+
+// LogFunc receive the log entry.
+type LogFunc func(ctx context.Context, entry Entry)
+
+type Config struct {
+	// Processors is applied in order to the message JSON, i.e: masking then truncation.
+	Processors []jsonutil.Processor
+
+	// Log is called for every entry, it must not be nil.
+	Log LogFunc
+}
+
+type logger struct {
+	conf     Config
+	pipeline *jsonutil.Pipeline
+}
+
+func newLogger(conf Config) *logger {
+	if conf.Log == nil {
+		conf.Log = func(ctx context.Context, entry Entry) {}
+	}
+
+	return &logger{conf: conf, pipeline: jsonutil.NewPipeline(conf.Processors...)}
+}
+
+// sanitize marshal msg using protojson and run it through the transformers.
+func (l *logger) sanitize(ctx context.Context, msg interface{}) []byte {
+	pb, ok := msg.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	out, err := protojson.Marshal(pb)
+	if err != nil {
+		return nil
+	}
+
+	out, err = l.pipeline.Process(ctx, out)
+	if err != nil {
+		return nil
+	}
+
+	return out
+}
+
+// UnaryServerInterceptor log the sanitized request and response message of unary call.
+func UnaryServerInterceptor(conf Config) grpc.UnaryServerInterceptor {
+	l := newLogger(conf)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		entry := Entry{
+			FullMethod: info.FullMethod,
+			Request:    l.sanitize(ctx, req),
+			Done:       true,
+			Code:       status.Code(err).String(),
+			Latency:    time.Since(start),
+		}
+
+		if err == nil {
+			entry.Response = l.sanitize(ctx, resp)
+		}
+
+		l.conf.Log(ctx, entry)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor log the sanitized message of streaming call.
+func StreamServerInterceptor(conf Config) grpc.StreamServerInterceptor {
+	l := newLogger(conf)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, &serverStream{ServerStream: ss, logger: l, fullMethod: info.FullMethod})
+
+		l.conf.Log(ss.Context(), Entry{
+			FullMethod: info.FullMethod,
+			Done:       true,
+			Code:       status.Code(err).String(),
+			Latency:    time.Since(start),
+		})
+
+		return err
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	logger     *logger
+	fullMethod string
+}
+
+func (s *serverStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		ctx := s.Context()
+		s.logger.conf.Log(ctx, Entry{FullMethod: s.fullMethod, Request: s.logger.sanitize(ctx, m)})
+	}
+
+	return err
+}
+
+func (s *serverStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		ctx := s.Context()
+		s.logger.conf.Log(ctx, Entry{FullMethod: s.fullMethod, Response: s.logger.sanitize(ctx, m)})
+	}
+
+	return err
+}
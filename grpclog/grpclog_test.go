@@ -0,0 +1,59 @@
+package grpclog_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"github.com/yusufsyaifudin/jsonutil/grpclog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	mask := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "password" || info.Key == "token" {
+				return "xxx"
+			}
+
+			return info.Value
+		},
+	})
+
+	var entries []grpclog.Entry
+	interceptor := grpclog.UnaryServerInterceptor(grpclog.Config{
+		Processors: []jsonutil.Processor{mask},
+		Log: func(ctx context.Context, entry grpclog.Entry) {
+			entries = append(entries, entry)
+		},
+	})
+
+	req, err := structpb.NewStruct(map[string]interface{}{"user": "a", "password": "secret"})
+	assert.NoError(t, err)
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/auth.v1.Auth/Login"}
+	resp, err := interceptor(context.Background(), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return structpb.NewStruct(map[string]interface{}{"token": "abc"})
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "/auth.v1.Auth/Login", entries[0].FullMethod)
+	assert.JSONEq(t, `{"password":"xxx","user":"a"}`, string(entries[0].Request))
+	assert.JSONEq(t, `{"token":"xxx"}`, string(entries[0].Response))
+	assert.Equal(t, codes.OK.String(), entries[0].Code)
+
+	entries = nil
+	_, err = interceptor(context.Background(), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.Unauthenticated, "bad password")
+	})
+	assert.Error(t, err)
+	assert.Len(t, entries, 1)
+	assert.Nil(t, entries[0].Response)
+	assert.Equal(t, codes.Unauthenticated.String(), entries[0].Code)
+}
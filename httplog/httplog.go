@@ -0,0 +1,194 @@
+package httplog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+// DefaultMaxBodySize is the maximum body size captured for logging when Config.MaxBodySize is not set.
+const DefaultMaxBodySize = 64 * 1024
+
+// Entry is one access log entry.
+// Body is the sanitized copy, it is nil when the body is empty, not JSON or form, invalid or larger than Config.MaxBodySize.
+// Form body (application/x-www-form-urlencoded) is sanitized using jsonutil.ProcessForm, so it is logged form-encoded.
+// Because the original body may contain sensitive information, it never passed into the Entry.
+type Entry struct {
+	Method       string
+	URL          string
+	StatusCode   int
+	RequestBody  []byte
+	ResponseBody []byte
+	RequestSize  int64 // RequestSize is the read request body size, at most MaxBodySize+1 bytes more than read by the handler.
+	ResponseSize int64 // ResponseSize is the original response body size written by the handler.
+	Latency      time.Duration
+}
+
+// LogFunc receive the access log entry after the handler returned.
+type LogFunc func(ctx context.Context, entry Entry)
+
+type Config struct {
+	// Processor is used to sanitize (truncate and/or mask) the JSON body before passed to Log.
+	// When nil, jsonutil.NewTransformer with default config is used, means the body is only re-encoded.
+	Processor jsonutil.Processor
+
+	// Log is called for every request. Default to no-op, which discards the entry.
+	Log LogFunc
+
+	// MaxBodySize is the maximum body size in bytes to be captured. Default to DefaultMaxBodySize.
+	MaxBodySize int
+}
+
+type middleware struct {
+	conf Config
+	next http.Handler
+}
+
+// Middleware tee the JSON (or form) request and response body, sanitize it using Config.Processor,
+// and pass the sanitized copy to Config.Log along with size and latency information.
+// The handler still receives the original request body, and the client still receives the original response.
+func Middleware(conf Config, next http.Handler) http.Handler {
+	if conf.Processor == nil {
+		conf.Processor = jsonutil.NewTransformer(jsonutil.Config{})
+	}
+
+	if conf.Log == nil {
+		conf.Log = func(ctx context.Context, entry Entry) {}
+	}
+
+	if conf.MaxBodySize <= 0 {
+		conf.MaxBodySize = DefaultMaxBodySize
+	}
+
+	return &middleware{conf: conf, next: next}
+}
+
+func (m *middleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	ctx := r.Context()
+
+	reqBody := &captureReader{body: capture{limit: m.conf.MaxBodySize}}
+	if r.Body != nil && r.Body != http.NoBody {
+		reqBody.ReadCloser = r.Body
+		r.Body = reqBody
+	}
+
+	rw := &responseWriter{
+		ResponseWriter: w,
+		statusCode:     http.StatusOK,
+		body:           capture{limit: m.conf.MaxBodySize},
+	}
+
+	m.next.ServeHTTP(rw, r)
+
+	// drain the unread request body, so the logged body is complete even when handler not read it all.
+	// It stops after MaxBodySize, so a client streaming forever can't keep the connection busy.
+	if reqBody.ReadCloser != nil {
+		_, _ = io.Copy(ioutil.Discard, io.LimitReader(reqBody, int64(m.conf.MaxBodySize)+1))
+	}
+
+	entry := Entry{
+		Method:       r.Method,
+		URL:          r.URL.String(),
+		StatusCode:   rw.statusCode,
+		RequestSize:  reqBody.body.size,
+		ResponseSize: rw.body.size,
+		Latency:      time.Since(start),
+	}
+
+	entry.RequestBody = m.sanitize(ctx, r.Header.Get("Content-Type"), &reqBody.body)
+	entry.ResponseBody = m.sanitize(ctx, rw.Header().Get("Content-Type"), &rw.body)
+
+	m.conf.Log(ctx, entry)
+}
+
+func (m *middleware) sanitize(ctx context.Context, contentType string, c *capture) []byte {
+	if c.size == 0 || c.overflow {
+		return nil
+	}
+
+	var out []byte
+	var err error
+	switch {
+	case jsonutil.IsJSONMediaType(contentType):
+		out, err = m.conf.Processor.Process(ctx, c.buf.Bytes())
+	case jsonutil.IsFormMediaType(contentType):
+		out, err = jsonutil.ProcessForm(ctx, m.conf.Processor, c.buf.Bytes())
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return nil
+	}
+
+	return out
+}
+
+// capture copy written bytes until the limit.
+type capture struct {
+	buf      bytes.Buffer
+	limit    int
+	size     int64
+	overflow bool
+}
+
+func (c *capture) Write(p []byte) {
+	c.size += int64(len(p))
+	if c.overflow {
+		return
+	}
+
+	if c.buf.Len()+len(p) > c.limit {
+		c.overflow = true
+		c.buf.Reset()
+		return
+	}
+
+	c.buf.Write(p)
+}
+
+type captureReader struct {
+	io.ReadCloser
+	body capture
+}
+
+func (c *captureReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.body.Write(p[:n])
+	return n, err
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        capture
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.statusCode = statusCode
+		w.wroteHeader = true
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.body.Write(p[:n])
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
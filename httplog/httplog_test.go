@@ -0,0 +1,148 @@
+package httplog_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"github.com/yusufsyaifudin/jsonutil/httplog"
+)
+
+func TestMiddleware(t *testing.T) {
+	transformer := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "password" || info.Key == "token" {
+				return "xxx"
+			}
+
+			return info.Value
+		},
+	})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"user":"a","password":"secret"}`, string(body))
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"token":"abc"}`))
+	})
+
+	var entry httplog.Entry
+	srv := httplog.Middleware(httplog.Config{
+		Processor: transformer,
+		Log: func(ctx context.Context, e httplog.Entry) {
+			entry = e
+		},
+	}, handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/login?a=b", strings.NewReader(`{"user":"a","password":"secret"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	// client still receive the original response
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `{"token":"abc"}`, rec.Body.String())
+
+	assert.Equal(t, http.MethodPost, entry.Method)
+	assert.Equal(t, "/login?a=b", entry.URL)
+	assert.Equal(t, http.StatusCreated, entry.StatusCode)
+	assert.Equal(t, `{"password":"xxx","user":"a"}`, string(entry.RequestBody))
+	assert.Equal(t, `{"token":"xxx"}`, string(entry.ResponseBody))
+	assert.EqualValues(t, 32, entry.RequestSize)
+	assert.EqualValues(t, 15, entry.ResponseSize)
+}
+
+func TestMiddleware_SkipBody(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// handler not read the body
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(`{"token":"abc"}`))
+	})
+
+	var entry httplog.Entry
+	srv := httplog.Middleware(httplog.Config{
+		MaxBodySize: 4,
+		Log: func(ctx context.Context, e httplog.Entry) {
+			entry = e
+		},
+	}, handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"a":"too large"}`))
+	req.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, http.StatusOK, entry.StatusCode)
+	assert.Nil(t, entry.RequestBody)
+	assert.Nil(t, entry.ResponseBody)
+	// the unread body is drained up to MaxBodySize+1 bytes
+	assert.EqualValues(t, 5, entry.RequestSize)
+	assert.EqualValues(t, 15, entry.ResponseSize)
+}
+
+func TestMiddleware_Form(t *testing.T) {
+	transformer := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "password" {
+				return "xxx"
+			}
+
+			return info.Value
+		},
+	})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "secret", r.PostForm.Get("password"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	var entry httplog.Entry
+	srv := httplog.Middleware(httplog.Config{
+		Processor: transformer,
+		Log: func(ctx context.Context, e httplog.Entry) {
+			entry = e
+		},
+	}, handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`user=alice&password=secret`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, `password=xxx&user=alice`, string(entry.RequestBody))
+	assert.Nil(t, entry.ResponseBody)
+}
+
+// endlessReader is a request body which never ends.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	return len(p), nil
+}
+
+func TestMiddleware_EndlessBody(t *testing.T) {
+	var entry httplog.Entry
+	srv := httplog.Middleware(httplog.Config{
+		MaxBodySize: 10,
+		Log: func(ctx context.Context, e httplog.Entry) {
+			entry = e
+		},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", endlessReader{})
+	req.Header.Set("Content-Type", "application/json")
+	srv.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.EqualValues(t, 11, entry.RequestSize)
+	assert.Nil(t, entry.RequestBody)
+}
@@ -0,0 +1,158 @@
+// Package httpmask replace the JSON request and response body with the masked one,
+// so the logging middleware (or anything else) wrapped by it never see the sensitive values.
+//
+// Unlike httplog, the wrapped handler receives the masked request body and the client receives the masked response,
+// i.e: place it between the outer logging middleware and the handler whose traffic must be masked:
+//
+//	logging(httpmask.Middleware(transformer, handler))
+package httpmask
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+// DefaultMaxBodySize is the maximum body size masked when Config.MaxBodySize is not set.
+const DefaultMaxBodySize = 1024 * 1024
+
+type Config struct {
+	// Processor mask the JSON body, it must not be nil.
+	Processor jsonutil.Processor
+
+	// MaxBodySize is the maximum body size in bytes to be masked. Default to DefaultMaxBodySize.
+	// Larger body is passed as is, since it cannot be masked without buffering it entirely.
+	MaxBodySize int
+}
+
+type middleware struct {
+	conf Config
+	next http.Handler
+}
+
+// Middleware mask the JSON request and response body using m, with DefaultMaxBodySize.
+func Middleware(m *jsonutil.Transformer, next http.Handler) http.Handler {
+	return MiddlewareWithConfig(Config{Processor: m}, next)
+}
+
+// MiddlewareWithConfig mask the JSON request and response body using Config.Processor.
+// Only body with JSON content type (see jsonutil.IsJSONMediaType) is masked,
+// body which is invalid JSON or larger than Config.MaxBodySize is passed as is.
+func MiddlewareWithConfig(conf Config, next http.Handler) http.Handler {
+	if conf.MaxBodySize <= 0 {
+		conf.MaxBodySize = DefaultMaxBodySize
+	}
+
+	return &middleware{conf: conf, next: next}
+}
+
+func (m *middleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Body != nil && r.Body != http.NoBody && jsonutil.IsJSONMediaType(r.Header.Get("Content-Type")) {
+		m.maskRequest(ctx, r)
+	}
+
+	rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK, limit: m.conf.MaxBodySize}
+	m.next.ServeHTTP(rw, r)
+
+	if !rw.buffering {
+		return
+	}
+
+	body := rw.buf.Bytes()
+	if out, err := m.conf.Processor.Process(ctx, body); err == nil {
+		body = out
+	}
+
+	rw.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(rw.statusCode)
+	_, _ = w.Write(body)
+}
+
+// maskRequest replace r.Body with the masked one, or keep the original content when it cannot be masked.
+func (m *middleware) maskRequest(ctx context.Context, r *http.Request) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, int64(m.conf.MaxBodySize)+1))
+	if err != nil || len(body) > m.conf.MaxBodySize {
+		// put back what is already read
+		r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), r.Body), Closer: r.Body}
+		return
+	}
+
+	_ = r.Body.Close()
+	if out, err := m.conf.Processor.Process(ctx, body); err == nil {
+		body = out
+	}
+
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// responseWriter buffer the JSON response until the handler returns.
+// When the response is not JSON, is flushed, or is larger than the limit, it is written through as is.
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	buffering   bool
+	limit       int
+	buf         bytes.Buffer
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+
+	w.statusCode = statusCode
+	w.wroteHeader = true
+	w.buffering = jsonutil.IsJSONMediaType(w.Header().Get("Content-Type"))
+	if !w.buffering {
+		w.ResponseWriter.WriteHeader(statusCode)
+	}
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if !w.buffering {
+		return w.ResponseWriter.Write(p)
+	}
+
+	if w.buf.Len()+len(p) > w.limit {
+		if err := w.passThrough(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(p)
+	}
+
+	return w.buf.Write(p)
+}
+
+func (w *responseWriter) Flush() {
+	if w.buffering {
+		_ = w.passThrough()
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// passThrough stop buffering and write the buffered response as is.
+func (w *responseWriter) passThrough() error {
+	w.buffering = false
+	w.ResponseWriter.WriteHeader(w.statusCode)
+	_, err := w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return err
+}
@@ -0,0 +1,77 @@
+package httpmask_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"github.com/yusufsyaifudin/jsonutil/httpmask"
+)
+
+var transformer = jsonutil.NewTransformer(jsonutil.Config{
+	Keys: map[string]jsonutil.StringTransformer{
+		"password": func(ctx context.Context, info jsonutil.KVInfo) string { return "xxx" },
+		"token":    func(ctx context.Context, info jsonutil.KVInfo) string { return "xxx" },
+	},
+})
+
+func TestMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"user":"a","password":"xxx"}`, string(body))
+		assert.EqualValues(t, len(body), r.ContentLength)
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"token":`))
+		_, _ = w.Write([]byte(`"abc"}`))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"user":"a","password":"secret"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	httpmask.Middleware(transformer, handler).ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `{"token":"xxx"}`, rec.Body.String())
+	assert.Equal(t, "15", rec.Header().Get("Content-Length"))
+}
+
+func TestMiddleware_PassThrough(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		ContentType string
+		Body        string
+	}{
+		{Name: "not json", ContentType: "text/plain", Body: `{"password":"secret"}`},
+		{Name: "invalid json", ContentType: "application/json", Body: `{"password":`},
+		{Name: "too large", ContentType: "application/json", Body: `{"password":"` + strings.Repeat("a", 32) + `"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.Body, string(body))
+
+				w.Header().Set("Content-Type", tc.ContentType)
+				_, _ = w.Write(body)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.Body))
+			req.Header.Set("Content-Type", tc.ContentType)
+			rec := httptest.NewRecorder()
+			httpmask.MiddlewareWithConfig(httpmask.Config{Processor: transformer, MaxBodySize: 32}, handler).ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tc.Body, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"sync"
+)
+
+// internMaxLength is the longest string interned by Interner,
+// longer strings are rarely repeated so they are not worth the table entry.
+const internMaxLength = 128
+
+// Interner decode JSON into interface{} (the same value as json.Unmarshal produce),
+// but every object key and string value up to 128 bytes is shared with the previously decoded equal string.
+// It cuts the heap usage when many decoded documents with repeated keys and values
+// (i.e: large homogeneous arrays) is held in memory, at the cost of keeping the table alive.
+// Interner is safe for concurrent use, use one Interner per batch and drop it (or call Reset) afterwards.
+type Interner struct {
+	mu      sync.Mutex
+	strings map[string]string
+}
+
+// NewInterner return empty Interner.
+func NewInterner() *Interner {
+	return &Interner{strings: make(map[string]string)}
+}
+
+// Decode decode doc with interned strings, number is decoded as float64.
+func (in *Interner) Decode(doc []byte) (interface{}, error) {
+	start, err := scanDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	v, _, err := in.decode(doc, start)
+	return v, err
+}
+
+// Unmarshal has the same signature as json.Unmarshal, so it can be used as Config.JSONUnmarshal:
+//
+//	jsonutil.NewTransformer(jsonutil.Config{JSONUnmarshal: jsonutil.NewInterner().Unmarshal})
+//
+// Only *interface{} is decoded with interned strings, other types is passed to json.Unmarshal.
+func (in *Interner) Unmarshal(data []byte, v interface{}) error {
+	ptr, ok := v.(*interface{})
+	if !ok {
+		return json.Unmarshal(data, v)
+	}
+
+	decoded, err := in.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	*ptr = decoded
+	return nil
+}
+
+// Len return the number of distinct strings in the table.
+func (in *Interner) Len() int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	return len(in.strings)
+}
+
+// Reset empty the table, previously decoded values is not affected.
+func (in *Interner) Reset() {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	in.strings = make(map[string]string)
+}
+
+func (in *Interner) decode(data []byte, i int) (interface{}, int, error) {
+	switch data[i] {
+	case '{':
+		m := make(map[string]interface{})
+		var err error
+		end, scanErr := scanObject(data, i, func(_ string, member objectMember) bool {
+			var key interface{}
+			key, _, err = in.decode(data, member.keyStart)
+			if err != nil {
+				return false
+			}
+
+			m[key.(string)], _, err = in.decode(data, member.valueStart)
+			return err == nil
+		})
+		if scanErr != nil {
+			return nil, end, scanErr
+		}
+		return m, end, err
+
+	case '[':
+		s := make([]interface{}, 0)
+		var err error
+		end, scanErr := scanArray(data, i, func(idx, start, end int) bool {
+			var v interface{}
+			v, _, err = in.decode(data, start)
+			s = append(s, v)
+			return err == nil
+		})
+		if scanErr != nil {
+			return nil, end, scanErr
+		}
+		return s, end, err
+
+	case '"':
+		end, err := scanString(data, i)
+		if err != nil {
+			return nil, end, err
+		}
+
+		str, err := in.intern(data[i:end])
+		return str, end, err
+
+	case 't':
+		return true, i + 4, nil
+
+	case 'f':
+		return false, i + 5, nil
+
+	case 'n':
+		return nil, i + 4, nil
+	}
+
+	end, err := scanNumber(data, i)
+	if err != nil {
+		return nil, end, err
+	}
+
+	f, err := strconv.ParseFloat(string(data[i:end]), 64)
+	return f, end, err
+}
+
+// intern return the decoded JSON string raw (including quotes) from the table.
+// String without escape sequence is stored by its content, so the table holds only one copy of it,
+// otherwise by its raw form, which never collide since the content cannot contain a quote.
+func (in *Interner) intern(raw []byte) (string, error) {
+	if len(raw)-2 > internMaxLength {
+		return unquote(raw)
+	}
+
+	key := raw
+	if bytes.IndexByte(raw, '\\') < 0 {
+		key = raw[1 : len(raw)-1]
+	}
+
+	// string(key) as the map index doesn't allocate
+	if str, ok := in.strings[string(key)]; ok {
+		return str, nil
+	}
+
+	str, err := unquote(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if len(key) == len(raw) {
+		in.strings[string(key)] = str
+	} else {
+		in.strings[str] = str
+	}
+
+	return str, nil
+}
@@ -0,0 +1,71 @@
+package jsonutil_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func stringData(s string) uintptr {
+	return (*reflect.StringHeader)(unsafe.Pointer(&s)).Data
+}
+
+func TestInterner_Decode(t *testing.T) {
+	doc := []byte(`{"users":[{"status":"active","role":"admin","n":1.5},{"status":"active","role":"adm\u0069n","n":-2,"ok":true,"x":null}],"e":[],"o":{}}`)
+
+	var expect interface{}
+	assert.NoError(t, json.Unmarshal(doc, &expect))
+
+	in := jsonutil.NewInterner()
+	got, err := in.Decode(doc)
+	assert.NoError(t, err)
+	assert.Equal(t, expect, got)
+
+	users := got.(map[string]interface{})["users"].([]interface{})
+	first, second := users[0].(map[string]interface{}), users[1].(map[string]interface{})
+	assert.Equal(t, stringData(first["status"].(string)), stringData(second["status"].(string)))
+
+	// the escaped form is interned separately
+	assert.Equal(t, "admin", second["role"])
+
+	// shared across documents
+	again, err := in.Decode([]byte(`["active"]`))
+	assert.NoError(t, err)
+	assert.Equal(t, stringData(first["status"].(string)), stringData(again.([]interface{})[0].(string)))
+
+	assert.Equal(t, 11, in.Len())
+	in.Reset()
+	assert.Equal(t, 0, in.Len())
+}
+
+func TestInterner_Unmarshal(t *testing.T) {
+	in := jsonutil.NewInterner()
+	tr := jsonutil.NewTransformer(jsonutil.Config{JSONUnmarshal: in.Unmarshal})
+
+	out, err := tr.TransformBytes(context.Background(), []byte(`[{"a":"b"},{"a":"b"}]`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"a":"b"},{"a":"b"}]`, string(out))
+	assert.Equal(t, 2, in.Len())
+
+	var typed struct {
+		A string `json:"a"`
+	}
+	assert.NoError(t, in.Unmarshal([]byte(`{"a":"b"}`), &typed))
+	assert.Equal(t, "b", typed.A)
+}
+
+func TestInterner_Error(t *testing.T) {
+	in := jsonutil.NewInterner()
+
+	_, err := in.Decode([]byte(`{"a":`))
+	assert.ErrorIs(t, err, jsonutil.ErrUnexpectedEnd)
+
+	var v interface{}
+	assert.Error(t, in.Unmarshal([]byte(`[1,]`), &v))
+	assert.Nil(t, v)
+}
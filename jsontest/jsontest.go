@@ -0,0 +1,270 @@
+// Package jsontest provide helpers to compare JSON output against golden files in tests.
+package jsontest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+// update is namespaced, so it doesn't conflict with the -update flag commonly defined by the tested package.
+var update = flag.Bool("jsontest.update", false, "rewrite the golden files with the actual output")
+
+// Ignored replace the value of ignored path in the compared documents.
+const Ignored = "<ignored>"
+
+type options struct {
+	ignore [][]string
+	mask   []string
+}
+
+// Option configure AssertMatchesGolden.
+type Option func(*options)
+
+// IgnorePaths skip the comparison of volatile values (i.e: timestamp or generated id) on paths.
+// Path is in dotted form or JSON Pointer (see jsonutil.SplitPath), "*" match any single segment, i.e: items.*.id
+func IgnorePaths(paths ...string) Option {
+	return func(o *options) {
+		for _, path := range paths {
+			o.ignore = append(o.ignore, jsonutil.SplitPath(path))
+		}
+	}
+}
+
+// MaskPaths mask the value on paths of the actual document using jsonutil.ActionMask before the comparison,
+// so the expected document can be written with the placeholder jsonutil.DefaultPlaceholder instead of the real secret.
+// Path is in the form accepted by jsonutil.Rule Paths, "*" match any single segment.
+func MaskPaths(paths ...string) Option {
+	return func(o *options) {
+		o.mask = append(o.mask, paths...)
+	}
+}
+
+// AssertEqual compare expected and actual JSON semantically:
+// the key order and formatting is not significant, and the value on ignored paths is not compared.
+func AssertEqual(t testing.TB, expected, actual []byte, opts ...Option) bool {
+	t.Helper()
+
+	o := newOptions(opts)
+	actualData, err := o.normalizeActual(actual)
+	if err != nil {
+		t.Errorf("jsontest: invalid actual JSON: %v", err)
+		return false
+	}
+
+	expectedData, err := normalize(expected, o)
+	if err != nil {
+		t.Errorf("jsontest: invalid expected JSON: %v", err)
+		return false
+	}
+
+	return compare(t, expectedData, actualData, "JSON is not equal")
+}
+
+// AssertMatchesGolden compare got with the JSON in goldenPath semantically:
+// the key order and formatting is not significant, and the value on ignored paths is not compared.
+// When the test is run with -jsontest.update flag, the golden file is rewritten with got (indented, keys sorted) instead,
+// after MaskPaths is applied so the secret never lands in the golden file.
+func AssertMatchesGolden(t testing.TB, got []byte, goldenPath string, opts ...Option) {
+	t.Helper()
+
+	o := newOptions(opts)
+	got, err := o.maskDoc(got)
+	if err != nil {
+		t.Fatalf("jsontest: invalid actual JSON: %v", err)
+		return
+	}
+
+	actual, err := normalize(got, o)
+	if err != nil {
+		t.Fatalf("jsontest: invalid actual JSON: %v", err)
+		return
+	}
+
+	if *update {
+		if err = writeGolden(got, goldenPath); err != nil {
+			t.Fatalf("jsontest: cannot update golden file %s: %v", goldenPath, err)
+		}
+		return
+	}
+
+	golden, err := ioutil.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("jsontest: cannot read golden file (run with -jsontest.update to create it): %v", err)
+		return
+	}
+
+	expected, err := normalize(golden, o)
+	if err != nil {
+		t.Fatalf("jsontest: invalid JSON in golden file %s: %v", goldenPath, err)
+		return
+	}
+
+	compare(t, expected, actual, "JSON does not match golden file "+goldenPath)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// maskDoc apply MaskPaths to doc.
+func (o *options) maskDoc(doc []byte) ([]byte, error) {
+	if len(o.mask) == 0 {
+		return doc, nil
+	}
+
+	ruleSet, err := jsonutil.NewRuleSet([]jsonutil.Rule{{Paths: o.mask, Action: jsonutil.ActionMask}})
+	if err != nil {
+		return nil, err
+	}
+
+	return ruleSet.Process(context.Background(), doc)
+}
+
+func (o *options) normalizeActual(doc []byte) (interface{}, error) {
+	doc, err := o.maskDoc(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return normalize(doc, o)
+}
+
+// compare report the different paths between expected and actual with msg, and return true when they are equal.
+func compare(t testing.TB, expected, actual interface{}, msg string) bool {
+	t.Helper()
+
+	if reflect.DeepEqual(expected, actual) {
+		return true
+	}
+
+	diff := []string{}
+	diffPaths(expected, actual, []string{}, &diff)
+	t.Errorf("jsontest: %s\ndifferent paths: %s\nexpected:\n%s\nactual:\n%s",
+		msg, strings.Join(diff, ", "), indent(expected), indent(actual))
+	return false
+}
+
+// normalize decode doc with json.Number and replace the value on ignored paths with Ignored.
+func normalize(doc []byte, o *options) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+
+	var data interface{}
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	return replaceIgnored(data, []string{}, o.ignore), nil
+}
+
+func replaceIgnored(data interface{}, segments []string, ignore [][]string) interface{} {
+	for _, pattern := range ignore {
+		if matchSegments(pattern, segments) {
+			return Ignored
+		}
+	}
+
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = replaceIgnored(val, append(segments, key), ignore)
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = replaceIgnored(val, append(segments, strconv.Itoa(i)), ignore)
+		}
+	}
+
+	return data
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+
+	for i, p := range pattern {
+		if p != "*" && p != segments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// diffPaths append the dotted path of every different value between a and b into diff.
+func diffPaths(a, b interface{}, segments []string, diff *[]string) {
+	mapA, okA := a.(map[string]interface{})
+	mapB, okB := b.(map[string]interface{})
+	if okA && okB {
+		keys := make([]string, 0, len(mapA)+len(mapB))
+		for key := range mapA {
+			keys = append(keys, key)
+		}
+		for key := range mapB {
+			if _, ok := mapA[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			diffPaths(mapA[key], mapB[key], append(segments, key), diff)
+		}
+		return
+	}
+
+	sliceA, okA := a.([]interface{})
+	sliceB, okB := b.([]interface{})
+	if okA && okB && len(sliceA) == len(sliceB) {
+		for i := range sliceA {
+			diffPaths(sliceA[i], sliceB[i], append(segments, strconv.Itoa(i)), diff)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*diff = append(*diff, "/"+strings.TrimPrefix(jsonutil.JoinPointer(segments), "/"))
+	}
+}
+
+func indent(data interface{}) string {
+	b, _ := json.MarshalIndent(data, "", "  ")
+	return string(b)
+}
+
+func writeGolden(got []byte, goldenPath string) error {
+	var buf bytes.Buffer
+	canonical, err := jsonutil.Canonicalize(got)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Indent(&buf, canonical, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteByte('\n')
+
+	if err = os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(goldenPath, buf.Bytes(), 0644)
+}
@@ -0,0 +1,123 @@
+package jsontest_test
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil/jsontest"
+)
+
+// the tested package may define its own -update flag without conflict with jsontest.
+var _ = flag.Bool("update", false, "update the golden files of the tested package")
+
+// recorder capture the failure instead of failing the test.
+type recorder struct {
+	testing.TB
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+	r.fatal = true
+}
+
+func TestAssertMatchesGolden(t *testing.T) {
+	golden := filepath.Join("testdata", "user.golden.json")
+
+	t.Run("match with different order and ignored paths", func(t *testing.T) {
+		got := `{"name":"alice","items":[{"name":"book","id":"xyz"}],"created_at":"2024-05-05T10:00:00Z"}`
+		jsontest.AssertMatchesGolden(t, []byte(got), golden, jsontest.IgnorePaths("created_at", "items.*.id"))
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		r := &recorder{}
+		got := `{"name":"bob","items":[{"name":"book","id":"xyz"}],"created_at":"2024-05-05T10:00:00Z"}`
+		jsontest.AssertMatchesGolden(r, []byte(got), golden, jsontest.IgnorePaths("/created_at"))
+
+		assert.Len(t, r.errors, 1)
+		assert.False(t, r.fatal)
+		assert.Contains(t, r.errors[0], "different paths: /items/0/id, /name")
+	})
+
+	t.Run("missing golden file", func(t *testing.T) {
+		r := &recorder{}
+		jsontest.AssertMatchesGolden(r, []byte(`{}`), filepath.Join("testdata", "missing.json"))
+		assert.True(t, r.fatal)
+	})
+
+	t.Run("invalid actual", func(t *testing.T) {
+		r := &recorder{}
+		jsontest.AssertMatchesGolden(r, []byte(`{`), golden)
+		assert.True(t, r.fatal)
+	})
+}
+
+func TestAssertMatchesGolden_Update(t *testing.T) {
+	assert.NoError(t, flag.Set("jsontest.update", "true"))
+	defer flag.Set("jsontest.update", "false")
+
+	dir, err := ioutil.TempDir("", "jsontest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	golden := filepath.Join(dir, "new", "golden.json")
+	jsontest.AssertMatchesGolden(t, []byte(`{"b":1,"a":{"c":"<x>"}}`), golden)
+
+	b, err := ioutil.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": {\n    \"c\": \"<x>\"\n  },\n  \"b\": 1\n}\n", string(b))
+
+	assert.NoError(t, flag.Set("jsontest.update", "false"))
+	jsontest.AssertMatchesGolden(t, []byte(`{"a":{"c":"<x>"},"b":1}`), golden)
+}
+
+func TestAssertEqual(t *testing.T) {
+	actual := []byte(`{"user":{"password":"secret","name":"alice"},"tokens":[{"value":"t1"},{"value":"t2"}],"request_id":"r-123"}`)
+	expected := []byte(`{
+		"request_id": "any",
+		"tokens": [{"value": "***"}, {"value": "***"}],
+		"user": {"name": "alice", "password": "***"}
+	}`)
+
+	ok := jsontest.AssertEqual(t, expected, actual,
+		jsontest.IgnorePaths("request_id"),
+		jsontest.MaskPaths("user.password", "tokens.*.value"),
+	)
+	assert.True(t, ok)
+
+	t.Run("not equal", func(t *testing.T) {
+		r := &recorder{}
+		ok := jsontest.AssertEqual(r, expected, actual, jsontest.IgnorePaths("request_id"), jsontest.MaskPaths("user.password"))
+		assert.False(t, ok)
+		assert.Len(t, r.errors, 1)
+		assert.Contains(t, r.errors[0], "different paths: /tokens/0/value, /tokens/1/value")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		r := &recorder{}
+		assert.False(t, jsontest.AssertEqual(r, []byte(`{}`), []byte(`{`)))
+		assert.False(t, jsontest.AssertEqual(r, []byte(`[`), []byte(`{}`)))
+		assert.Len(t, r.errors, 2)
+	})
+}
+
+func TestAssertMatchesGolden_MaskPaths(t *testing.T) {
+	got := `{"name":"alice","items":[{"name":"book","id":"real-id"}],"created_at":"2023-01-01T00:00:00Z"}`
+
+	r := &recorder{}
+	jsontest.AssertMatchesGolden(r, []byte(got), filepath.Join("testdata", "user.golden.json"), jsontest.MaskPaths("items.0.id"))
+	assert.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], "different paths: /items/0/id")
+}
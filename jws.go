@@ -0,0 +1,245 @@
+package jsonutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// JWSSigner sign the JWS signing input using the algorithm (JWA "alg" name, i.e: HS256).
+type JWSSigner interface {
+	Algorithm() string
+	Sign(signingInput []byte) ([]byte, error)
+}
+
+// JWSVerifier verify the signature of JWS signing input, it must return error when the signature is invalid.
+type JWSVerifier interface {
+	Algorithm() string
+	Verify(signingInput, signature []byte) error
+}
+
+type jwsHeader struct {
+	Alg  string   `json:"alg"`
+	B64  *bool    `json:"b64,omitempty"`
+	Crit []string `json:"crit,omitempty"`
+}
+
+// SignDetachedJWS return the detached JWS compact serialization (<header>..<signature>) of payload,
+// using the unencoded payload option (RFC 7797), so the payload is signed as is and sent separately.
+func SignDetachedJWS(payload []byte, signer JWSSigner) (string, error) {
+	b64 := false
+	header, err := json.Marshal(jwsHeader{Alg: signer.Algorithm(), B64: &b64, Crit: []string{"b64"}})
+	if err != nil {
+		return "", err
+	}
+
+	encodedHeader := base64.RawURLEncoding.EncodeToString(header)
+	signature, err := signer.Sign(jwsSigningInput(encodedHeader, payload, false))
+	if err != nil {
+		return "", err
+	}
+
+	return encodedHeader + ".." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// VerifyDetachedJWS verify the detached JWS of payload.
+// Both unencoded (RFC 7797, b64 false) and regular base64url encoded payload is supported.
+// It returns ErrInvalidSignature when the signature does not match.
+func VerifyDetachedJWS(payload []byte, jws string, verifier JWSVerifier) error {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return fmt.Errorf("jsonutil: malformed detached jws")
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("jsonutil: malformed jws header: %w", err)
+	}
+
+	var header jwsHeader
+	if err = json.Unmarshal(rawHeader, &header); err != nil {
+		return fmt.Errorf("jsonutil: malformed jws header: %w", err)
+	}
+
+	if header.Alg != verifier.Algorithm() {
+		return fmt.Errorf("jsonutil: jws algorithm %q does not match verifier %q", header.Alg, verifier.Algorithm())
+	}
+
+	if err = checkJWSCrit(header); err != nil {
+		return err
+	}
+
+	encoded := header.B64 == nil || *header.B64
+	if !encoded && !containsString(header.Crit, "b64") {
+		return fmt.Errorf("jsonutil: jws header b64 must be listed in crit")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("jsonutil: malformed jws signature: %w", err)
+	}
+
+	if err = verifier.Verify(jwsSigningInput(parts[0], payload, encoded), signature); err != nil {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// checkJWSCrit reject the critical header parameter which is not understood (RFC 7515 section 4.1.11),
+// only b64 (RFC 7797) is supported.
+func checkJWSCrit(header jwsHeader) error {
+	if header.Crit != nil && len(header.Crit) == 0 {
+		return fmt.Errorf("jsonutil: jws header crit must not be empty")
+	}
+
+	for _, name := range header.Crit {
+		if name != "b64" {
+			return fmt.Errorf("jsonutil: unsupported critical jws header %q", name)
+		}
+
+		if header.B64 == nil {
+			return fmt.Errorf("jsonutil: critical jws header %q is missing", name)
+		}
+	}
+
+	return nil
+}
+
+func jwsSigningInput(encodedHeader string, payload []byte, encoded bool) []byte {
+	if encoded {
+		return []byte(encodedHeader + "." + base64.RawURLEncoding.EncodeToString(payload))
+	}
+
+	input := make([]byte, 0, len(encodedHeader)+1+len(payload))
+	input = append(input, encodedHeader...)
+	input = append(input, '.')
+	return append(input, payload...)
+}
+
+func containsString(list []string, str string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HMACSigner is JWSSigner and JWSVerifier using HS256.
+type HMACSigner struct {
+	Key []byte
+}
+
+func (s HMACSigner) Algorithm() string {
+	return "HS256"
+}
+
+func (s HMACSigner) Sign(signingInput []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write(signingInput)
+	return mac.Sum(nil), nil
+}
+
+func (s HMACSigner) Verify(signingInput, signature []byte) error {
+	expected, _ := s.Sign(signingInput)
+	if !hmac.Equal(expected, signature) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// RSASigner is JWSSigner using RS256.
+type RSASigner struct {
+	PrivateKey *rsa.PrivateKey
+}
+
+func (s RSASigner) Algorithm() string {
+	return "RS256"
+}
+
+func (s RSASigner) Sign(signingInput []byte) ([]byte, error) {
+	digest := sha256.Sum256(signingInput)
+	return rsa.SignPKCS1v15(rand.Reader, s.PrivateKey, crypto.SHA256, digest[:])
+}
+
+// RSAVerifier is JWSVerifier using RS256.
+type RSAVerifier struct {
+	PublicKey *rsa.PublicKey
+}
+
+func (v RSAVerifier) Algorithm() string {
+	return "RS256"
+}
+
+func (v RSAVerifier) Verify(signingInput, signature []byte) error {
+	digest := sha256.Sum256(signingInput)
+	return rsa.VerifyPKCS1v15(v.PublicKey, crypto.SHA256, digest[:], signature)
+}
+
+// ECDSASigner is JWSSigner using ES256, the private key must be on P-256 curve.
+type ECDSASigner struct {
+	PrivateKey *ecdsa.PrivateKey
+}
+
+func (s ECDSASigner) Algorithm() string {
+	return "ES256"
+}
+
+func (s ECDSASigner) Sign(signingInput []byte) ([]byte, error) {
+	if s.PrivateKey.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("jsonutil: ES256 requires P-256 key")
+	}
+
+	digest := sha256.Sum256(signingInput)
+	r, ss, err := ecdsa.Sign(rand.Reader, s.PrivateKey, digest[:])
+	if err != nil {
+		return nil, err
+	}
+
+	// JWS use fixed size r || s instead of ASN.1
+	signature := make([]byte, 64)
+	rb, sb := r.Bytes(), ss.Bytes()
+	copy(signature[32-len(rb):32], rb)
+	copy(signature[64-len(sb):], sb)
+	return signature, nil
+}
+
+// ECDSAVerifier is JWSVerifier using ES256, the public key must be on P-256 curve.
+type ECDSAVerifier struct {
+	PublicKey *ecdsa.PublicKey
+}
+
+func (v ECDSAVerifier) Algorithm() string {
+	return "ES256"
+}
+
+func (v ECDSAVerifier) Verify(signingInput, signature []byte) error {
+	if v.PublicKey == nil || v.PublicKey.Curve != elliptic.P256() {
+		return fmt.Errorf("jsonutil: ES256 requires P-256 key")
+	}
+
+	if len(signature) != 64 {
+		return ErrInvalidSignature
+	}
+
+	digest := sha256.Sum256(signingInput)
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(v.PublicKey, digest[:], r, s) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
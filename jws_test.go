@@ -0,0 +1,99 @@
+package jsonutil_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestDetachedJWS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	payload := []byte(`{"order_id":"123","amount":100}`)
+	tests := []struct {
+		Name     string
+		Signer   jsonutil.JWSSigner
+		Verifier jsonutil.JWSVerifier
+	}{
+		{Name: "HS256", Signer: jsonutil.HMACSigner{Key: []byte("secret")}, Verifier: jsonutil.HMACSigner{Key: []byte("secret")}},
+		{Name: "RS256", Signer: jsonutil.RSASigner{PrivateKey: rsaKey}, Verifier: jsonutil.RSAVerifier{PublicKey: &rsaKey.PublicKey}},
+		{Name: "ES256", Signer: jsonutil.ECDSASigner{PrivateKey: ecKey}, Verifier: jsonutil.ECDSAVerifier{PublicKey: &ecKey.PublicKey}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			jws, err := jsonutil.SignDetachedJWS(payload, tc.Signer)
+			assert.NoError(t, err)
+
+			parts := strings.Split(jws, ".")
+			assert.Len(t, parts, 3)
+			assert.Empty(t, parts[1])
+
+			header, err := base64.RawURLEncoding.DecodeString(parts[0])
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"alg":"`+tc.Name+`","b64":false,"crit":["b64"]}`, string(header))
+
+			assert.NoError(t, jsonutil.VerifyDetachedJWS(payload, jws, tc.Verifier))
+			assert.ErrorIs(t, jsonutil.VerifyDetachedJWS([]byte(`{"order_id":"123","amount":1000}`), jws, tc.Verifier), jsonutil.ErrInvalidSignature)
+		})
+	}
+}
+
+func TestVerifyDetachedJWS_Encoded(t *testing.T) {
+	payload := []byte(`{"a":1}`)
+	signer := jsonutil.HMACSigner{Key: []byte("secret")}
+
+	// regular JWS header without b64, the payload is base64url encoded in the signing input
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256"}`))
+	signature, err := signer.Sign([]byte(header + "." + base64.RawURLEncoding.EncodeToString(payload)))
+	assert.NoError(t, err)
+
+	assert.NoError(t, jsonutil.VerifyDetachedJWS(payload, header+".."+base64.RawURLEncoding.EncodeToString(signature), signer))
+}
+
+func TestVerifyDetachedJWS_Error(t *testing.T) {
+	payload := []byte(`{"a":1}`)
+	jws, err := jsonutil.SignDetachedJWS(payload, jsonutil.HMACSigner{Key: []byte("secret")})
+	assert.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	assert.EqualError(t, jsonutil.VerifyDetachedJWS(payload, jws, jsonutil.ECDSAVerifier{PublicKey: &ecKey.PublicKey}),
+		`jsonutil: jws algorithm "HS256" does not match verifier "ES256"`)
+	assert.Error(t, jsonutil.VerifyDetachedJWS(payload, "a.b.c", jsonutil.HMACSigner{}))
+	assert.Error(t, jsonutil.VerifyDetachedJWS(payload, "!!..abc", jsonutil.HMACSigner{}))
+
+	noCrit := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","b64":false}`))
+	assert.EqualError(t, jsonutil.VerifyDetachedJWS(payload, noCrit+"..abc", jsonutil.HMACSigner{}), "jsonutil: jws header b64 must be listed in crit")
+
+	// valid signature, but the header has the critical parameter which is not understood
+	hmac := jsonutil.HMACSigner{Key: []byte("secret")}
+	for header, expected := range map[string]string{
+		`{"alg":"HS256","b64":false,"crit":["b64","exp"]}`: `jsonutil: unsupported critical jws header "exp"`,
+		`{"alg":"HS256","crit":[]}`:                        `jsonutil: jws header crit must not be empty`,
+		`{"alg":"HS256","crit":["b64"]}`:                   `jsonutil: critical jws header "b64" is missing`,
+	} {
+		encodedHeader := base64.RawURLEncoding.EncodeToString([]byte(header))
+		signature, err := hmac.Sign([]byte(encodedHeader + "." + string(payload)))
+		assert.NoError(t, err)
+
+		jws := encodedHeader + ".." + base64.RawURLEncoding.EncodeToString(signature)
+		assert.EqualError(t, jsonutil.VerifyDetachedJWS(payload, jws, hmac), expected, header)
+	}
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	assert.EqualError(t, jsonutil.ECDSAVerifier{PublicKey: &p384.PublicKey}.Verify(payload, make([]byte, 64)), "jsonutil: ES256 requires P-256 key")
+}
@@ -0,0 +1,64 @@
+package jsonutil
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrKeyCollision is returned when Config.KeyTransformer rename two keys of the same object into the same key.
+var ErrKeyCollision = errors.New("jsonutil: key collision")
+
+// KeyPattern match object key using regular expression or glob, see Config.KeyPatterns.
+type KeyPattern struct {
+	pattern     string
+	regex       *regexp.Regexp
+	transformer StringTransformer
+}
+
+// RegexKey return KeyPattern which use transformer for the key matching the regular expression, i.e: `_token$`.
+// The expression is not anchored, use ^ and $ to match the whole key.
+func RegexKey(expr string, transformer StringTransformer) (KeyPattern, error) {
+	regex, err := regexp.Compile(expr)
+	if err != nil {
+		return KeyPattern{}, err
+	}
+
+	return KeyPattern{pattern: expr, regex: regex, transformer: transformer}, nil
+}
+
+// GlobKey return KeyPattern which use transformer for the key matching the glob pattern, i.e: password* or *_secret.
+// The whole key must match, "*" match any characters (including none) and "?" match any single character.
+func GlobKey(glob string, transformer StringTransformer) (KeyPattern, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString("$")
+
+	// (?s) so "*" also match newline inside the key
+	regex, err := regexp.Compile("(?s)" + sb.String())
+	if err != nil {
+		return KeyPattern{}, err
+	}
+
+	return KeyPattern{pattern: glob, regex: regex, transformer: transformer}, nil
+}
+
+// String return the pattern as written.
+func (p KeyPattern) String() string {
+	return p.pattern
+}
+
+// Match return true if key match the pattern.
+func (p KeyPattern) Match(key string) bool {
+	return p.regex != nil && p.transformer != nil && p.regex.MatchString(key)
+}
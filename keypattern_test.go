@@ -0,0 +1,113 @@
+package jsonutil_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestKeyPattern(t *testing.T) {
+	glob, err := jsonutil.GlobKey("password*", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "password*", glob.String())
+	assert.False(t, glob.Match("password"), "pattern without transformer never match")
+
+	glob, err = jsonutil.GlobKey("pass?ord*", func(ctx context.Context, info jsonutil.KVInfo) string { return "" })
+	assert.NoError(t, err)
+	assert.True(t, glob.Match("password"))
+	assert.True(t, glob.Match("passWord_hash"))
+	assert.False(t, glob.Match("old_password"))
+	assert.False(t, glob.Match("passw"))
+
+	dot, err := jsonutil.GlobKey("a.b", func(ctx context.Context, info jsonutil.KVInfo) string { return "" })
+	assert.NoError(t, err)
+	assert.True(t, dot.Match("a.b"))
+	assert.False(t, dot.Match("axb"))
+
+	regex, err := jsonutil.RegexKey(`.*_token$`, func(ctx context.Context, info jsonutil.KVInfo) string { return "" })
+	assert.NoError(t, err)
+	assert.True(t, regex.Match("access_token"))
+	assert.False(t, regex.Match("token_type"))
+
+	_, err = jsonutil.RegexKey(`(`, nil)
+	assert.Error(t, err)
+}
+
+func TestTransformer_KeyPatterns(t *testing.T) {
+	constant := func(v string) jsonutil.StringTransformer {
+		return func(ctx context.Context, info jsonutil.KVInfo) string {
+			return v
+		}
+	}
+
+	token, err := jsonutil.RegexKey(`_token$`, constant("token"))
+	assert.NoError(t, err)
+
+	password, err := jsonutil.GlobKey("password*", constant("password"))
+	assert.NoError(t, err)
+
+	catchAll, err := jsonutil.GlobKey("*", constant("any"))
+	assert.NoError(t, err)
+
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		Keys:        map[string]jsonutil.StringTransformer{"password_hint": constant("exact")},
+		KeyPatterns: []jsonutil.KeyPattern{token, password, catchAll},
+		Paths:       map[string]jsonutil.StringTransformer{"$.keep.password": jsonutil.DefaultStringTransformer},
+	})
+
+	out, err := tr.TransformBytes(context.Background(), []byte(`{"access_token":"a","password":"b","password_hint":"c","passwords":["d"],"name":"e","keep":{"password":"f"}}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"access_token":"token","password":"password","password_hint":"exact","passwords":["password"],"name":"any","keep":{"password":"f"}}`, string(out))
+}
+
+func TestTransformer_MaskKeys(t *testing.T) {
+	email, err := jsonutil.RegexKey(`@`, func(ctx context.Context, info jsonutil.KVInfo) string {
+		return "user_" + strconv.Itoa(len(info.Value))
+	})
+	assert.NoError(t, err)
+
+	doc := `{"john@example.com":{"password":"a","name":"john"},"users":[{"al@example.com":"x"}],"plain":{"key":"v"}}`
+	expect := `{"user_16":{"password":"***","name":"john"},"users":[{"user_14":"x"}],"plain":{"key":"v"}}`
+	conf := jsonutil.Config{
+		MaskKeys: []jsonutil.KeyPattern{email},
+		Keys: map[string]jsonutil.StringTransformer{
+			"password": func(ctx context.Context, info jsonutil.KVInfo) string { return "***" },
+		},
+	}
+
+	tr := jsonutil.NewTransformer(conf)
+	out, err := tr.TransformBytes(context.Background(), []byte(doc))
+	assert.NoError(t, err)
+	assert.JSONEq(t, expect, string(out))
+
+	var buf bytes.Buffer
+	err = tr.TransformStream(context.Background(), strings.NewReader(doc), &buf)
+	assert.NoError(t, err)
+	assert.JSONEq(t, expect, buf.String())
+
+	// copy-on-write keeps the input as is
+	var data interface{}
+	assert.NoError(t, json.Unmarshal([]byte(doc), &data))
+	nested := map[string]interface{}{"doc": data}
+	got, err := tr.Transform(context.Background(), nested)
+	assert.NoError(t, err)
+
+	b, _ := json.Marshal(got)
+	assert.JSONEq(t, `{"doc":`+expect+`}`, string(b))
+
+	b, _ = json.Marshal(nested)
+	assert.JSONEq(t, `{"doc":`+doc+`}`, string(b))
+
+	conf.InPlace = true
+	got, err = jsonutil.NewTransformer(conf).Transform(context.Background(), nested)
+	assert.NoError(t, err)
+
+	b, _ = json.Marshal(got)
+	assert.JSONEq(t, `{"doc":`+expect+`}`, string(b))
+}
@@ -0,0 +1,75 @@
+package jsonutil
+
+import (
+	"sync"
+)
+
+// keyRegistry is the Config.Keys which can be changed while Transform is running.
+type keyRegistry struct {
+	mu   sync.RWMutex
+	keys map[string]StringTransformer
+}
+
+func newKeyRegistry(keys map[string]StringTransformer) *keyRegistry {
+	r := &keyRegistry{keys: make(map[string]StringTransformer, len(keys))}
+	for key, transformer := range keys {
+		if transformer != nil {
+			r.keys[key] = transformer
+		}
+	}
+
+	return r
+}
+
+func (r *keyRegistry) get(key string) (StringTransformer, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	r.mu.RLock()
+	transformer, ok := r.keys[key]
+	r.mu.RUnlock()
+	return transformer, ok
+}
+
+// AddKey set the transformer of key, replacing the existing one. Nil transformer remove the key.
+// It is safe to call while Transform is running, the document being transformed may see either the old or the new rule.
+func (m *Transformer) AddKey(key string, transformer StringTransformer) {
+	if transformer == nil {
+		m.RemoveKey(key)
+		return
+	}
+
+	m.keys.mu.Lock()
+	m.keys.keys[key] = transformer
+	m.keys.mu.Unlock()
+}
+
+// RemoveKey remove the transformer of key, its value is passed to Config.StringTransformer again.
+func (m *Transformer) RemoveKey(key string) {
+	m.keys.mu.Lock()
+	delete(m.keys.keys, key)
+	m.keys.mu.Unlock()
+}
+
+// ReplaceKeys replace all keys at once, i.e: after reloading the rules from config service.
+func (m *Transformer) ReplaceKeys(keys map[string]StringTransformer) {
+	replaced := newKeyRegistry(keys).keys
+
+	m.keys.mu.Lock()
+	m.keys.keys = replaced
+	m.keys.mu.Unlock()
+}
+
+// Keys return a copy of the current keys.
+func (m *Transformer) Keys() map[string]StringTransformer {
+	m.keys.mu.RLock()
+	defer m.keys.mu.RUnlock()
+
+	keys := make(map[string]StringTransformer, len(m.keys.keys))
+	for key, transformer := range m.keys.keys {
+		keys[key] = transformer
+	}
+
+	return keys
+}
@@ -0,0 +1,76 @@
+package jsonutil_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestTransformer_AddKey(t *testing.T) {
+	constant := func(v string) jsonutil.StringTransformer {
+		return func(ctx context.Context, info jsonutil.KVInfo) string {
+			return v
+		}
+	}
+
+	keys := map[string]jsonutil.StringTransformer{"password": constant("***")}
+	tr := jsonutil.NewTransformer(jsonutil.Config{Keys: keys})
+
+	// the config map is copied
+	keys["name"] = constant("changed")
+
+	doc := []byte(`{"password":"a","token":"b","name":"c"}`)
+	out, err := tr.TransformBytes(context.Background(), doc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"password":"***","token":"b","name":"c"}`, string(out))
+
+	tr.AddKey("token", constant("xxx"))
+	out, err = tr.TransformBytes(context.Background(), doc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"password":"***","token":"xxx","name":"c"}`, string(out))
+
+	tr.RemoveKey("password")
+	out, err = tr.TransformBytes(context.Background(), doc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"password":"a","token":"xxx","name":"c"}`, string(out))
+
+	tr.ReplaceKeys(map[string]jsonutil.StringTransformer{"name": constant("n"), "nil": nil})
+	out, err = tr.TransformBytes(context.Background(), doc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"password":"a","token":"b","name":"n"}`, string(out))
+	assert.Len(t, tr.Keys(), 1)
+
+	tr.AddKey("name", nil)
+	assert.Len(t, tr.Keys(), 0)
+}
+
+func TestTransformer_AddKeyConcurrent(t *testing.T) {
+	mask := func(ctx context.Context, info jsonutil.KVInfo) string { return "***" }
+	tr := jsonutil.NewTransformer(jsonutil.Config{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				tr.AddKey("password", mask)
+				tr.ReplaceKeys(map[string]jsonutil.StringTransformer{"token": mask})
+				tr.RemoveKey("token")
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				_, err := tr.TransformBytes(context.Background(), []byte(`{"password":"a","token":"b"}`))
+				assert.NoError(t, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,217 @@
+package jsonutil
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+)
+
+// LargeFileFormat is the input format of ProcessLargeFile.
+type LargeFileFormat int
+
+const (
+	// FormatAuto detect the format from the first non-whitespace byte: '[' is FormatArray, otherwise FormatNDJSON.
+	FormatAuto LargeFileFormat = iota
+	// FormatArray is a single top-level array, every element is processed separately.
+	FormatArray
+	// FormatNDJSON is newline delimited (or concatenated) documents, every document is processed separately.
+	FormatNDJSON
+)
+
+// LargeFileOptions is the options of ProcessLargeFile.
+type LargeFileOptions struct {
+	Format LargeFileFormat
+
+	// MaxElementSize is the maximum size in bytes of one element (or NDJSON document),
+	// bigger element fails with ErrDocumentTooLarge. Zero means unlimited.
+	MaxElementSize int
+
+	// OnProgress is called every ProgressInterval bytes (DefaultProgressInterval when zero) of consumed input,
+	// and once more when the whole input is processed successfully.
+	OnProgress       ProgressFunc
+	ProgressInterval int64
+
+	// TotalBytes is passed to OnProgress as the total size.
+	// When zero, it is taken from r when r is *os.File or has Len method (i.e: bytes.Reader), otherwise -1.
+	TotalBytes int64
+}
+
+// ProcessLargeFile process JSON from r element by element using p, and write the result into w.
+// Only one element is kept in memory at once, so the memory usage is bounded by the largest element
+// instead of the file size.
+// The output of FormatArray is a compact top-level array, and the output of FormatNDJSON is one document per line.
+// Processing error is returned as *ItemError with the element index, the output written so far is kept.
+func ProcessLargeFile(ctx context.Context, r io.Reader, w io.Writer, p Processor, opts LargeFileOptions) error {
+	d := NewStreamDecoder(r)
+	d.maxSize = opts.MaxElementSize
+
+	total := opts.TotalBytes
+	if total == 0 {
+		total = readerSize(r)
+	}
+	d.setProgress(opts.OnProgress, total, opts.ProgressInterval)
+
+	format := opts.Format
+	if format == FormatAuto {
+		c, err := d.peek()
+		if err == io.EOF {
+			d.progress.finish(d.InputOffset())
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		format = FormatNDJSON
+		if c == '[' {
+			format = FormatArray
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	var err error
+	switch format {
+	case FormatArray:
+		err = processLargeArray(ctx, d, bw, p)
+	case FormatNDJSON:
+		err = processLargeNDJSON(ctx, d, bw, p)
+	default:
+		err = fmt.Errorf("jsonutil: unknown large file format %d", format)
+	}
+
+	if flushErr := bw.Flush(); err == nil {
+		err = flushErr
+	}
+
+	if err == nil {
+		d.progress.finish(d.InputOffset())
+	}
+
+	return err
+}
+
+func processLargeNDJSON(ctx context.Context, d *StreamDecoder, w *bufio.Writer, p Processor) error {
+	for idx := 0; ; idx++ {
+		doc, err := d.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err = processLargeElement(ctx, w, p, idx, doc); err != nil {
+			return err
+		}
+
+		if err = w.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+}
+
+func processLargeArray(ctx context.Context, d *StreamDecoder, w *bufio.Writer, p Processor) error {
+	if err := expectByte(d, '['); err != nil {
+		return err
+	}
+
+	if err := w.WriteByte('['); err != nil {
+		return err
+	}
+
+	if c, err := d.peek(); err != nil {
+		return largeEOF(d, err)
+	} else if c == ']' {
+		d.pos++
+		return finishLargeArray(d, w)
+	}
+
+	for idx := 0; ; idx++ {
+		elem, err := d.Next()
+		if err != nil {
+			return largeEOF(d, err)
+		}
+
+		if idx > 0 {
+			if err = w.WriteByte(','); err != nil {
+				return err
+			}
+		}
+
+		if err = processLargeElement(ctx, w, p, idx, elem); err != nil {
+			return err
+		}
+
+		c, err := d.peek()
+		if err != nil {
+			return largeEOF(d, err)
+		}
+
+		d.pos++
+		switch c {
+		case ',':
+		case ']':
+			return finishLargeArray(d, w)
+		default:
+			return d.streamError(newSyntaxError(d.buf, d.pos-1, fmt.Sprintf("invalid character %q after array element", c)))
+		}
+	}
+}
+
+// finishLargeArray write the closing bracket and ensure there is nothing after the array.
+func finishLargeArray(d *StreamDecoder, w *bufio.Writer) error {
+	if err := w.WriteByte(']'); err != nil {
+		return err
+	}
+
+	c, err := d.peek()
+	if err == io.EOF {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return d.streamError(newSyntaxError(d.buf, d.pos, fmt.Sprintf("invalid character %q after top-level value", c)))
+}
+
+func processLargeElement(ctx context.Context, w *bufio.Writer, p Processor, idx int, elem []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	out, err := p.Process(ctx, elem)
+	if err != nil {
+		return &ItemError{Index: idx, Err: err}
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
+func expectByte(d *StreamDecoder, expected byte) error {
+	c, err := d.peek()
+	if err != nil {
+		return largeEOF(d, err)
+	}
+
+	if c != expected {
+		return d.streamError(newSyntaxError(d.buf, d.pos, fmt.Sprintf("invalid character %q looking for %q", c, expected)))
+	}
+
+	d.pos++
+	return nil
+}
+
+// largeEOF convert io.EOF in the middle of array into unexpected end SyntaxError.
+func largeEOF(d *StreamDecoder, err error) error {
+	if err != io.EOF {
+		return err
+	}
+
+	return d.streamError(syntaxErr(d.buf, len(d.buf), ""))
+}
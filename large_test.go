@@ -0,0 +1,200 @@
+package jsonutil_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestProcessLargeFile(t *testing.T) {
+	p := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{MaskKeys: []string{"password"}})
+
+	tests := []struct {
+		Name   string
+		Format jsonutil.LargeFileFormat
+		Input  string
+		Output string
+	}{
+		{
+			Name:   "array",
+			Input:  ` [ {"password": "a"}, {"user": "b", "password": "c"} , 1 ] `,
+			Output: `[{"password": "***"},{"user": "b", "password": "***"},1]`,
+		},
+		{
+			Name:   "empty array",
+			Input:  `[ ]`,
+			Output: `[]`,
+		},
+		{
+			Name:   "ndjson",
+			Input:  "{\"password\": \"a\"}\n{\"password\": \"b\"}\n",
+			Output: "{\"password\": \"***\"}\n{\"password\": \"***\"}\n",
+		},
+		{
+			Name:   "array as ndjson",
+			Format: jsonutil.FormatNDJSON,
+			Input:  "[{\"password\": \"a\"}]\n[1]",
+			Output: "[{\"password\": \"***\"}]\n[1]\n",
+		},
+		{
+			Name:   "empty input",
+			Input:  "  ",
+			Output: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			readers := map[string]io.Reader{
+				"full reader":     strings.NewReader(tc.Input),
+				"one byte reader": iotest.OneByteReader(strings.NewReader(tc.Input)),
+			}
+
+			for name, r := range readers {
+				var w bytes.Buffer
+				err := jsonutil.ProcessLargeFile(context.Background(), r, &w, p, jsonutil.LargeFileOptions{Format: tc.Format})
+				assert.NoError(t, err, name)
+				assert.Equal(t, tc.Output, w.String(), name)
+			}
+		})
+	}
+}
+
+func TestProcessLargeFile_BoundedMemory(t *testing.T) {
+	elem := `{"password": "` + strings.Repeat("x", 1000) + `"}`
+	input := "[" + strings.Repeat(elem+",", 999) + elem + "]"
+
+	var w bytes.Buffer
+	p := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{MaskKeys: []string{"password"}})
+	err := jsonutil.ProcessLargeFile(context.Background(), strings.NewReader(input), &w, p, jsonutil.LargeFileOptions{MaxElementSize: 16 * 1024})
+	assert.NoError(t, err)
+	assert.Equal(t, "["+strings.Repeat(`{"password": "***"},`, 999)+`{"password": "***"}]`, w.String())
+
+	// single element is bigger than the limit
+	w.Reset()
+	input = `[{"a": "` + strings.Repeat("x", 64*1024) + `"}]`
+	err = jsonutil.ProcessLargeFile(context.Background(), strings.NewReader(input), &w, p, jsonutil.LargeFileOptions{MaxElementSize: 16 * 1024})
+	assert.ErrorIs(t, err, jsonutil.ErrDocumentTooLarge)
+}
+
+func TestProcessLargeFile_MaxElementSize(t *testing.T) {
+	p := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{})
+	elem := func(size int) string {
+		return `{"a":"` + strings.Repeat("x", size-8) + `"}`
+	}
+
+	for _, format := range []jsonutil.LargeFileFormat{jsonutil.FormatArray, jsonutil.FormatNDJSON} {
+		build := func(elems ...string) string {
+			if format == jsonutil.FormatArray {
+				return "[" + strings.Join(elems, ",") + "]"
+			}
+			return strings.Join(elems, "\n")
+		}
+
+		opts := jsonutil.LargeFileOptions{Format: format, MaxElementSize: 100}
+		err := jsonutil.ProcessLargeFile(context.Background(), strings.NewReader(build(elem(10), elem(100), elem(10))), ioutil.Discard, p, opts)
+		assert.NoError(t, err, format)
+
+		err = jsonutil.ProcessLargeFile(context.Background(), strings.NewReader(build(elem(10), elem(101), elem(10))), ioutil.Discard, p, opts)
+		assert.ErrorIs(t, err, jsonutil.ErrDocumentTooLarge, format)
+
+		err = jsonutil.ProcessLargeFile(context.Background(), strings.NewReader(build(elem(3000))), ioutil.Discard, p, opts)
+		assert.ErrorIs(t, err, jsonutil.ErrDocumentTooLarge, format)
+
+		err = jsonutil.ProcessLargeFile(context.Background(), iotest.OneByteReader(strings.NewReader(build(elem(101)))), ioutil.Discard, p, opts)
+		assert.ErrorIs(t, err, jsonutil.ErrDocumentTooLarge, format)
+	}
+}
+
+func TestProcessLargeFile_ErrorOffset(t *testing.T) {
+	p := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{})
+	input := "[\n" + strings.Repeat(`{"a":"`+strings.Repeat("x", 100)+`"},`+"\n", 100) + `1 x]`
+
+	err := jsonutil.ProcessLargeFile(context.Background(), iotest.HalfReader(strings.NewReader(input)), ioutil.Discard, p, jsonutil.LargeFileOptions{})
+	var syntaxErr *jsonutil.SyntaxError
+	if assert.True(t, errors.As(err, &syntaxErr)) {
+		assert.Equal(t, len(input)-2, syntaxErr.Offset)
+		assert.Equal(t, 102, syntaxErr.Line)
+		assert.Equal(t, 3, syntaxErr.Column)
+	}
+}
+
+func TestProcessLargeFile_Error(t *testing.T) {
+	p := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{})
+	process := func(input string, format jsonutil.LargeFileFormat, p jsonutil.Processor) (string, error) {
+		var w bytes.Buffer
+		err := jsonutil.ProcessLargeFile(context.Background(), strings.NewReader(input), &w, p, jsonutil.LargeFileOptions{Format: format})
+		return w.String(), err
+	}
+
+	_, err := process(`[1, 2`, jsonutil.FormatAuto, p)
+	assert.ErrorIs(t, err, jsonutil.ErrUnexpectedEnd)
+
+	_, err = process(`[1 2]`, jsonutil.FormatAuto, p)
+	assert.Error(t, err)
+
+	_, err = process(`[1] [2]`, jsonutil.FormatArray, p)
+	assert.Error(t, err)
+
+	_, err = process(`{"a": 1}`, jsonutil.FormatArray, p)
+	assert.Error(t, err)
+
+	failErr := errors.New("failed")
+	failing := jsonutil.ProcessorFunc(func(ctx context.Context, doc []byte) ([]byte, error) {
+		if string(doc) == "2" {
+			return nil, failErr
+		}
+		return doc, nil
+	})
+
+	out, err := process(`[1, 2, 3]`, jsonutil.FormatAuto, failing)
+	assert.Equal(t, `[1,`, out)
+	assert.ErrorIs(t, err, failErr)
+
+	var itemErr *jsonutil.ItemError
+	assert.True(t, errors.As(err, &itemErr))
+	assert.Equal(t, 1, itemErr.Index)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = jsonutil.ProcessLargeFile(ctx, strings.NewReader(`[1]`), &bytes.Buffer{}, p, jsonutil.LargeFileOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestProcessLargeFile_OnProgress(t *testing.T) {
+	input := "[" + strings.Repeat(`{"a":"xxxxxxxxxx"},`, 99) + `{"a":"xxxxxxxxxx"}]`
+
+	var processed []int64
+	opts := jsonutil.LargeFileOptions{
+		ProgressInterval: 500,
+		OnProgress: func(n, total int64) {
+			assert.Equal(t, int64(len(input)), total)
+			processed = append(processed, n)
+		},
+	}
+
+	err := jsonutil.ProcessLargeFile(context.Background(), strings.NewReader(input), &bytes.Buffer{}, jsonutil.NewSanitizer(jsonutil.SanitizerConfig{}), opts)
+	assert.NoError(t, err)
+	assert.Len(t, processed, len(input)/500+1)
+	assert.Equal(t, int64(len(input)), processed[len(processed)-1])
+
+	// explicit total
+	processed = processed[:0]
+	opts.TotalBytes = 12345
+	opts.OnProgress = func(n, total int64) {
+		assert.Equal(t, int64(12345), total)
+		processed = append(processed, n)
+	}
+
+	err = jsonutil.ProcessLargeFile(context.Background(), iotest.OneByteReader(strings.NewReader(input)), &bytes.Buffer{}, jsonutil.NewSanitizer(jsonutil.SanitizerConfig{}), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(input)), processed[len(processed)-1])
+}
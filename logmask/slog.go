@@ -0,0 +1,126 @@
+//go:build go1.21
+// +build go1.21
+
+package logmask
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+type slogHandler struct {
+	next        slog.Handler
+	transformer *jsonutil.Transformer
+	groups      []string
+}
+
+// NewSlogHandler wrap next, so the attributes (including the one added using WithAttrs) is transformed before handled by next.
+// Attribute inside WithGroup is nested under the group name, i.e: the path of password in logger.WithGroup("req") is ["req", "password"].
+func NewSlogHandler(next slog.Handler, transformer *jsonutil.Transformer) slog.Handler {
+	return &slogHandler{next: next, transformer: transformer}
+}
+
+func (h *slogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *slogHandler) Handle(ctx context.Context, record slog.Record) error {
+	attrs := make([]slog.Attr, 0, record.NumAttrs())
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+
+	masked := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	masked.AddAttrs(h.mask(ctx, attrs)...)
+	return h.next.Handle(ctx, masked)
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &slogHandler{
+		next:        h.next.WithAttrs(h.mask(context.Background(), attrs)),
+		transformer: h.transformer,
+		groups:      h.groups,
+	}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	groups := make([]string, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+
+	return &slogHandler{
+		next:        h.next.WithGroup(name),
+		transformer: h.transformer,
+		groups:      append(groups, name),
+	}
+}
+
+// mask return the transformed attributes, keeping the order and the unchanged attribute as is.
+func (h *slogHandler) mask(ctx context.Context, attrs []slog.Attr) []slog.Attr {
+	values := make(map[string]interface{}, len(attrs))
+	for _, attr := range attrs {
+		values[attr.Key] = attrValue(attr.Value)
+	}
+
+	// nest the attributes under the current groups, so the path is the same as the JSON output
+	var data interface{} = values
+	for i := len(h.groups) - 1; i >= 0; i-- {
+		data = map[string]interface{}{h.groups[i]: data}
+	}
+
+	transformed, err := h.transformer.Transform(ctx, data)
+	if err != nil {
+		return []slog.Attr{slog.String("!ERROR", err.Error())}
+	}
+
+	for _, group := range h.groups {
+		transformed = transformed.(map[string]interface{})[group]
+	}
+
+	out := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		out = append(out, rebuildAttr(attr, transformed.(map[string]interface{})[attr.Key]))
+	}
+
+	return out
+}
+
+// attrValue return the value as decoded JSON, group become map[string]interface{}.
+func attrValue(v slog.Value) interface{} {
+	v = v.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		m := make(map[string]interface{})
+		for _, attr := range v.Group() {
+			m[attr.Key] = attrValue(attr.Value)
+		}
+		return m
+	case slog.KindString:
+		return v.String()
+	}
+
+	return v.Any()
+}
+
+// rebuildAttr return attr with the transformed value.
+func rebuildAttr(attr slog.Attr, transformed interface{}) slog.Attr {
+	v := attr.Value.Resolve()
+	if m, ok := transformed.(map[string]interface{}); ok && v.Kind() == slog.KindGroup {
+		children := v.Group()
+		rebuilt := make([]slog.Attr, 0, len(children))
+		for _, child := range children {
+			rebuilt = append(rebuilt, rebuildAttr(child, m[child.Key]))
+		}
+
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(rebuilt...)}
+	}
+
+	if reflect.DeepEqual(attrValue(v), transformed) {
+		return slog.Attr{Key: attr.Key, Value: v}
+	}
+
+	return slog.Any(attr.Key, transformed)
+}
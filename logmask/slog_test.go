@@ -0,0 +1,44 @@
+//go:build go1.21
+// +build go1.21
+
+package logmask_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"github.com/yusufsyaifudin/jsonutil/logmask"
+)
+
+func TestNewSlogHandler(t *testing.T) {
+	mask := jsonutil.NewTransformer(jsonutil.Config{
+		Keys: map[string]jsonutil.StringTransformer{
+			"password": func(ctx context.Context, info jsonutil.KVInfo) string { return "xxx" },
+		},
+		Paths: map[string]jsonutil.StringTransformer{
+			"$.req.token": func(ctx context.Context, info jsonutil.KVInfo) string { return "yyy" },
+		},
+	})
+
+	buf := &bytes.Buffer{}
+	handler := slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := slog.New(logmask.NewSlogHandler(handler, mask))
+
+	logger.With("password", "a").Info("login", "user", "john", "n", 1, slog.Group("auth", "password", "b", "token", "c"))
+	assert.Equal(t, `{"level":"INFO","msg":"login","password":"xxx","user":"john","n":1,"auth":{"password":"xxx","token":"c"}}`+"\n", buf.String())
+
+	buf.Reset()
+	logger.WithGroup("req").Info("call", "token", "t", "password", "p")
+	assert.Equal(t, `{"level":"INFO","msg":"call","req":{"token":"yyy","password":"xxx"}}`+"\n", buf.String())
+}
@@ -0,0 +1,81 @@
+// Package logmask run jsonutil.Transformer on the structured fields of every log entry,
+// so the sensitive values never reach the log sink.
+// Every field is transformed as a top level object member, i.e: zap.String("password", "x") has the path ["password"],
+// and the fields of an object (or slog group) are nested under its key.
+package logmask
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/yusufsyaifudin/jsonutil"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type zapCore struct {
+	zapcore.Core
+	transformer *jsonutil.Transformer
+}
+
+// NewZapCore wrap core, so the fields (including the one added using With) is transformed before written into core.
+// Use it with zap.WrapCore, i.e: logger.WithOptions(zap.WrapCore(func(c zapcore.Core) zapcore.Core { return logmask.NewZapCore(c, t) })).
+func NewZapCore(core zapcore.Core, transformer *jsonutil.Transformer) zapcore.Core {
+	return &zapCore{Core: core, transformer: transformer}
+}
+
+func (c *zapCore) With(fields []zapcore.Field) zapcore.Core {
+	return &zapCore{Core: c.Core.With(c.mask(fields)), transformer: c.transformer}
+}
+
+func (c *zapCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+func (c *zapCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, c.mask(fields))
+}
+
+// mask return the transformed fields, the unchanged field is kept as is so its type is preserved.
+func (c *zapCore) mask(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, 0, len(fields))
+	for _, field := range fields {
+		if field.Type == zapcore.NamespaceType || field.Type == zapcore.SkipType {
+			out = append(out, field)
+			continue
+		}
+
+		// one field may produce more than one key, i.e: zap.Error add "error" and "errorVerbose"
+		enc := zapcore.NewMapObjectEncoder()
+		field.AddTo(enc)
+
+		transformed, err := c.transformer.Transform(context.Background(), enc.Fields)
+		if err != nil {
+			out = append(out, zap.String(field.Key, "!ERROR: "+err.Error()))
+			continue
+		}
+
+		values := transformed.(map[string]interface{})
+		if reflect.DeepEqual(enc.Fields, values) {
+			out = append(out, field)
+			continue
+		}
+
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			out = append(out, zap.Any(key, values[key]))
+		}
+	}
+
+	return out
+}
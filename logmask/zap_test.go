@@ -0,0 +1,43 @@
+package logmask_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"github.com/yusufsyaifudin/jsonutil/logmask"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewZapCore(t *testing.T) {
+	mask := jsonutil.NewTransformer(jsonutil.Config{
+		Keys: map[string]jsonutil.StringTransformer{
+			"password": func(ctx context.Context, info jsonutil.KVInfo) string { return "xxx" },
+			"error":    func(ctx context.Context, info jsonutil.KVInfo) string { return "hidden" },
+		},
+	})
+
+	buf := &bytes.Buffer{}
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}),
+		zapcore.AddSync(buf),
+		zap.InfoLevel,
+	)
+	logger := zap.New(logmask.NewZapCore(core, mask))
+
+	logger.With(zap.String("password", "a")).Info("login",
+		zap.String("user", "john"),
+		zap.Int("n", 1),
+		zap.Any("body", map[string]interface{}{"password": "b"}),
+		zap.Error(errors.New("secret")),
+	)
+	assert.Equal(t, `{"msg":"login","password":"xxx","user":"john","n":1,"body":{"password":"xxx"},"error":"hidden"}`+"\n", buf.String())
+
+	buf.Reset()
+	logger.Debug("filtered", zap.String("password", "a"))
+	assert.Empty(t, buf.String())
+}
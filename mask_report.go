@@ -0,0 +1,69 @@
+package jsonutil
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"runtime"
+	"sort"
+	"unicode/utf8"
+)
+
+// MaskReport record one value changed by Transformer, so the redaction can be audited without logging the value itself.
+type MaskReport struct {
+	Path        string `json:"path"` // Path is in JSON Pointer form, i.e: /items/0/token
+	Key         string `json:"key"`
+	Length      int    `json:"length"`      // Length is the number of characters of the original string, or of the JSON encoding for other value.
+	Transformer string `json:"transformer"` // Transformer is the function name (i.e: github.com/yusufsyaifudin/jsonutil/maskfuncs.MaskEmail) or detector:<name>.
+}
+
+// TransformBytesWithReport is like TransformBytes but also return the report of every changed value, sorted by the path.
+func (m *Transformer) TransformBytesWithReport(ctx context.Context, b []byte) ([]byte, []MaskReport, error) {
+	report := make([]MaskReport, 0)
+	reporting := m.withHook(func(info KVInfo, transformer interface{}, before, after interface{}) {
+		if sameValue(before, after) {
+			return
+		}
+
+		path, key := m.maskedPath(ctx, info)
+		report = append(report, MaskReport{
+			Path:        JoinPointer(path),
+			Key:         key,
+			Length:      valueLength(before),
+			Transformer: funcName(transformer),
+		})
+	})
+
+	out, err := reporting.TransformBytes(ctx, b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		return pointerLess(report[i].Path, report[j].Path)
+	})
+
+	return out, report, nil
+}
+
+func valueLength(v interface{}) int {
+	if str, ok := v.(string); ok {
+		return utf8.RuneCountInString(str)
+	}
+
+	b, _ := json.Marshal(v)
+	return len(b)
+}
+
+func funcName(fn interface{}) string {
+	if detector, ok := fn.(Detector); ok {
+		return "detector:" + detector.Name()
+	}
+
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return ""
+	}
+
+	return f.Name()
+}
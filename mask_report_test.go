@@ -0,0 +1,75 @@
+package jsonutil_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"github.com/yusufsyaifudin/jsonutil/maskfuncs"
+)
+
+func TestTransformer_TransformBytesWithReport(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		Keys: map[string]jsonutil.StringTransformer{
+			"email": maskfuncs.MaskEmail,
+			"name":  jsonutil.DefaultStringTransformer,
+		},
+		Paths: map[string]jsonutil.StringTransformer{
+			"$.cards[*]": maskfuncs.MaskAll,
+		},
+		ValueTransformer: func(ctx context.Context, info jsonutil.KVInfo, value interface{}) interface{} {
+			if info.Key == "salary" {
+				return 0
+			}
+			return value
+		},
+	})
+
+	out, report, err := tr.TransformBytesWithReport(context.Background(), []byte(`{"name":"john","email":"john@example.com","cards":["4111","5500"],"salary":12345}`))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(out), "john@example.com")
+
+	if !assert.Len(t, report, 4) {
+		return
+	}
+
+	assert.Equal(t, "/cards/0", report[0].Path)
+	assert.Equal(t, "/cards/1", report[1].Path)
+	assert.Equal(t, "/email", report[2].Path)
+	assert.Equal(t, "email", report[2].Key)
+	assert.Equal(t, 16, report[2].Length)
+	assert.Equal(t, "github.com/yusufsyaifudin/jsonutil/maskfuncs.MaskEmail", report[2].Transformer)
+	assert.True(t, strings.HasPrefix(report[3].Transformer, "github.com/yusufsyaifudin/jsonutil_test."), report[3].Transformer)
+	assert.Equal(t, "/salary", report[3].Path)
+	assert.Equal(t, 5, report[3].Length)
+
+	_, _, err = tr.TransformBytesWithReport(context.Background(), []byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestTransformer_TransformBytesWithReport_MaskKeys(t *testing.T) {
+	email, err := jsonutil.GlobKey("*@*", func(ctx context.Context, info jsonutil.KVInfo) string { return "[email]" })
+	assert.NoError(t, err)
+
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		MaskKeys: []jsonutil.KeyPattern{email},
+		Keys: map[string]jsonutil.StringTransformer{
+			"token":            maskfuncs.MaskAll,
+			"john@example.com": maskfuncs.MaskAll,
+		},
+	})
+
+	out, report, err := tr.TransformBytesWithReport(context.Background(), []byte(`{"users":{"john@example.com":{"token":"abc"}},"john@example.com":"x"}`))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(out), "john@example.com")
+	assert.NotContains(t, fmt.Sprintf("%+v", report), "john")
+
+	paths := make([]string, 0)
+	for _, r := range report {
+		paths = append(paths, r.Path+" "+r.Key)
+	}
+	assert.ElementsMatch(t, []string{"/[email] [email]", "/[email] [email]", "/users/[email] [email]", "/users/[email]/token token"}, paths)
+}
@@ -0,0 +1,154 @@
+// Package maskfuncs is a set of common masking functions, every function is a jsonutil.StringTransformer,
+// so it can be used as Config.StringTransformer or as the value of Config.Keys, Config.Paths and KeyPattern.
+package maskfuncs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+// MaskChar is the character used to replace the masked characters.
+const MaskChar = '*'
+
+var _ jsonutil.StringTransformer = MaskAll
+var _ jsonutil.StringTransformer = MaskEmail
+var _ jsonutil.StringTransformer = MaskCreditCard
+var _ jsonutil.StringTransformer = MaskPhone
+
+// MaskAll replace every character with MaskChar, the length is kept.
+func MaskAll(ctx context.Context, info jsonutil.KVInfo) string {
+	return strings.Repeat(string(MaskChar), len([]rune(info.Value)))
+}
+
+// MaskFirstN return StringTransformer which replace the first n characters with MaskChar.
+func MaskFirstN(n int) jsonutil.StringTransformer {
+	return func(ctx context.Context, info jsonutil.KVInfo) string {
+		runes := []rune(info.Value)
+		for i := 0; i < n && i < len(runes); i++ {
+			runes[i] = MaskChar
+		}
+
+		return string(runes)
+	}
+}
+
+// MaskLastN return StringTransformer which replace the last n characters with MaskChar.
+func MaskLastN(n int) jsonutil.StringTransformer {
+	return func(ctx context.Context, info jsonutil.KVInfo) string {
+		runes := []rune(info.Value)
+		for i := len(runes) - 1; i >= 0 && i >= len(runes)-n; i-- {
+			runes[i] = MaskChar
+		}
+
+		return string(runes)
+	}
+}
+
+// MaskEmail keep the first character of the local part and the whole domain, i.e: john.doe@example.com become j*******@example.com.
+// Value which is not an email is masked entirely.
+func MaskEmail(ctx context.Context, info jsonutil.KVInfo) string {
+	at := strings.LastIndexByte(info.Value, '@')
+	if at <= 0 || at == len(info.Value)-1 {
+		return MaskAll(ctx, info)
+	}
+
+	local := []rune(info.Value[:at])
+	for i := 1; i < len(local); i++ {
+		local[i] = MaskChar
+	}
+
+	return string(local) + info.Value[at:]
+}
+
+// MaskCreditCard keep the last 4 digits and the separators, i.e: 4111-1111-1111-1234 become ****-****-****-1234.
+func MaskCreditCard(ctx context.Context, info jsonutil.KVInfo) string {
+	return maskRunes(info.Value, 4, MaskChar, isDigit)
+}
+
+// MaskPhone keep the leading plus sign, separators and the last 4 digits, i.e: +62 812-3456-7890 become +** ***-****-7890.
+func MaskPhone(ctx context.Context, info jsonutil.KVInfo) string {
+	return maskRunes(info.Value, 4, MaskChar, isDigit)
+}
+
+// PreserveFormat return StringTransformer which replace every letter and digit with maskChar except the last 4,
+// while the punctuation, spaces and length is kept, i.e: 4111-1111-1111-1234 become xxxx-xxxx-xxxx-1234 using 'x'.
+// Useful when the downstream still validate the format, such as IBAN, license plate or national ID.
+func PreserveFormat(maskChar rune) jsonutil.StringTransformer {
+	return func(ctx context.Context, info jsonutil.KVInfo) string {
+		return maskRunes(info.Value, 4, maskChar, isAlphanumeric)
+	}
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isAlphanumeric(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// maskRunes replace every rune matched by match except the last keep ones with maskChar.
+// When the value has no more than keep matched runes, every one of them is masked.
+func maskRunes(str string, keep int, maskChar rune, match func(r rune) bool) string {
+	matched := 0
+	for _, r := range str {
+		if match(r) {
+			matched++
+		}
+	}
+
+	if matched <= keep {
+		keep = 0
+	}
+
+	runes := []rune(str)
+	seen := 0
+	for i, r := range runes {
+		if !match(r) {
+			continue
+		}
+
+		if seen < matched-keep {
+			runes[i] = maskChar
+		}
+		seen++
+	}
+
+	return string(runes)
+}
+
+// HashSHA256WithSalt return StringTransformer which replace the value with "sha256:<hex>" of salt followed by the value,
+// the same value always produce the same hash, so it can still be joined or counted without being revealed.
+func HashSHA256WithSalt(salt string) jsonutil.StringTransformer {
+	return func(ctx context.Context, info jsonutil.KVInfo) string {
+		sum := sha256.Sum256([]byte(salt + info.Value))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	}
+}
+
+// Tokenize return StringTransformer which replace the value with the hex of HMAC-SHA256 (truncated to 128 bits) using secret.
+// Unlike HashSHA256WithSalt, the token cannot be brute-forced without the secret, while equal values still produce
+// the same token, so the masked datasets remain joinable across tables or events.
+func Tokenize(secret []byte) jsonutil.StringTransformer {
+	return TokenizeWithPrefix(secret, "")
+}
+
+// TokenizeWithPrefix is like Tokenize, but the token is prefixed with the type hint followed by colon, i.e: email:5d41402abc4b2a76.
+// Empty prefix means no prefix.
+func TokenizeWithPrefix(secret []byte, prefix string) jsonutil.StringTransformer {
+	if prefix != "" {
+		prefix += ":"
+	}
+
+	return func(ctx context.Context, info jsonutil.KVInfo) string {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(info.Value))
+		return prefix + hex.EncodeToString(mac.Sum(nil)[:16])
+	}
+}
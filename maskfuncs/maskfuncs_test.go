@@ -0,0 +1,102 @@
+package maskfuncs_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"github.com/yusufsyaifudin/jsonutil/maskfuncs"
+)
+
+func TestMaskFuncs(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Transformer jsonutil.StringTransformer
+		In          string
+		Expect      string
+	}{
+		{Name: "all", Transformer: maskfuncs.MaskAll, In: "sécret", Expect: "******"},
+		{Name: "first n", Transformer: maskfuncs.MaskFirstN(3), In: "abcdef", Expect: "***def"},
+		{Name: "first n longer than value", Transformer: maskfuncs.MaskFirstN(10), In: "abc", Expect: "***"},
+		{Name: "last n", Transformer: maskfuncs.MaskLastN(2), In: "abcdéf", Expect: "abcd**"},
+		{Name: "last n longer than value", Transformer: maskfuncs.MaskLastN(10), In: "abc", Expect: "***"},
+		{Name: "email", Transformer: maskfuncs.MaskEmail, In: "john.doe@example.com", Expect: "j*******@example.com"},
+		{Name: "email single char", Transformer: maskfuncs.MaskEmail, In: "j@example.com", Expect: "j@example.com"},
+		{Name: "not email", Transformer: maskfuncs.MaskEmail, In: "john@", Expect: "*****"},
+		{Name: "credit card", Transformer: maskfuncs.MaskCreditCard, In: "4111-1111-1111-1234", Expect: "****-****-****-1234"},
+		{Name: "credit card no separator", Transformer: maskfuncs.MaskCreditCard, In: "4111111111111234", Expect: "************1234"},
+		{Name: "short number", Transformer: maskfuncs.MaskCreditCard, In: "1234", Expect: "****"},
+		{Name: "phone", Transformer: maskfuncs.MaskPhone, In: "+62 812-3456-7890", Expect: "+** ***-****-7890"},
+		{Name: "preserve format", Transformer: maskfuncs.PreserveFormat('x'), In: "4111-1111-1111-1234", Expect: "xxxx-xxxx-xxxx-1234"},
+		{Name: "preserve format letters", Transformer: maskfuncs.PreserveFormat('#'), In: "GB82 WEST 1234 5698", Expect: "#### #### #### 5698"},
+		{Name: "preserve format short", Transformer: maskfuncs.PreserveFormat('x'), In: "A-12", Expect: "x-xx"},
+		{Name: "hash", Transformer: maskfuncs.HashSHA256WithSalt("salt"), In: "value", Expect: "sha256:d430a1da30afe1a9d07b3b36042151ebaf53c4882af1609733c04930de318e33"},
+		{Name: "tokenize", Transformer: maskfuncs.Tokenize([]byte("key")), In: "john@example.com", Expect: "da94f7c6b701931416ec5044febd44fe"},
+		{Name: "tokenize with prefix", Transformer: maskfuncs.TokenizeWithPrefix([]byte("key"), "email"), In: "john@example.com", Expect: "email:da94f7c6b701931416ec5044febd44fe"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.Equal(t, tc.Expect, tc.Transformer(context.Background(), jsonutil.KVInfo{Value: tc.In}))
+		})
+	}
+}
+
+func TestMaskFuncs_Transformer(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		Keys: map[string]jsonutil.StringTransformer{
+			"email": maskfuncs.MaskEmail,
+			"card":  maskfuncs.MaskCreditCard,
+		},
+	})
+
+	out, err := tr.TransformBytes(context.Background(), []byte(`{"email":"alice@example.com","card":"4111 1111 1111 1234","name":"alice"}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"email":"a****@example.com","card":"**** **** **** 1234","name":"alice"}`, string(out))
+}
+
+func TestTokenize(t *testing.T) {
+	tokenize := maskfuncs.Tokenize([]byte("secret"))
+	token := func(v string) string {
+		return tokenize(context.Background(), jsonutil.KVInfo{Value: v})
+	}
+
+	// stable for equal input, different for different input or secret
+	assert.Equal(t, token("a"), token("a"))
+	assert.NotEqual(t, token("a"), token("b"))
+	assert.NotEqual(t, token("a"), maskfuncs.Tokenize([]byte("other"))(context.Background(), jsonutil.KVInfo{Value: "a"}))
+}
+
+func TestRegisterMaskFunc(t *testing.T) {
+	conf, err := jsonutil.LoadMaskingConfig(strings.NewReader(`{"keys": {` +
+		`"a": {"func": "mask_first_n", "args": [2]}, ` +
+		`"b": {"func": "preserve_format", "args": ["x"]}, ` +
+		`"c": {"func": "tokenize", "args": ["key", "email"]}, ` +
+		`"d": {"func": "hash_sha256_with_salt", "args": ["salt"]}, ` +
+		`"e": "mask_phone"}}`))
+	assert.NoError(t, err)
+
+	out, err := jsonutil.NewTransformer(conf).TransformBytes(context.Background(), []byte(
+		`{"a":"abcd","b":"AB-1234-56","c":"john@example.com","d":"value","e":"+62 812-3456-7890"}`,
+	))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"a":"**cd",
+		"b":"xx-xx34-56",
+		"c":"email:da94f7c6b701931416ec5044febd44fe",
+		"d":"sha256:d430a1da30afe1a9d07b3b36042151ebaf53c4882af1609733c04930de318e33",
+		"e":"+** ***-****-7890"
+	}`, string(out))
+
+	for _, config := range []string{
+		`{"keys":{"a":{"func":"preserve_format","args":["xy"]}}}`,
+		`{"keys":{"a":"tokenize"}}`,
+		`{"keys":{"a":"hash_sha256_with_salt"}}`,
+		`{"keys":{"a":"mask_last_n"}}`,
+	} {
+		_, err = jsonutil.LoadMaskingConfig(strings.NewReader(config))
+		assert.Error(t, err, config)
+	}
+}
@@ -0,0 +1,72 @@
+package maskfuncs
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+// init register the functions for jsonutil.LoadMaskingConfig, using snake_case of the function name.
+func init() {
+	jsonutil.RegisterMaskFunc("mask_all", noArgs(MaskAll))
+	jsonutil.RegisterMaskFunc("mask_email", noArgs(MaskEmail))
+	jsonutil.RegisterMaskFunc("mask_credit_card", noArgs(MaskCreditCard))
+	jsonutil.RegisterMaskFunc("mask_phone", noArgs(MaskPhone))
+	jsonutil.RegisterMaskFunc("mask_first_n", intArg(MaskFirstN))
+	jsonutil.RegisterMaskFunc("mask_last_n", intArg(MaskLastN))
+
+	jsonutil.RegisterMaskFunc("preserve_format", func(args []string) (jsonutil.StringTransformer, error) {
+		maskChar := MaskChar
+		if len(args) > 0 {
+			r, size := utf8.DecodeRuneInString(args[0])
+			if size == 0 || size != len(args[0]) {
+				return nil, fmt.Errorf("argument must be single character, got %q", args[0])
+			}
+			maskChar = r
+		}
+
+		return PreserveFormat(maskChar), nil
+	})
+
+	jsonutil.RegisterMaskFunc("hash_sha256_with_salt", func(args []string) (jsonutil.StringTransformer, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("need the salt as argument")
+		}
+
+		return HashSHA256WithSalt(args[0]), nil
+	})
+
+	jsonutil.RegisterMaskFunc("tokenize", func(args []string) (jsonutil.StringTransformer, error) {
+		switch len(args) {
+		case 1:
+			return Tokenize([]byte(args[0])), nil
+		case 2:
+			return TokenizeWithPrefix([]byte(args[0]), args[1]), nil
+		}
+
+		return nil, fmt.Errorf("need the secret and optional prefix as arguments")
+	})
+}
+
+func noArgs(transformer jsonutil.StringTransformer) jsonutil.MaskFuncFactory {
+	return func(args []string) (jsonutil.StringTransformer, error) {
+		return transformer, nil
+	}
+}
+
+func intArg(factory func(n int) jsonutil.StringTransformer) jsonutil.MaskFuncFactory {
+	return func(args []string) (jsonutil.StringTransformer, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("need one number as argument")
+		}
+
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return factory(n), nil
+	}
+}
@@ -0,0 +1,276 @@
+package jsonutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MaskFuncFactory build the StringTransformer of named mask function from its arguments, see RegisterMaskFunc.
+type MaskFuncFactory func(args []string) (StringTransformer, error)
+
+var (
+	maskFuncsMu sync.RWMutex
+	maskFuncs   = map[string]MaskFuncFactory{
+		"keep": func(args []string) (StringTransformer, error) {
+			return DefaultStringTransformer, nil
+		},
+		"placeholder": func(args []string) (StringTransformer, error) {
+			placeholder := DefaultPlaceholder
+			if len(args) > 0 {
+				placeholder = args[0]
+			}
+
+			return func(ctx context.Context, info KVInfo) string {
+				return placeholder
+			}, nil
+		},
+		"hash": func(args []string) (StringTransformer, error) {
+			return func(ctx context.Context, info KVInfo) string {
+				return hashValue(info.Value)
+			}, nil
+		},
+	}
+)
+
+// RegisterMaskFunc register the mask function by name, so it can be referred in the file read by LoadMaskingConfig.
+// The builtin is "keep", "placeholder" (optional argument is the placeholder) and "hash" (sha256),
+// importing package maskfuncs register its functions too, i.e: mask_email and mask_last_n.
+// Registering the same name again replace the previous one.
+func RegisterMaskFunc(name string, factory MaskFuncFactory) {
+	maskFuncsMu.Lock()
+	maskFuncs[name] = factory
+	maskFuncsMu.Unlock()
+}
+
+// MaskFuncNames return the registered mask function names, sorted.
+func MaskFuncNames() []string {
+	maskFuncsMu.RLock()
+	defer maskFuncsMu.RUnlock()
+
+	names := make([]string, 0, len(maskFuncs))
+	for name := range maskFuncs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// MaskSpec is the mask function name and its arguments.
+// In the file it is written as the name only (i.e: mask_email), or as object (i.e: {func: mask_last_n, args: [4]}).
+type MaskSpec struct {
+	Func string   `json:"func"`
+	Args []string `json:"args"`
+}
+
+func (s *MaskSpec) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		return json.Unmarshal(b, &s.Func)
+	}
+
+	// args may be written as number, i.e: [4]
+	var raw struct {
+		Func string        `json:"func"`
+		Args []interface{} `json:"args"`
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	s.Func = raw.Func
+	s.Args = make([]string, 0, len(raw.Args))
+	for _, arg := range raw.Args {
+		s.Args = append(s.Args, fmt.Sprint(arg))
+	}
+
+	return nil
+}
+
+// KeyPatternSpec is KeyPattern in the file, either Regex or Glob is set.
+type KeyPatternSpec struct {
+	Regex string `json:"regex"`
+	Glob  string `json:"glob"`
+	MaskSpec
+}
+
+func (s *KeyPatternSpec) UnmarshalJSON(b []byte) error {
+	// the embedded MaskSpec.UnmarshalJSON would otherwise be used for the whole object
+	var pattern struct {
+		Regex string `json:"regex"`
+		Glob  string `json:"glob"`
+	}
+
+	if err := json.Unmarshal(b, &pattern); err != nil {
+		return err
+	}
+
+	s.Regex, s.Glob = pattern.Regex, pattern.Glob
+	return s.MaskSpec.UnmarshalJSON(b)
+}
+
+// MaskingFile is the document format accepted by LoadMaskingConfig, i.e:
+//
+//	mode: blacklist
+//	default: keep
+//	keys:
+//	  password: placeholder
+//	  email: mask_email
+//	  card: {func: mask_last_n, args: [4]}
+//	key_patterns:
+//	  - regex: "(?i)_token$"
+//	    func: hash
+//	paths:
+//	  $.user.ssn: mask_all
+//	mask_keys:
+//	  - glob: "*@*"
+//	    func: hash
+type MaskingFile struct {
+	Mode             string              `json:"mode"` // Mode is blacklist (default) or whitelist.
+	Placeholder      string              `json:"placeholder"`
+	AllowKeys        []string            `json:"allow_keys"`
+	Default          *MaskSpec           `json:"default"` // Default is Config.StringTransformer.
+	Keys             map[string]MaskSpec `json:"keys"`
+	KeyPatterns      []KeyPatternSpec    `json:"key_patterns"`
+	Paths            map[string]MaskSpec `json:"paths"`
+	MaskKeys         []KeyPatternSpec    `json:"mask_keys"`
+	DecodeNestedJSON bool                `json:"decode_nested_json"`
+	MaxDepth         int                 `json:"max_depth"`
+}
+
+// LoadMaskingConfig read the YAML or JSON masking document (see MaskingFile) into Config,
+// the mask functions is resolved by the name registered using RegisterMaskFunc.
+func LoadMaskingConfig(r io.Reader) (Config, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var raw interface{}
+	if err = yaml.Unmarshal(b, &raw); err != nil {
+		return Config{}, fmt.Errorf("jsonutil: cannot parse masking config: %w", err)
+	}
+
+	// decode through JSON, so the mask spec can be written either as string or object
+	b, err = json.Marshal(stringKeys(raw))
+	if err != nil {
+		return Config{}, fmt.Errorf("jsonutil: cannot parse masking config: %w", err)
+	}
+
+	var file MaskingFile
+	if err = json.Unmarshal(b, &file); err != nil {
+		return Config{}, fmt.Errorf("jsonutil: cannot parse masking config: %w", err)
+	}
+
+	return file.Config()
+}
+
+// Config resolve the mask functions and return the Config.
+func (f *MaskingFile) Config() (Config, error) {
+	conf := Config{
+		Placeholder:      f.Placeholder,
+		AllowKeys:        f.AllowKeys,
+		DecodeNestedJSON: f.DecodeNestedJSON,
+		MaxDepth:         f.MaxDepth,
+	}
+
+	switch strings.ToLower(f.Mode) {
+	case "", "blacklist":
+		conf.Mode = Blacklist
+	case "whitelist":
+		conf.Mode = Whitelist
+	default:
+		return Config{}, fmt.Errorf("jsonutil: unknown mode %q", f.Mode)
+	}
+
+	var err error
+	if f.Default != nil {
+		if conf.StringTransformer, err = f.Default.resolve(); err != nil {
+			return Config{}, fmt.Errorf("jsonutil: default: %w", err)
+		}
+	}
+
+	conf.Keys = make(map[string]StringTransformer, len(f.Keys))
+	for key, spec := range f.Keys {
+		if conf.Keys[key], err = spec.resolve(); err != nil {
+			return Config{}, fmt.Errorf("jsonutil: key %q: %w", key, err)
+		}
+	}
+
+	conf.Paths = make(map[string]StringTransformer, len(f.Paths))
+	for path, spec := range f.Paths {
+		if _, err = ParseSelector(path); err != nil {
+			return Config{}, fmt.Errorf("jsonutil: path %q: %w", path, err)
+		}
+
+		if conf.Paths[path], err = spec.resolve(); err != nil {
+			return Config{}, fmt.Errorf("jsonutil: path %q: %w", path, err)
+		}
+	}
+
+	if conf.KeyPatterns, err = resolveKeyPatterns(f.KeyPatterns); err != nil {
+		return Config{}, fmt.Errorf("jsonutil: key_patterns: %w", err)
+	}
+
+	if conf.MaskKeys, err = resolveKeyPatterns(f.MaskKeys); err != nil {
+		return Config{}, fmt.Errorf("jsonutil: mask_keys: %w", err)
+	}
+
+	return conf, nil
+}
+
+func (s MaskSpec) resolve() (StringTransformer, error) {
+	maskFuncsMu.RLock()
+	factory, ok := maskFuncs[s.Func]
+	maskFuncsMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown mask function %q, registered: %s", s.Func, strings.Join(MaskFuncNames(), ", "))
+	}
+
+	transformer, err := factory(s.Args)
+	if err != nil {
+		return nil, fmt.Errorf("mask function %q: %w", s.Func, err)
+	}
+
+	return transformer, nil
+}
+
+func resolveKeyPatterns(specs []KeyPatternSpec) ([]KeyPattern, error) {
+	patterns := make([]KeyPattern, 0, len(specs))
+	for i, spec := range specs {
+		transformer, err := spec.resolve()
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", i, err)
+		}
+
+		var pattern KeyPattern
+		switch {
+		case spec.Regex != "" && spec.Glob == "":
+			pattern, err = RegexKey(spec.Regex, transformer)
+		case spec.Glob != "" && spec.Regex == "":
+			pattern, err = GlobKey(spec.Glob, transformer)
+		default:
+			err = fmt.Errorf("exactly one of regex or glob must be set")
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", i, err)
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
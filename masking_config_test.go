@@ -0,0 +1,81 @@
+package jsonutil_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	_ "github.com/yusufsyaifudin/jsonutil/maskfuncs"
+)
+
+func TestLoadMaskingConfig(t *testing.T) {
+	conf, err := jsonutil.LoadMaskingConfig(strings.NewReader(`
+mode: blacklist
+keys:
+  password: placeholder
+  email: mask_email
+  card:
+    func: mask_last_n
+    args: [4]
+key_patterns:
+  - regex: "_token$"
+    func: hash
+  - glob: "secret*"
+    func: placeholder
+    args: ["[hidden]"]
+paths:
+  $.user.name: mask_all
+mask_keys:
+  - glob: "*@*"
+    func: placeholder
+    args: ["redacted"]
+`))
+	assert.NoError(t, err)
+
+	out, err := jsonutil.NewTransformer(conf).TransformBytes(context.Background(), []byte(
+		`{"password":"a","email":"john@example.com","card":"4111111111111234","access_token":"t","secret_key":"s","user":{"name":"bob"},"al@example.com":1,"name":"x"}`,
+	))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"password":"***",
+		"email":"j***@example.com",
+		"card":"411111111111****",
+		"access_token":"sha256:e3b98a4da31a127d4bde6e43033f66ba274cab0eb7eb1c70ec41402bf6273dd8",
+		"secret_key":"[hidden]",
+		"user":{"name":"***"},
+		"redacted":1,
+		"name":"x"
+	}`, string(out))
+}
+
+func TestLoadMaskingConfig_JSON(t *testing.T) {
+	conf, err := jsonutil.LoadMaskingConfig(strings.NewReader(`{"mode":"whitelist","allow_keys":["id"],"placeholder":"-"}`))
+	assert.NoError(t, err)
+
+	out, err := jsonutil.NewTransformer(conf).TransformBytes(context.Background(), []byte(`{"id":"1","name":"x"}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1","name":"-"}`, string(out))
+}
+
+func TestLoadMaskingConfig_Error(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Config string
+	}{
+		{Name: "unknown func", Config: `{"keys":{"a":"nope"}}`},
+		{Name: "unknown mode", Config: `{"mode":"greylist"}`},
+		{Name: "invalid regex", Config: `{"key_patterns":[{"regex":"(","func":"hash"}]}`},
+		{Name: "no pattern", Config: `{"key_patterns":[{"func":"hash"}]}`},
+		{Name: "invalid path", Config: `{"paths":{"$.a[":"hash"}}`},
+		{Name: "invalid args", Config: `{"keys":{"a":{"func":"mask_last_n","args":["x"]}}}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			_, err := jsonutil.LoadMaskingConfig(strings.NewReader(tc.Config))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package jsonutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// ErrNotJSON is returned when the message content-type header is not a JSON media type.
+var ErrNotJSON = errors.New("jsonutil: message is not JSON")
+
+// MessageValidator is custom validation of a message, called before the message is sanitized.
+type MessageValidator func(ctx context.Context, key, value []byte, headers map[string][]byte) error
+
+type MessageConfig struct {
+	// Processors is applied in order to the message value, i.e: masking then truncation.
+	Processors []Processor
+
+	// MaxValueSize reject message value larger than this size in bytes. Zero means no limit.
+	MaxValueSize int
+
+	// ContentTypeHeader is the header name (case-insensitive) which contains the media type of the value.
+	// When the header exist and not a JSON media type, ErrNotJSON is returned. Default to "content-type".
+	ContentTypeHeader string
+
+	// Validators is called in order after the size and JSON syntax is validated.
+	Validators []MessageValidator
+}
+
+// MessageProcessor sanitize message-bus payloads (Kafka, NATS, etc.) before it is written to
+// dead letter queue or audit topic. It does not depend on any message-bus client,
+// so it can be plugged into any consumer or producer interceptor.
+type MessageProcessor struct {
+	conf     MessageConfig
+	pipeline *Pipeline
+}
+
+func NewMessageProcessor(conf MessageConfig) *MessageProcessor {
+	if conf.ContentTypeHeader == "" {
+		conf.ContentTypeHeader = "content-type"
+	}
+
+	return &MessageProcessor{conf: conf, pipeline: NewPipeline(conf.Processors...)}
+}
+
+// ProcessMessage validate and return the sanitized copy of message value.
+// Empty value (i.e: Kafka tombstone) is returned as is.
+func (p *MessageProcessor) ProcessMessage(ctx context.Context, key, value []byte, headers map[string][]byte) ([]byte, error) {
+	if len(value) == 0 {
+		return value, nil
+	}
+
+	if p.conf.MaxValueSize > 0 && len(value) > p.conf.MaxValueSize {
+		return nil, fmt.Errorf("jsonutil: message value size %d exceeds the limit %d", len(value), p.conf.MaxValueSize)
+	}
+
+	for name, val := range headers {
+		if !strings.EqualFold(name, p.conf.ContentTypeHeader) {
+			continue
+		}
+
+		if !IsJSONMediaType(string(val)) {
+			return nil, ErrNotJSON
+		}
+	}
+
+	if _, err := scanDocument(value); err != nil {
+		return nil, err
+	}
+
+	for _, validate := range p.conf.Validators {
+		if err := validate(ctx, key, value, headers); err != nil {
+			return nil, err
+		}
+	}
+
+	return p.pipeline.Process(ctx, value)
+}
+
+// IsJSONMediaType return true for application/json and any +json media type such as application/problem+json.
+func IsJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// IsFormMediaType return true for application/x-www-form-urlencoded.
+func IsFormMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/x-www-form-urlencoded"
+}
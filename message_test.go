@@ -0,0 +1,59 @@
+package jsonutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestMessageProcessor_ProcessMessage(t *testing.T) {
+	mask := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "card" {
+				return "xxx"
+			}
+
+			return info.Value
+		},
+	})
+
+	errNoID := errors.New("missing id")
+	p := jsonutil.NewMessageProcessor(jsonutil.MessageConfig{
+		Processors:   []jsonutil.Processor{mask},
+		MaxValueSize: 64,
+		Validators: []jsonutil.MessageValidator{
+			func(ctx context.Context, key, value []byte, headers map[string][]byte) error {
+				if !jsonutil.ExistsBytes(value, "id") {
+					return errNoID
+				}
+				return nil
+			},
+		},
+	})
+
+	ctx := context.Background()
+	headers := map[string][]byte{"Content-Type": []byte("application/json")}
+
+	out, err := p.ProcessMessage(ctx, []byte("k"), []byte(`{"id":1,"card":"4111"}`), headers)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"card":"xxx","id":1}`, string(out))
+
+	out, err = p.ProcessMessage(ctx, []byte("k"), nil, headers)
+	assert.NoError(t, err)
+	assert.Nil(t, out)
+
+	_, err = p.ProcessMessage(ctx, nil, []byte(`{"card":"4111"}`), nil)
+	assert.Equal(t, errNoID, err)
+
+	_, err = p.ProcessMessage(ctx, nil, []byte(`{"id":1} {}`), nil)
+	assert.Error(t, err)
+
+	_, err = p.ProcessMessage(ctx, nil, []byte(`{"id":1}`), map[string][]byte{"content-type": []byte("text/plain")})
+	assert.Equal(t, jsonutil.ErrNotJSON, err)
+
+	_, err = p.ProcessMessage(ctx, nil, make([]byte, 65), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package jsonutil
+
+import "time"
+
+// Metrics receive the measurement of document processing, i.e: to export them into Prometheus.
+// Implementation must be safe for concurrent use.
+type Metrics interface {
+	// DocumentProcessed is called once for every processed document with its input size and processing latency.
+	DocumentProcessed(size int, latency time.Duration)
+	// FieldsMasked is called with the number of string values replaced in one document.
+	FieldsMasked(n int)
+	// BytesTruncated is called with the number of bytes removed by truncation in one document.
+	BytesTruncated(n int)
+	// Error is called when the document cannot be processed.
+	Error(err error)
+}
+
+// NopMetrics is Metrics which does nothing, it is used when Metrics is not configured.
+type NopMetrics struct{}
+
+var _ Metrics = NopMetrics{}
+
+func (NopMetrics) DocumentProcessed(int, time.Duration) {}
+func (NopMetrics) FieldsMasked(int)                     {}
+func (NopMetrics) BytesTruncated(int)                   {}
+func (NopMetrics) Error(error)                          {}
+
+// observe report the result of processing doc started at start into metrics.
+func observe(metrics Metrics, doc []byte, start time.Time, masked, truncated int, err error) {
+	if err != nil {
+		metrics.Error(err)
+		return
+	}
+
+	metrics.DocumentProcessed(len(doc), time.Since(start))
+	if masked > 0 {
+		metrics.FieldsMasked(masked)
+	}
+
+	if truncated > 0 {
+		metrics.BytesTruncated(truncated)
+	}
+}
@@ -0,0 +1,99 @@
+package jsonutil_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+type testMetrics struct {
+	mu        sync.Mutex
+	documents int
+	sizes     []int
+	masked    int
+	truncated int
+	errors    []error
+}
+
+func (m *testMetrics) DocumentProcessed(size int, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.documents++
+	m.sizes = append(m.sizes, size)
+}
+
+func (m *testMetrics) FieldsMasked(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.masked += n
+}
+
+func (m *testMetrics) BytesTruncated(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.truncated += n
+}
+
+func (m *testMetrics) Error(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errors = append(m.errors, err)
+}
+
+func TestSanitizer_Metrics(t *testing.T) {
+	metrics := &testMetrics{}
+	s := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{
+		MaskKeys: []string{"password", "tokens"},
+		MaxChars: 5,
+		Metrics:  metrics,
+	})
+
+	doc := `{"password": "a", "tokens": ["b", "c"], "bio": "0123456789"}`
+	_, err := s.Sanitize(context.Background(), []byte(doc))
+	assert.NoError(t, err)
+
+	_, err = s.Sanitize(context.Background(), []byte(`[`))
+	assert.Error(t, err)
+
+	assert.Equal(t, 1, metrics.documents)
+	assert.Equal(t, []int{len(doc)}, metrics.sizes)
+	assert.Equal(t, 3, metrics.masked)
+	assert.Equal(t, 5, metrics.truncated)
+	assert.Len(t, metrics.errors, 1)
+}
+
+func TestTransformer_Metrics(t *testing.T) {
+	metrics := &testMetrics{}
+	transformer := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "password" {
+				return "***"
+			}
+			return info.Value
+		},
+		Metrics: metrics,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := transformer.TransformBytes(context.Background(), []byte(`{"password": "a", "list": [{"password": "b"}], "name": "c"}`))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	_, err := transformer.TransformBytes(context.Background(), []byte(`{`))
+	assert.Error(t, err)
+
+	assert.Equal(t, 10, metrics.documents)
+	assert.Equal(t, 20, metrics.masked)
+	assert.Equal(t, 0, metrics.truncated)
+	assert.Len(t, metrics.errors, 1)
+}
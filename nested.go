@@ -0,0 +1,109 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// ExpandNestedJSON find string values which content is JSON object or array, and inline it as the real value,
+// i.e: {"payload":"{\"id\":1}"} become {"payload":{"id":1}}.
+// This makes layered log envelope queryable by the log storage.
+// The maxDepth is how many levels of JSON-inside-string is expanded,
+// 1 means the string inside the expanded value is kept as is. Zero or negative expands nothing.
+// Use NestedJSONPaths to know which paths is expanded, so it can be reverted using CollapseNestedJSON.
+func ExpandNestedJSON(doc []byte, maxDepth int) ([]byte, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(expandNested(data, []string{}, maxDepth, nil))
+}
+
+// NestedJSONPaths return the (dotted) paths which is expanded by ExpandNestedJSON with the same maxDepth.
+// The paths is in the expanded document, sorted alphabetically.
+func NestedJSONPaths(doc []byte, maxDepth int) ([]string, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0)
+	expandNested(data, []string{}, maxDepth, &paths)
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func expandNested(data interface{}, segments []string, depth int, paths *[]string) interface{} {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			v[key] = expandNested(val, append(segments, key), depth, paths)
+		}
+
+	case []interface{}:
+		for i, val := range v {
+			v[i] = expandNested(val, append(segments, strconv.Itoa(i)), depth, paths)
+		}
+
+	case string:
+		if depth <= 0 {
+			return v
+		}
+
+		raw := bytes.TrimSpace([]byte(v))
+		if len(raw) == 0 || (raw[0] != '{' && raw[0] != '[') {
+			return v
+		}
+
+		var nested interface{}
+		if err := decodeDocument(raw, &nested); err != nil {
+			return v
+		}
+
+		if paths != nil {
+			*paths = append(*paths, JoinPath(segments))
+		}
+
+		return expandNested(nested, segments, depth-1, paths)
+	}
+
+	return data
+}
+
+// CollapseNestedJSON is the reverse of ExpandNestedJSON, the value on every path is encoded back as JSON string.
+// Deeper paths is collapsed first, so both "a" and "a.b" can be given in any order.
+// The rest of the document is kept as is.
+func CollapseNestedJSON(doc []byte, paths ...string) ([]byte, error) {
+	sorted := make([][]string, len(paths))
+	for i, path := range paths {
+		sorted[i] = SplitPath(path)
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i]) > len(sorted[j])
+	})
+
+	for _, segments := range sorted {
+		start, end, err := locate(doc, segments)
+		if err != nil {
+			return nil, err
+		}
+
+		var compact bytes.Buffer
+		if err = json.Compact(&compact, doc[start:end]); err != nil {
+			return nil, err
+		}
+
+		str, err := json.Marshal(compact.String())
+		if err != nil {
+			return nil, err
+		}
+
+		doc = splice(doc, start, end, str)
+	}
+
+	return doc, nil
+}
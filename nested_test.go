@@ -0,0 +1,46 @@
+package jsonutil_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestExpandNestedJSON(t *testing.T) {
+	in := []byte(`{"id":1,"msg":"{not json","payload":"{\"user\":\"alice\",\"inner\":\"[1, 2]\"}","list":[" [\"x\"] ","abc"]}`)
+
+	out, err := jsonutil.ExpandNestedJSON(in, 1)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"msg":"{not json","payload":{"user":"alice","inner":"[1, 2]"},"list":[["x"],"abc"]}`, string(out))
+
+	paths, err := jsonutil.NestedJSONPaths(in, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"list.0", "payload"}, paths)
+
+	out, err = jsonutil.ExpandNestedJSON(in, 2)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":1,"msg":"{not json","payload":{"user":"alice","inner":[1,2]},"list":[["x"],"abc"]}`, string(out))
+
+	paths, err = jsonutil.NestedJSONPaths(in, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"list.0", "payload", "payload.inner"}, paths)
+
+	out, err = jsonutil.ExpandNestedJSON(in, 0)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(in), string(out))
+
+	_, err = jsonutil.ExpandNestedJSON([]byte(`{`), 1)
+	assert.Error(t, err)
+}
+
+func TestCollapseNestedJSON(t *testing.T) {
+	in := []byte(`{"id": 1, "payload": {"user": "alice", "inner": [1, 2]}}`)
+
+	out, err := jsonutil.CollapseNestedJSON(in, "payload", "payload.inner")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id": 1, "payload": "{\"user\":\"alice\",\"inner\":\"[1,2]\"}"}`, string(out))
+
+	_, err = jsonutil.CollapseNestedJSON(in, "missing")
+	assert.ErrorIs(t, err, jsonutil.ErrPathNotFound)
+}
@@ -0,0 +1,72 @@
+package jsonutil
+
+import (
+	"context"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+type NormalizeConfig struct {
+	// Form is the Unicode normalization form, default to norm.NFC.
+	// Use norm.NFKC to also fold compatibility characters, i.e: full-width "Ａ" become "A".
+	Form norm.Form
+
+	// Keys limit the normalization only to the value of these keys, empty means every string value.
+	Keys []string
+
+	// OnConfusable when not nil is called for every (normalized) value which mix letters from Latin, Greek and Cyrillic script,
+	// such as "pаypal" with Cyrillic "а", which is commonly used for spoofing.
+	OnConfusable func(ctx context.Context, info KVInfo)
+}
+
+// NormalizeUnicode return StringTransformer which normalize the string value into the same Unicode normalization form,
+// so the same text typed in different way (i.e: "é" as single rune or "e" + combining accent) is matched exactly downstream.
+func NormalizeUnicode(conf NormalizeConfig) StringTransformer {
+	keys := make(map[string]struct{}, len(conf.Keys))
+	for _, key := range conf.Keys {
+		keys[key] = struct{}{}
+	}
+
+	return func(ctx context.Context, info KVInfo) string {
+		if len(keys) > 0 {
+			if _, ok := keys[info.Key]; !ok {
+				return info.Value
+			}
+		}
+
+		info.Value = conf.Form.String(info.Value)
+		if conf.OnConfusable != nil && IsMixedScript(info.Value) {
+			conf.OnConfusable(ctx, info)
+		}
+
+		return info.Value
+	}
+}
+
+// confusableScripts is the scripts which have many letters look alike each other.
+var confusableScripts = []*unicode.RangeTable{unicode.Latin, unicode.Greek, unicode.Cyrillic}
+
+// IsMixedScript return true if str contains letters from more than one of Latin, Greek and Cyrillic script.
+func IsMixedScript(str string) bool {
+	seen := -1
+	for _, r := range str {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		for i, script := range confusableScripts {
+			if !unicode.Is(script, r) {
+				continue
+			}
+
+			if seen >= 0 && seen != i {
+				return true
+			}
+
+			seen = i
+		}
+	}
+
+	return false
+}
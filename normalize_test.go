@@ -0,0 +1,54 @@
+package jsonutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestNormalizeUnicode(t *testing.T) {
+	// "cafe\u0301" is "e" + combining acute accent, and full-width "ＡＢＣ"
+	in := []byte(`{"name":"cafe\u0301","code":"ＡＢＣ"}`)
+
+	mask := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: jsonutil.NormalizeUnicode(jsonutil.NormalizeConfig{}),
+	})
+
+	out, err := mask.TransformBytes(context.Background(), in)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"caf\u00e9","code":"ＡＢＣ"}`, string(out))
+
+	mask = jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: jsonutil.NormalizeUnicode(jsonutil.NormalizeConfig{Form: norm.NFKC, Keys: []string{"code"}}),
+	})
+
+	out, err = mask.TransformBytes(context.Background(), in)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"cafe\u0301","code":"ABC"}`, string(out))
+}
+
+func TestNormalizeUnicode_OnConfusable(t *testing.T) {
+	var confusables []string
+	mask := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: jsonutil.NormalizeUnicode(jsonutil.NormalizeConfig{
+			OnConfusable: func(ctx context.Context, info jsonutil.KVInfo) {
+				confusables = append(confusables, info.Key)
+			},
+		}),
+	})
+
+	_, err := mask.TransformBytes(context.Background(), []byte(`{"spoof":"pаypal","latin":"paypal","cyrillic":"привет","mixed_words":"hello мир"}`))
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"spoof", "mixed_words"}, confusables)
+}
+
+func TestIsMixedScript(t *testing.T) {
+	assert.False(t, jsonutil.IsMixedScript("paypal 123"))
+	assert.False(t, jsonutil.IsMixedScript("Ωμέγα"))
+	assert.True(t, jsonutil.IsMixedScript("pаypal"))
+	assert.True(t, jsonutil.IsMixedScript("Ωmega"))
+	assert.False(t, jsonutil.IsMixedScript("日本語 text"))
+}
@@ -0,0 +1,254 @@
+package jsonutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ErrAmbiguousNumber is returned by NormalizeNumbers in strict mode when the decimal separator cannot be determined,
+// i.e: "1,234" is either 1234 or 1.234.
+var ErrAmbiguousNumber = errors.New("jsonutil: ambiguous number")
+
+// ErrInvalidNumber is returned by NormalizeNumbers in strict mode when the string value is not a number.
+var ErrInvalidNumber = errors.New("jsonutil: invalid number")
+
+// NumberConfig configure NormalizeNumbers.
+type NumberConfig struct {
+	// Paths is the value to be converted, in dotted form where "*" match any single segment, i.e: items.*.price.
+	// Array of string on the path is converted element by element.
+	Paths []string
+
+	// DecimalSeparator is used when a single separator is followed by exactly three digits (i.e: "1,234"),
+	// either '.' (default) or ','.
+	DecimalSeparator rune
+
+	// Strict return ErrAmbiguousNumber instead of using DecimalSeparator for the ambiguous value,
+	// and ErrInvalidNumber for the value which is not a number, instead of keeping it as is.
+	Strict bool
+}
+
+type numberNormalizer struct {
+	paths   [][]string
+	decimal byte
+	strict  bool
+}
+
+// NormalizeNumbers return Processor which convert locale formatted string number on the configured paths
+// into JSON number, i.e: "1.234,56", "1,234.56", "$ 1 234.56" and "Rp1.234,56" become 1234.56.
+// Currency symbols, spaces and apostrophe (Swiss grouping) are removed.
+// When the string contains both '.' and ',' the last one is the decimal separator,
+// when one of them appear more than once it is the grouping separator.
+// Number and other type of value is kept as is.
+func NormalizeNumbers(conf NumberConfig) Processor {
+	n := &numberNormalizer{decimal: '.', strict: conf.Strict}
+	if conf.DecimalSeparator == ',' {
+		n.decimal = ','
+	}
+
+	for _, path := range conf.Paths {
+		n.paths = append(n.paths, SplitPath(path))
+	}
+
+	return n
+}
+
+func (n *numberNormalizer) Process(ctx context.Context, doc []byte) ([]byte, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, err
+	}
+
+	out, err := n.walk(data, []string{}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(out)
+}
+
+func (n *numberNormalizer) walk(v interface{}, segments []string, matched bool) (interface{}, error) {
+	if !matched {
+		for _, path := range n.paths {
+			if matchSegments(path, segments) {
+				matched = true
+				break
+			}
+		}
+	}
+
+	var err error
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, child := range val {
+			if val[key], err = n.walk(child, append(segments, key), false); err != nil {
+				return nil, err
+			}
+		}
+
+	case []interface{}:
+		for i, child := range val {
+			// string elements of the matched array is converted too
+			_, isString := child.(string)
+			if val[i], err = n.walk(child, append(segments, strconv.Itoa(i)), matched && isString); err != nil {
+				return nil, err
+			}
+		}
+
+	case string:
+		if !matched {
+			return v, nil
+		}
+
+		num, err := ParseLocaleNumber(val, rune(n.decimal))
+		if err == nil {
+			return num, nil
+		}
+
+		if errors.Is(err, ErrAmbiguousNumber) && !n.strict {
+			num, _ = parseLocaleNumber(val, n.decimal, true)
+			return num, nil
+		}
+
+		if n.strict {
+			return nil, fmt.Errorf("%w %q on path %q", err, val, JoinPath(segments))
+		}
+	}
+
+	return v, nil
+}
+
+// ParseLocaleNumber parse locale formatted number, see NormalizeNumbers.
+// decimal is only used to tell whether the value is ambiguous, ErrAmbiguousNumber is returned for it.
+func ParseLocaleNumber(str string, decimal rune) (json.Number, error) {
+	sep := byte('.')
+	if decimal == ',' {
+		sep = ','
+	}
+
+	return parseLocaleNumber(str, sep, false)
+}
+
+// parseLocaleNumber parse str, when resolve is true the ambiguous value use decimal as the separator.
+func parseLocaleNumber(str string, decimal byte, resolve bool) (json.Number, error) {
+	var sb strings.Builder
+	for _, r := range str {
+		switch {
+		case unicode.Is(unicode.Sc, r), unicode.IsSpace(r), r == '\'', r == '’', r == ' ':
+		case r == '-', r == '+', r == '.', r == ',', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		case unicode.IsLetter(r) && sb.Len() == 0:
+			// currency code prefix, i.e: Rp, IDR, USD
+		default:
+			return "", ErrInvalidNumber
+		}
+	}
+
+	s := sb.String()
+	neg := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	lastDot, lastComma := strings.LastIndexByte(s, '.'), strings.LastIndexByte(s, ',')
+	dots, commas := strings.Count(s, "."), strings.Count(s, ",")
+
+	var group byte
+	decimalAt := -1
+	switch {
+	case dots > 0 && commas > 0:
+		decimalAt, group = lastDot, ','
+		if lastComma > lastDot {
+			decimalAt, group = lastComma, '.'
+		}
+
+		if strings.Count(s, string(s[decimalAt])) > 1 {
+			return "", ErrInvalidNumber
+		}
+
+	case dots > 1 || commas > 1:
+		group = '.'
+		if commas > 0 {
+			group = ','
+		}
+
+	case dots == 1 || commas == 1:
+		at := lastDot + lastComma + 1 // the other one is -1
+		if len(s)-at-1 != 3 || at == 0 || at > 3 || s[0] == '0' {
+			decimalAt = at
+			break
+		}
+
+		// 1,234 or 1.234
+		if !resolve {
+			return "", ErrAmbiguousNumber
+		}
+
+		if s[at] == decimal {
+			decimalAt = at
+		} else {
+			group = s[at]
+		}
+	}
+
+	intPart, fracPart := s, ""
+	if decimalAt >= 0 {
+		intPart, fracPart = s[:decimalAt], s[decimalAt+1:]
+		if fracPart == "" || !isDigits(fracPart) {
+			return "", ErrInvalidNumber
+		}
+	}
+
+	if group != 0 {
+		groups := strings.Split(intPart, string(group))
+		for i, g := range groups {
+			if !isDigits(g) || (i > 0 && len(g) != 3) || (i == 0 && len(g) > 3) {
+				return "", ErrInvalidNumber
+			}
+		}
+		intPart = strings.Join(groups, "")
+	}
+
+	if intPart == "" && decimalAt == 0 {
+		intPart = "0" // .5
+	}
+
+	if !isDigits(intPart) {
+		return "", ErrInvalidNumber
+	}
+
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	num := intPart
+	if fracPart != "" {
+		num += "." + fracPart
+	}
+
+	if neg && strings.Trim(num, "0.") != "" {
+		num = "-" + num
+	}
+
+	return json.Number(num), nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,84 @@
+package jsonutil_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestParseLocaleNumber(t *testing.T) {
+	testCases := []struct {
+		In     string
+		Expect json.Number
+		Err    error
+	}{
+		{In: "1.234,56", Expect: "1234.56"},
+		{In: "1,234.56", Expect: "1234.56"},
+		{In: "$ 1,234,567.89", Expect: "1234567.89"},
+		{In: "Rp1.234.567", Expect: "1234567"},
+		{In: "1 234,5 €", Expect: "1234.5"},
+		{In: "1'234.5", Expect: "1234.5"},
+		{In: "-12,5", Expect: "-12.5"},
+		{In: "+0012.50", Expect: "12.50"},
+		{In: "1234,567", Expect: "1234.567"},
+		{In: "0,125", Expect: "0.125"},
+		{In: ".5", Expect: "0.5"},
+		{In: "-0", Expect: "0"},
+		{In: "42", Expect: "42"},
+		{In: "1,234", Err: jsonutil.ErrAmbiguousNumber},
+		{In: "12.345", Err: jsonutil.ErrAmbiguousNumber},
+		{In: "1,23,456", Err: jsonutil.ErrInvalidNumber},
+		{In: "1.234.56,7,8", Err: jsonutil.ErrInvalidNumber},
+		{In: "1e5", Err: jsonutil.ErrInvalidNumber},
+		{In: "abc", Err: jsonutil.ErrInvalidNumber},
+		{In: "12,", Err: jsonutil.ErrInvalidNumber},
+		{In: "", Err: jsonutil.ErrInvalidNumber},
+	}
+
+	for _, tc := range testCases {
+		num, err := jsonutil.ParseLocaleNumber(tc.In, '.')
+		if tc.Err != nil {
+			assert.True(t, errors.Is(err, tc.Err), "%q: %v", tc.In, err)
+			continue
+		}
+
+		assert.NoError(t, err, tc.In)
+		assert.Equal(t, tc.Expect, num, tc.In)
+	}
+}
+
+func TestNormalizeNumbers(t *testing.T) {
+	doc := []byte(`{"items":[{"price":"1.234,56","qty":"1,234","name":"1,5"},{"price":12.5,"qty":"n/a"}],"totals":["1,000.5","2.000,25"]}`)
+
+	p := jsonutil.NormalizeNumbers(jsonutil.NumberConfig{Paths: []string{"items.*.price", "items.*.qty", "totals"}})
+	out, err := p.Process(context.Background(), doc)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"items":[{"price":1234.56,"qty":1234,"name":"1,5"},{"price":12.5,"qty":"n/a"}],"totals":[1000.5,2000.25]}`, string(out))
+
+	p = jsonutil.NormalizeNumbers(jsonutil.NumberConfig{Paths: []string{"items.*.qty"}, DecimalSeparator: ','})
+	out, err = p.Process(context.Background(), doc)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), `"qty":1.234`)
+}
+
+func TestNormalizeNumbers_Strict(t *testing.T) {
+	p := jsonutil.NormalizeNumbers(jsonutil.NumberConfig{Paths: []string{"items.*.qty"}, Strict: true})
+
+	_, err := p.Process(context.Background(), []byte(`{"items":[{"qty":"1,234"}]}`))
+	assert.True(t, errors.Is(err, jsonutil.ErrAmbiguousNumber))
+	assert.EqualError(t, err, `jsonutil: ambiguous number "1,234" on path "items.0.qty"`)
+
+	_, err = p.Process(context.Background(), []byte(`{"items":[{"qty":"n/a"}]}`))
+	assert.True(t, errors.Is(err, jsonutil.ErrInvalidNumber))
+
+	out, err := p.Process(context.Background(), []byte(`{"items":[{"qty":"1.234,5"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"items":[{"qty":1234.5}]}`, string(out))
+
+	_, err = p.Process(context.Background(), []byte(`{`))
+	assert.Error(t, err)
+}
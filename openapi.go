@@ -0,0 +1,243 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// openAPIMethods is the operation keys of OpenAPI 3 path item.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// OperationPolicy is the sanitization pipelines of one OpenAPI operation.
+// Pipeline without any sensitive field has no processor, so it returns the document as is.
+type OperationPolicy struct {
+	Method      string // Method is upper case HTTP method, i.e: GET
+	Path        string // Path is the path template, i.e: /users/{id}
+	OperationID string
+
+	Request   *Pipeline
+	Responses map[string]*Pipeline // Responses is keyed by the status code as written, i.e: "200", "4XX" or "default"
+}
+
+// Response return the pipeline of the response status, falling back to the range (i.e: "4XX") then "default".
+func (o *OperationPolicy) Response(status int) *Pipeline {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if p, ok := o.Responses[key]; ok {
+			return p
+		}
+	}
+
+	return NewPipeline()
+}
+
+// OpenAPIPolicy is the sanitization policy derived from OpenAPI 3 document, see LoadOpenAPIPolicy.
+type OpenAPIPolicy struct {
+	operations []*OperationPolicy
+}
+
+// LoadOpenAPIPolicy read OpenAPI 3 document (YAML or JSON) and build the request and response pipelines of every operation.
+// The JSON request body and response schemas are walked like RulesFromSchema, where schema with SensitiveKeyword
+// produce its rule and schema with "format": "password" is masked.
+// Local $ref (i.e: #/components/schemas/User) is resolved for schema, request body and response.
+func LoadOpenAPIPolicy(r io.Reader) (*OpenAPIPolicy, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw interface{}
+	if err = yaml.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("jsonutil: cannot parse OpenAPI document: %w", err)
+	}
+
+	// YAML mapping may have non-string keys (i.e: response code 200), normalize it through JSON
+	b, err = json.Marshal(stringKeys(raw))
+	if err != nil {
+		return nil, fmt.Errorf("jsonutil: cannot parse OpenAPI document: %w", err)
+	}
+
+	var root interface{}
+	if err = decodeDocument(b, &root); err != nil {
+		return nil, err
+	}
+
+	doc, _ := root.(map[string]interface{})
+	paths, _ := doc["paths"].(map[string]interface{})
+
+	policy := &OpenAPIPolicy{}
+	for path, item := range paths {
+		pathItem, _ := item.(map[string]interface{})
+		for _, method := range openAPIMethods {
+			op, ok := pathItem[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			operation, err := newOperationPolicy(root, strings.ToUpper(method), path, op)
+			if err != nil {
+				return nil, fmt.Errorf("jsonutil: operation %s %s: %w", strings.ToUpper(method), path, err)
+			}
+
+			policy.operations = append(policy.operations, operation)
+		}
+	}
+
+	sort.Slice(policy.operations, func(i, j int) bool {
+		a, b := policy.operations[i], policy.operations[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Method < b.Method
+	})
+
+	return policy, nil
+}
+
+func newOperationPolicy(root interface{}, method, path string, op map[string]interface{}) (*OperationPolicy, error) {
+	w := newSchemaWalker(root, true)
+	operation := &OperationPolicy{Method: method, Path: path, Responses: map[string]*Pipeline{}}
+	operation.OperationID, _ = op["operationId"].(string)
+
+	var err error
+	if body, ok := op["requestBody"]; ok {
+		if operation.Request, err = contentPipeline(w, body); err != nil {
+			return nil, fmt.Errorf("request body: %w", err)
+		}
+	} else {
+		operation.Request = NewPipeline()
+	}
+
+	responses, _ := op["responses"].(map[string]interface{})
+	for code, response := range responses {
+		if operation.Responses[code], err = contentPipeline(w, response); err != nil {
+			return nil, fmt.Errorf("response %s: %w", code, err)
+		}
+	}
+
+	return operation, nil
+}
+
+// contentPipeline return the pipeline of JSON schemas inside the content of request body or response object.
+func contentPipeline(w *schemaWalker, node interface{}) (*Pipeline, error) {
+	obj, _ := node.(map[string]interface{})
+	if ref, ok := obj["$ref"].(string); ok {
+		target, err := w.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		obj, _ = target.(map[string]interface{})
+	}
+
+	w.rules = map[string]Rule{}
+	content, _ := obj["content"].(map[string]interface{})
+	for mediaType, media := range content {
+		if !strings.Contains(mediaType, "json") {
+			continue
+		}
+
+		mediaObj, _ := media.(map[string]interface{})
+		if schema, ok := mediaObj["schema"]; ok {
+			if err := w.walk(schema, []string{}, map[string]bool{}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	rules := w.sortedRules()
+	if len(rules) == 0 {
+		return NewPipeline(), nil
+	}
+
+	ruleSet, err := NewRuleSet(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPipeline(ruleSet), nil
+}
+
+// Operations return every operation sorted by path then method.
+func (p *OpenAPIPolicy) Operations() []*OperationPolicy {
+	return p.operations
+}
+
+// Match return the operation of the request method and path, i.e: GET /users/123 match GET /users/{id}.
+// When several templates match, the one with the most literal segments wins.
+func (p *OpenAPIPolicy) Match(method, path string) (*OperationPolicy, bool) {
+	method = strings.ToUpper(method)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var found *OperationPolicy
+	bestLiterals := -1
+	for _, op := range p.operations {
+		if op.Method != method {
+			continue
+		}
+
+		literals, ok := matchPathTemplate(op.Path, segments)
+		if ok && literals > bestLiterals {
+			found, bestLiterals = op, literals
+		}
+	}
+
+	return found, found != nil
+}
+
+// matchPathTemplate return the number of literal segments when the template match the path segments.
+func matchPathTemplate(template string, segments []string) (int, bool) {
+	parts := strings.Split(strings.Trim(template, "/"), "/")
+	if len(parts) != len(segments) {
+		return 0, false
+	}
+
+	literals := 0
+	for i, part := range parts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if segments[i] == "" {
+				return 0, false
+			}
+			continue
+		}
+
+		if part != segments[i] {
+			return 0, false
+		}
+		literals++
+	}
+
+	return literals, true
+}
+
+// stringKeys convert the map with non-string keys decoded from YAML into map[string]interface{}.
+func stringKeys(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, child := range val {
+			m[fmt.Sprint(k)] = stringKeys(child)
+		}
+		return m
+
+	case map[string]interface{}:
+		for k, child := range val {
+			val[k] = stringKeys(child)
+		}
+		return val
+
+	case []interface{}:
+		for i, child := range val {
+			val[i] = stringKeys(child)
+		}
+		return val
+	}
+
+	return v
+}
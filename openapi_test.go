@@ -0,0 +1,164 @@
+package jsonutil_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+const sampleOpenAPI = `
+openapi: 3.0.3
+info:
+  title: users
+  version: "1"
+paths:
+  /users:
+    post:
+      operationId: createUser
+      requestBody:
+        $ref: "#/components/requestBodies/NewUser"
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/User"
+  /users/{id}:
+    get:
+      operationId: getUser
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/User"
+        4XX:
+          description: error
+          content:
+            application/problem+json:
+              schema:
+                type: object
+                properties:
+                  detail:
+                    type: string
+                    x-sensitive: truncate
+                    x-max-chars: 3
+  /users/me:
+    get:
+      operationId: getMe
+      responses:
+        default:
+          description: ok
+components:
+  requestBodies:
+    NewUser:
+      content:
+        application/json:
+          schema:
+            type: object
+            properties:
+              name:
+                type: string
+              password:
+                type: string
+                format: password
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+        api_key:
+          type: string
+          x-sensitive: hash
+        devices:
+          type: array
+          items:
+            type: object
+            properties:
+              token:
+                type: string
+                x-sensitive: drop
+`
+
+func TestLoadOpenAPIPolicy(t *testing.T) {
+	policy, err := jsonutil.LoadOpenAPIPolicy(strings.NewReader(sampleOpenAPI))
+	assert.NoError(t, err)
+
+	ops := []string{}
+	for _, op := range policy.Operations() {
+		ops = append(ops, op.Method+" "+op.Path+" "+op.OperationID)
+	}
+	assert.Equal(t, []string{"POST /users createUser", "GET /users/me getMe", "GET /users/{id} getUser"}, ops)
+
+	ctx := context.Background()
+
+	t.Run("request", func(t *testing.T) {
+		op, ok := policy.Match("post", "/users")
+		assert.True(t, ok)
+
+		out, err := op.Request.Process(ctx, []byte(`{"name":"alice","password":"secret"}`))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"name":"alice","password":"***"}`, string(out))
+	})
+
+	t.Run("response", func(t *testing.T) {
+		op, ok := policy.Match("GET", "/users/123")
+		assert.True(t, ok)
+		assert.Equal(t, "getUser", op.OperationID)
+
+		out, err := op.Response(200).Process(ctx, []byte(`{"name":"alice","api_key":"k","devices":[{"id":1,"token":"t"}]}`))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"name":"alice","api_key":"sha256:8254c329a92850f6d539dd376f4816ee2764517da5e0235514af433164480d7a","devices":[{"id":1}]}`, string(out))
+
+		out, err = op.Response(404).Process(ctx, []byte(`{"detail":"not found"}`))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"detail":"not **escaped 6 chars**"}`, string(out))
+
+		// no schema for 500
+		out, err = op.Response(500).Process(ctx, []byte(`{"detail":"internal"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"detail":"internal"}`, string(out))
+	})
+
+	t.Run("literal path wins", func(t *testing.T) {
+		op, ok := policy.Match("GET", "/users/me")
+		assert.True(t, ok)
+		assert.Equal(t, "getMe", op.OperationID)
+
+		_, ok = policy.Match("DELETE", "/users/me")
+		assert.False(t, ok)
+
+		_, ok = policy.Match("GET", "/users/me/devices")
+		assert.False(t, ok)
+	})
+}
+
+func TestLoadOpenAPIPolicy_Error(t *testing.T) {
+	_, err := jsonutil.LoadOpenAPIPolicy(strings.NewReader(`
+paths:
+  /a:
+    post:
+      requestBody:
+        $ref: "#/components/requestBodies/Missing"
+`))
+	assert.Error(t, err)
+
+	_, err = jsonutil.LoadOpenAPIPolicy(strings.NewReader(`
+paths:
+  /a:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                x-sensitive: encrypt
+`))
+	assert.Error(t, err)
+}
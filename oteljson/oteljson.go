@@ -0,0 +1,176 @@
+// Package oteljson trace jsonutil.Processor using OpenTelemetry,
+// so the sanitization cost shows up in the request traces.
+package oteljson
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/yusufsyaifudin/jsonutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName is the name of the tracer.
+const InstrumentationName = "github.com/yusufsyaifudin/jsonutil/oteljson"
+
+// Attribute keys set on the span.
+const (
+	AttrProcessor  = attribute.Key("jsonutil.processor")
+	AttrBytesIn    = attribute.Key("jsonutil.bytes_in")
+	AttrBytesOut   = attribute.Key("jsonutil.bytes_out")
+	AttrRuleCount  = attribute.Key("jsonutil.rule_count")
+	AttrStageCount = attribute.Key("jsonutil.stage_count")
+	AttrChanged    = attribute.Key("jsonutil.changed")
+
+	// AttrChangedFields is the number of leaf values (see jsonutil.Paths) changed, added or removed by the processor.
+	AttrChangedFields = attribute.Key("jsonutil.changed_fields")
+)
+
+// Config is the configuration of Wrap.
+type Config struct {
+	// Enabled turn on the tracing, when false Wrap return the processor as is.
+	Enabled bool
+
+	// SpanName default to "jsonutil.Process".
+	SpanName string
+
+	// TracerProvider default to otel.GetTracerProvider().
+	TracerProvider trace.TracerProvider
+}
+
+type processor struct {
+	next     jsonutil.Processor
+	tracer   trace.Tracer
+	spanName string
+	attrs    []attribute.KeyValue
+}
+
+var _ jsonutil.Processor = (*processor)(nil)
+
+// Wrap return Processor which create a span around every p.Process call.
+// The span contains the document size before and after processing, the processor type,
+// the number of rules (for *jsonutil.RuleSet) or stages (for *jsonutil.Pipeline), and the number of changed fields.
+// Error is recorded on the span and the span status is set to error.
+func Wrap(p jsonutil.Processor, conf Config) jsonutil.Processor {
+	if !conf.Enabled {
+		return p
+	}
+
+	if conf.SpanName == "" {
+		conf.SpanName = "jsonutil.Process"
+	}
+
+	if conf.TracerProvider == nil {
+		conf.TracerProvider = otel.GetTracerProvider()
+	}
+
+	attrs := []attribute.KeyValue{AttrProcessor.String(fmt.Sprintf("%T", p))}
+	switch v := p.(type) {
+	case *jsonutil.RuleSet:
+		attrs = append(attrs, AttrRuleCount.Int(v.Len()))
+	case *jsonutil.Pipeline:
+		attrs = append(attrs, AttrStageCount.Int(len(v.Processors())))
+	}
+
+	return &processor{
+		next:     p,
+		tracer:   conf.TracerProvider.Tracer(InstrumentationName),
+		spanName: conf.SpanName,
+		attrs:    attrs,
+	}
+}
+
+func (p *processor) Process(ctx context.Context, doc []byte) ([]byte, error) {
+	ctx, span := p.tracer.Start(ctx, p.spanName, trace.WithAttributes(p.attrs...))
+	defer span.End()
+
+	span.SetAttributes(AttrBytesIn.Int(len(doc)))
+	out, err := p.next.Process(ctx, doc)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return out, err
+	}
+
+	changed := 0
+	if string(out) != string(doc) {
+		changed = changedFields(doc, out)
+	}
+
+	span.SetAttributes(AttrBytesOut.Int(len(out)), AttrChanged.Bool(changed > 0), AttrChangedFields.Int(changed))
+	return out, nil
+}
+
+// changedFields return the number of leaf paths which value is different in before and after,
+// including the path which only exists in one of them.
+// When either of them is not valid JSON, the whole document is counted as one changed field.
+func changedFields(before, after []byte) int {
+	beforeLeaves, err := leaves(before)
+	if err != nil {
+		return 1
+	}
+
+	afterLeaves, err := leaves(after)
+	if err != nil {
+		return 1
+	}
+
+	changed := 0
+	for path, v := range beforeLeaves {
+		if other, ok := afterLeaves[path]; !ok || other != v {
+			changed++
+		}
+	}
+
+	for path := range afterLeaves {
+		if _, ok := beforeLeaves[path]; !ok {
+			changed++
+		}
+	}
+
+	return changed
+}
+
+// leaves return the JSON encoded value of every leaf in doc, keyed by its JSON Pointer.
+func leaves(doc []byte) (map[string]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+
+	var data interface{}
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string)
+	walkLeaves(data, []string{}, out)
+	return out, nil
+}
+
+func walkLeaves(data interface{}, segments []string, out map[string]string) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			for key, val := range v {
+				walkLeaves(val, append(segments, key), out)
+			}
+			return
+		}
+
+	case []interface{}:
+		if len(v) > 0 {
+			for i, val := range v {
+				walkLeaves(val, append(segments, strconv.Itoa(i)), out)
+			}
+			return
+		}
+	}
+
+	b, _ := json.Marshal(data)
+	out[jsonutil.JoinPointer(segments)] = string(b)
+}
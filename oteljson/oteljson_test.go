@@ -0,0 +1,79 @@
+package oteljson_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"github.com/yusufsyaifudin/jsonutil/oteljson"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWrap(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ruleSet, err := jsonutil.NewRuleSet([]jsonutil.Rule{{Keys: []string{"password"}, Action: jsonutil.ActionMask}})
+	assert.NoError(t, err)
+
+	p := oteljson.Wrap(ruleSet, oteljson.Config{Enabled: true, TracerProvider: provider})
+	out, err := p.Process(context.Background(), []byte(`{"password":"secret"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"password":"***"}`, string(out))
+
+	_, err = p.Process(context.Background(), []byte(`{`))
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "jsonutil.Process", spans[0].Name())
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		oteljson.AttrProcessor.String("*jsonutil.RuleSet"),
+		oteljson.AttrRuleCount.Int(1),
+		oteljson.AttrBytesIn.Int(21),
+		oteljson.AttrBytesOut.Int(18),
+		oteljson.AttrChanged.Bool(true),
+		oteljson.AttrChangedFields.Int(1),
+	}, spans[0].Attributes())
+
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Len(t, spans[1].Events(), 1)
+}
+
+func TestWrap_ChangedFields(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	ruleSet, err := jsonutil.NewRuleSet([]jsonutil.Rule{
+		{Keys: []string{"password", "token"}, Action: jsonutil.ActionMask},
+		{Keys: []string{"debug"}, Action: jsonutil.ActionDrop},
+	})
+	assert.NoError(t, err)
+
+	p := oteljson.Wrap(ruleSet, oteljson.Config{Enabled: true, TracerProvider: provider})
+	for _, doc := range []string{
+		`{"user":"a","password":"x","tokens":[{"token":"y"},{"token":"z"}],"debug":{"a":1,"b":[]}}`,
+		`{"user":"a"}`,
+	} {
+		_, err = p.Process(context.Background(), []byte(doc))
+		assert.NoError(t, err)
+	}
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	// password, 2 tokens, and 2 leaves of the removed debug
+	assert.Contains(t, spans[0].Attributes(), oteljson.AttrChangedFields.Int(5))
+	assert.Contains(t, spans[0].Attributes(), oteljson.AttrChanged.Bool(true))
+	assert.Contains(t, spans[1].Attributes(), oteljson.AttrChangedFields.Int(0))
+	assert.Contains(t, spans[1].Attributes(), oteljson.AttrChanged.Bool(false))
+}
+
+func TestWrap_Disabled(t *testing.T) {
+	p := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{})
+	assert.Equal(t, jsonutil.Processor(p), oteljson.Wrap(p, oteljson.Config{}))
+}
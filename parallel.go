@@ -0,0 +1,185 @@
+package jsonutil
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// ItemError is the error of single document in ParallelProcess, or single element in ParallelProcessArray.
+type ItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("jsonutil: item %d: %v", e.Index, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// ItemErrors is list of failed items, sorted by its index.
+type ItemErrors []*ItemError
+
+func (e ItemErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+
+	return fmt.Sprintf("jsonutil: %d items failed, first error: %v", len(e), e[0])
+}
+
+// ParallelProcess process every docs (i.e: lines of NDJSON) using p concurrently with the number of workers.
+// When workers <= 0, runtime.GOMAXPROCS(0) is used.
+// The output has the same order as docs. When some of the docs is failed, its output is nil
+// and the error is returned as ItemErrors along with the rest of the output.
+func ParallelProcess(ctx context.Context, docs [][]byte, p Processor, workers int) ([][]byte, error) {
+	out, errs, dispatched := parallelProcess(ctx, docs, p, workers)
+	if dispatched < len(docs) {
+		return nil, ctx.Err()
+	}
+
+	var itemErrs ItemErrors
+	for i, err := range errs {
+		if err != nil {
+			out[i] = nil
+			itemErrs = append(itemErrs, &ItemError{Index: i, Err: err})
+		}
+	}
+
+	if len(itemErrs) > 0 {
+		return out, itemErrs
+	}
+
+	return out, nil
+}
+
+// parallelProcess process docs using workers, and return the output and error of every dispatched docs.
+// The docs from index dispatched is not processed since ctx is cancelled.
+func parallelProcess(ctx context.Context, docs [][]byte, p Processor, workers int) (out [][]byte, errs []error, dispatched int) {
+	out = make([][]byte, len(docs))
+	errs = make([]error, len(docs))
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	if workers > len(docs) {
+		workers = len(docs)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				out[i], errs[i] = p.Process(ctx, docs[i])
+			}
+		}()
+	}
+
+dispatch:
+	for ; dispatched < len(docs); dispatched++ {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- dispatched:
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+	return out, errs, dispatched
+}
+
+// BatchOpts configure Transformer.TransformBatch.
+type BatchOpts struct {
+	// Workers is the number of goroutines, default to runtime.GOMAXPROCS(0).
+	Workers int
+}
+
+// TransformBatch transform every payloads (i.e: Kafka messages) concurrently using TransformBytes.
+// The output and errors have the same order as payloads, the failed payload has nil output and non-nil error.
+// When ctx is cancelled, the payloads which is not processed yet get ctx.Err() as the error.
+func (m *Transformer) TransformBatch(ctx context.Context, payloads [][]byte, opts BatchOpts) ([][]byte, []error) {
+	out, errs, dispatched := parallelProcess(ctx, payloads, m, opts.Workers)
+	for i := range errs {
+		if i >= dispatched {
+			errs[i] = ctx.Err()
+		}
+
+		if errs[i] != nil {
+			out[i] = nil
+		}
+	}
+
+	return out, errs
+}
+
+// ParallelProcessArray is like ParallelProcess, but for single document with huge top-level array.
+// Each array element is processed as its own document, then joined back in the same order.
+// The whitespace between elements is not preserved.
+// When any element is failed, nil is returned with ItemErrors.
+func ParallelProcessArray(ctx context.Context, doc []byte, p Processor, workers int) ([]byte, error) {
+	start, err := scanDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc[start] != '[' {
+		return nil, fmt.Errorf("jsonutil: top-level value is %s, not array", typeOfRaw(doc[start]))
+	}
+
+	var elems [][]byte
+	_, err = scanArray(doc, start, func(idx, s, e int) bool {
+		elems = append(elems, doc[s:e])
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	outs, err := ParallelProcess(ctx, elems, p, workers)
+	if err != nil {
+		return nil, err
+	}
+
+	size := 2 + len(outs)
+	for _, o := range outs {
+		size += len(o)
+	}
+
+	out := make([]byte, 0, size)
+	out = append(out, '[')
+	for i, o := range outs {
+		if i > 0 {
+			out = append(out, ',')
+		}
+		out = append(out, o...)
+	}
+
+	return append(out, ']'), nil
+}
+
+// typeOfRaw return the Type of raw JSON value starts with byte c.
+func typeOfRaw(c byte) Type {
+	switch c {
+	case '{':
+		return Object
+	case '[':
+		return Array
+	case '"':
+		return String
+	case 't', 'f':
+		return Boolean
+	case 'n':
+		return Null
+	}
+
+	return Number
+}
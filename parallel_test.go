@@ -0,0 +1,159 @@
+package jsonutil_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestParallelProcess(t *testing.T) {
+	s := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{MaskKeys: []string{"password"}})
+	docs := [][]byte{
+		[]byte(`{"password":"a"}`),
+		[]byte(`{"password":`),
+		[]byte(`{"name":"b"}`),
+		[]byte(`[`),
+		[]byte(`{"password":"c"}`),
+	}
+
+	out, err := jsonutil.ParallelProcess(context.Background(), docs, s, 3)
+	assert.Equal(t, [][]byte{[]byte(`{"password":"***"}`), nil, []byte(`{"name":"b"}`), nil, []byte(`{"password":"***"}`)}, out)
+
+	var itemErrs jsonutil.ItemErrors
+	assert.True(t, errors.As(err, &itemErrs))
+	assert.Len(t, itemErrs, 2)
+	assert.Equal(t, 1, itemErrs[0].Index)
+	assert.Equal(t, 3, itemErrs[1].Index)
+
+	out, err = jsonutil.ParallelProcess(context.Background(), docs[:1], s, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(`{"password":"***"}`)}, out)
+
+	out, err = jsonutil.ParallelProcess(context.Background(), nil, s, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestParallelProcess_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{})
+	docs := make([][]byte, 100)
+	for i := range docs {
+		docs[i] = []byte(`{}`)
+	}
+
+	_, err := jsonutil.ParallelProcess(ctx, docs, s, 1)
+	assert.Error(t, err)
+}
+
+func TestParallelProcessArray(t *testing.T) {
+	s := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{MaskKeys: []string{"password"}})
+
+	out, err := jsonutil.ParallelProcessArray(context.Background(), []byte(` [ {"password":"a"}, 1, "x", {"password":["b"]} ] `), s, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"password":"***"},1,"x",{"password":["***"]}]`, string(out))
+
+	out, err = jsonutil.ParallelProcessArray(context.Background(), []byte(`[]`), s, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, string(out))
+
+	_, err = jsonutil.ParallelProcessArray(context.Background(), []byte(`{}`), s, 2)
+	assert.EqualError(t, err, "jsonutil: top-level value is object, not array")
+
+	fail := jsonutil.ProcessorFunc(func(ctx context.Context, doc []byte) ([]byte, error) {
+		if string(doc) == "2" {
+			return nil, errors.New("boom")
+		}
+		return doc, nil
+	})
+
+	_, err = jsonutil.ParallelProcessArray(context.Background(), []byte(`[1,2,3]`), fail, 2)
+	assert.EqualError(t, err, "jsonutil: item 1: boom")
+}
+
+func TestTransformer_TransformBatch(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		Keys: map[string]jsonutil.StringTransformer{
+			"password": func(ctx context.Context, info jsonutil.KVInfo) string { return "***" },
+		},
+	})
+
+	payloads := [][]byte{
+		[]byte(`{"password":"a"}`),
+		[]byte(`{"password":`),
+		[]byte(`{"name":"b"}`),
+	}
+
+	out, errs := tr.TransformBatch(context.Background(), payloads, jsonutil.BatchOpts{Workers: 2})
+	assert.Equal(t, [][]byte{[]byte(`{"password":"***"}`), nil, []byte(`{"name":"b"}`)}, out)
+	assert.Len(t, errs, 3)
+	assert.NoError(t, errs[0])
+	assert.Error(t, errs[1])
+	assert.NoError(t, errs[2])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out, errs = tr.TransformBatch(ctx, payloads, jsonutil.BatchOpts{})
+	assert.Len(t, out, 3)
+	assert.Len(t, errs, 3)
+	for i := range errs {
+		if errs[i] != nil {
+			assert.Nil(t, out[i])
+		}
+	}
+}
+
+func TestTransformer_Parallelism(t *testing.T) {
+	config := jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "password" {
+				return "***"
+			}
+			return info.Value
+		},
+		KeyFilter: func(ctx context.Context, info jsonutil.KVInfo) jsonutil.FilterAction {
+			if info.Key == "debug" {
+				return jsonutil.FilterDelete
+			}
+			return jsonutil.FilterTransform
+		},
+	}
+
+	var in, expected bytes.Buffer
+	in.WriteByte('[')
+	expected.WriteByte('[')
+	for i := 0; i < 100; i++ {
+		if i > 0 {
+			in.WriteByte(',')
+			expected.WriteByte(',')
+		}
+		fmt.Fprintf(&in, `{"id":%d,"password":"p%d","debug":"x"},"s%d"`, i, i, i)
+		fmt.Fprintf(&expected, `{"id":%d,"password":"***"},"s%d"`, i, i)
+	}
+	in.WriteByte(']')
+	expected.WriteByte(']')
+
+	for _, parallelism := range []int{0, 3, 8, 500} {
+		metrics := &testMetrics{}
+		config.Metrics = metrics
+		config.Parallelism = parallelism
+		out, err := jsonutil.NewTransformer(config).TransformBytes(context.Background(), in.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, expected.String(), string(out), "parallelism %d", parallelism)
+		assert.Equal(t, 100, metrics.masked, "parallelism %d", parallelism)
+	}
+
+	config.Parallelism = 4
+	config.MaxDepth = 2
+	config.MaxDepthError = true
+	_, err := jsonutil.NewTransformer(config).TransformBytes(context.Background(), []byte(`["a",{"b":[1]},"c"]`))
+	assert.True(t, errors.Is(err, jsonutil.ErrMaxDepthExceeded))
+}
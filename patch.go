@@ -0,0 +1,311 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+)
+
+// ErrTestFailed is returned by ApplyPatch when the value of a test operation is not equal to the document value.
+var ErrTestFailed = errors.New("jsonutil: patch test failed")
+
+// PatchOperation is one JSON Patch (RFC 6902) operation. Path and From is JSON Pointer.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// PatchError is the failed operation of ApplyPatch.
+type PatchError struct {
+	Index int
+	Op    PatchOperation
+	Err   error
+}
+
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("jsonutil: patch operation %d (%s %q): %v", e.Index, e.Op.Op, e.Op.Path, e.Err)
+}
+
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyPatch apply the JSON Patch operations (add, remove, replace, move, copy and test) in order and return the new document.
+// Same as SetBytes, only the changed part of the document is rewritten.
+// The patch is atomic: when one operation failed, the *PatchError is returned and doc is not changed.
+func ApplyPatch(doc []byte, ops []PatchOperation) ([]byte, error) {
+	if err := Validate(doc); err != nil {
+		return nil, err
+	}
+
+	out := doc
+	for i, op := range ops {
+		var err error
+		out, err = applyOperation(out, op)
+		if err != nil {
+			return nil, &PatchError{Index: i, Op: op, Err: err}
+		}
+	}
+
+	return out, nil
+}
+
+// TestOperations return the test operation of each path with its current value in doc,
+// to be put in front of the patch, so it is only applied when the document is not changed in the meantime
+// (compare-and-swap). Use GuardPatch to do both at once.
+func TestOperations(doc []byte, paths ...string) ([]PatchOperation, error) {
+	ops := make([]PatchOperation, 0, len(paths))
+	for _, path := range paths {
+		segments := SplitPath(path)
+		pointer := JoinPointer(segments)
+		start, end, err := locate(doc, segments)
+		if err != nil {
+			return nil, fmt.Errorf("jsonutil: test operation %q: %w", pointer, err)
+		}
+
+		ops = append(ops, PatchOperation{
+			Op:    "test",
+			Path:  pointer,
+			Value: append(json.RawMessage(nil), doc[start:end]...),
+		})
+	}
+
+	return ops, nil
+}
+
+// GuardPatch return ops prefixed with the test operations of paths from the current doc, see TestOperations.
+// When no path is given, the path and from of every operation in ops is guarded, skipping the one which does not exist yet.
+func GuardPatch(doc []byte, ops []PatchOperation, paths ...string) ([]PatchOperation, error) {
+	if len(paths) == 0 {
+		seen := map[string]bool{}
+		for _, op := range ops {
+			for _, path := range []string{op.Path, op.From} {
+				if op.Op == "test" || seen[path] || (path == "" && op.From == "") {
+					continue
+				}
+
+				seen[path] = true
+				if ExistsBytes(doc, path) {
+					paths = append(paths, path)
+				}
+			}
+		}
+	}
+
+	tests, err := TestOperations(doc, paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(tests, ops...), nil
+}
+
+func applyOperation(doc []byte, op PatchOperation) ([]byte, error) {
+	segments := SplitPath(op.Path)
+	if op.Path != "" && op.Path[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON Pointer %q", op.Path)
+	}
+
+	switch op.Op {
+	case "add":
+		raw, err := patchValue(op)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, segments, raw)
+
+	case "remove":
+		return patchRemove(doc, segments)
+
+	case "replace":
+		raw, err := patchValue(op)
+		if err != nil {
+			return nil, err
+		}
+
+		start, end, err := locate(doc, segments)
+		if err != nil {
+			return nil, err
+		}
+		return splice(doc, start, end, raw), nil
+
+	case "move", "copy":
+		from := SplitPath(op.From)
+		start, end, err := locate(doc, from)
+		if err != nil {
+			return nil, fmt.Errorf("from %q: %w", op.From, err)
+		}
+
+		raw := append([]byte(nil), doc[start:end]...)
+		if op.Op == "move" {
+			if isPathPrefix(from, segments) && len(from) < len(segments) {
+				return nil, fmt.Errorf("cannot move %q into its own child", op.From)
+			}
+
+			if doc, err = patchRemove(doc, from); err != nil {
+				return nil, err
+			}
+		}
+		return patchAdd(doc, segments, raw)
+
+	case "test":
+		raw, err := patchValue(op)
+		if err != nil {
+			return nil, err
+		}
+
+		start, end, err := locate(doc, segments)
+		if err != nil {
+			return nil, err
+		}
+
+		equal, err := jsonEqual(doc[start:end], raw)
+		if err != nil {
+			return nil, err
+		}
+
+		if !equal {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	}
+
+	return nil, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+func patchValue(op PatchOperation) ([]byte, error) {
+	if len(op.Value) == 0 {
+		return nil, errors.New("missing value")
+	}
+
+	if err := Validate(op.Value); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSpace(op.Value), nil
+}
+
+// patchAdd add raw on segments: replace the object member, or insert into array before the index ("-" to append).
+func patchAdd(doc []byte, segments []string, raw []byte) ([]byte, error) {
+	if len(segments) == 0 {
+		return raw, nil
+	}
+
+	parent, seg := segments[:len(segments)-1], segments[len(segments)-1]
+	parentStart, _, err := locate(doc, parent)
+	if err != nil {
+		return nil, err
+	}
+
+	switch doc[parentStart] {
+	case '{':
+		return SetBytes(doc, JoinPointer(segments), json.RawMessage(raw))
+
+	case '[':
+		length, insertAt := 0, -1
+		idx, convErr := strconv.Atoi(seg)
+		_, _ = scanArray(doc, parentStart, func(n, s, e int) bool {
+			length = n + 1
+			if n == idx {
+				insertAt = s
+			}
+			return true
+		})
+
+		switch {
+		case seg == "-" || (convErr == nil && idx == length):
+			return SetBytes(doc, JoinPointer(append(parent[:len(parent):len(parent)], strconv.Itoa(length))), json.RawMessage(raw))
+		case convErr != nil || insertAt < 0 || strconv.Itoa(idx) != seg:
+			return nil, fmt.Errorf("array index %s out of range, array length is %d", seg, length)
+		}
+
+		return splice(doc, insertAt, insertAt, append(raw[:len(raw):len(raw)], ',')), nil
+	}
+
+	return nil, fmt.Errorf("cannot add %q, parent value is not object or array", JoinPointer(segments))
+}
+
+func patchRemove(doc []byte, segments []string) ([]byte, error) {
+	if _, _, err := locate(doc, segments); err != nil {
+		return nil, err
+	}
+
+	return DeleteBytes(doc, JoinPointer(segments))
+}
+
+func isPathPrefix(prefix, segments []string) bool {
+	if len(prefix) > len(segments) {
+		return false
+	}
+
+	for i := range prefix {
+		if prefix[i] != segments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// jsonEqual compare two JSON values as RFC 6902 test: object member order does not matter
+// and numbers are equal when numerically equal, i.e: 1 and 1.0.
+func jsonEqual(a, b []byte) (bool, error) {
+	var va, vb interface{}
+	if err := decodeDocument(a, &va); err != nil {
+		return false, err
+	}
+
+	if err := decodeDocument(b, &vb); err != nil {
+		return false, err
+	}
+
+	return valueEqual(va, vb), nil
+}
+
+func valueEqual(a, b interface{}) bool {
+	switch va := a.(type) {
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+
+		for key, val := range va {
+			other, exists := vb[key]
+			if !exists || !valueEqual(val, other) {
+				return false
+			}
+		}
+		return true
+
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+
+		for i := range va {
+			if !valueEqual(va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+
+	case json.Number:
+		vb, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+
+		ra, okA := new(big.Rat).SetString(va.String())
+		rb, okB := new(big.Rat).SetString(vb.String())
+		return okA && okB && ra.Cmp(rb) == 0
+	}
+
+	return a == b
+}
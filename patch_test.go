@@ -0,0 +1,193 @@
+package jsonutil_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestApplyPatch(t *testing.T) {
+	const doc = `{"name": "john", "tags": ["a", "b"], "address": {"city": "x"}, "score": 1.0}`
+
+	testCases := []struct {
+		Name   string
+		Patch  string
+		Expect string
+	}{
+		{
+			Name:   "add member",
+			Patch:  `[{"op":"add","path":"/age","value":20}]`,
+			Expect: `{"name": "john", "tags": ["a", "b"], "address": {"city": "x"}, "score": 1.0,"age":20}`,
+		},
+		{
+			Name:   "add replace existing member",
+			Patch:  `[{"op":"add","path":"/name","value":"jane"}]`,
+			Expect: `{"name": "jane", "tags": ["a", "b"], "address": {"city": "x"}, "score": 1.0}`,
+		},
+		{
+			Name:   "add insert into array",
+			Patch:  `[{"op":"add","path":"/tags/1","value":"c"}, {"op":"add","path":"/tags/-","value":"d"}, {"op":"add","path":"/tags/4","value":"e"}]`,
+			Expect: `{"name": "john", "tags": ["a", "c","b","d","e"], "address": {"city": "x"}, "score": 1.0}`,
+		},
+		{
+			Name:   "remove and replace",
+			Patch:  `[{"op":"remove","path":"/tags/0"}, {"op":"replace","path":"/address/city","value":"y"}]`,
+			Expect: `{"name": "john", "tags": ["b"], "address": {"city": "y"}, "score": 1.0}`,
+		},
+		{
+			Name:   "move and copy",
+			Patch:  `[{"op":"copy","from":"/name","path":"/address/owner"}, {"op":"move","from":"/score","path":"/address/score"}]`,
+			Expect: `{"name": "john", "tags": ["a", "b"], "address": {"city": "x","owner":"john","score":1.0}}`,
+		},
+		{
+			Name:   "test numerically equal and replace root",
+			Patch:  `[{"op":"test","path":"/score","value":1}, {"op":"test","path":"/address","value":{"city":"x"}}, {"op":"replace","path":"","value":[]}]`,
+			Expect: `[]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var ops []jsonutil.PatchOperation
+			assert.NoError(t, json.Unmarshal([]byte(tc.Patch), &ops))
+
+			out, err := jsonutil.ApplyPatch([]byte(doc), ops)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Expect, string(out))
+		})
+	}
+}
+
+func TestApplyPatch_EmptyKey(t *testing.T) {
+	// RFC 6901: "/" is the member with empty key, only "" is the whole document
+	patch := func(doc, ops string) (string, error) {
+		var patch []jsonutil.PatchOperation
+		assert.NoError(t, json.Unmarshal([]byte(ops), &patch))
+
+		out, err := jsonutil.ApplyPatch([]byte(doc), patch)
+		return string(out), err
+	}
+
+	out, err := patch(`{"a":1}`, `[{"op":"add","path":"/","value":2}]`)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":1,"":2}`, out)
+
+	out, err = patch(`{"":{"":1},"a":1}`, `[{"op":"test","path":"//","value":1}, {"op":"replace","path":"//","value":3}, {"op":"remove","path":"/a"}]`)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"":{"":3}}`, out)
+
+	out, err = patch(`{"":1,"a":1}`, `[{"op":"remove","path":"/"}]`)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"a":1}`, out)
+
+	_, err = patch(`{"a":1}`, `[{"op":"replace","path":"/","value":2}]`)
+	assert.Error(t, err)
+}
+
+func TestApplyPatch_ValueNotModified(t *testing.T) {
+	// the value followed by whitespace is inserted without it, the whitespace must stay in the caller's buffer
+	ops := []jsonutil.PatchOperation{{Op: "add", Path: "/0", Value: json.RawMessage(`"a" `)}}
+	out, err := jsonutil.ApplyPatch([]byte(`["b"]`), ops)
+	assert.NoError(t, err)
+	assert.Equal(t, `["a","b"]`, string(out))
+	assert.Equal(t, `"a" `, string(ops[0].Value))
+}
+
+func TestApplyPatch_Error(t *testing.T) {
+	const doc = `{"a": {"b": 1}, "list": [1]}`
+
+	testCases := []struct {
+		Patch string
+		Error string
+	}{
+		{
+			Patch: `[{"op":"add","path":"/a/c","value":1}, {"op":"test","path":"/a/b","value":2}]`,
+			Error: `jsonutil: patch operation 1 (test "/a/b"): jsonutil: patch test failed`,
+		},
+		{
+			Patch: `[{"op":"remove","path":"/x"}]`,
+			Error: `jsonutil: patch operation 0 (remove "/x"): jsonutil: path not found`,
+		},
+		{
+			Patch: `[{"op":"replace","path":"/x","value":1}]`,
+			Error: `jsonutil: patch operation 0 (replace "/x"): jsonutil: path not found`,
+		},
+		{
+			Patch: `[{"op":"add","path":"/list/5","value":1}]`,
+			Error: `jsonutil: patch operation 0 (add "/list/5"): array index 5 out of range, array length is 1`,
+		},
+		{
+			Patch: `[{"op":"add","path":"/x/y","value":1}]`,
+			Error: `jsonutil: patch operation 0 (add "/x/y"): jsonutil: path not found`,
+		},
+		{
+			Patch: `[{"op":"move","from":"/a","path":"/a/b/c"}]`,
+			Error: `jsonutil: patch operation 0 (move "/a/b/c"): cannot move "/a" into its own child`,
+		},
+		{
+			Patch: `[{"op":"add","path":"a"}]`,
+			Error: `jsonutil: patch operation 0 (add "a"): invalid JSON Pointer "a"`,
+		},
+		{
+			Patch: `[{"op":"add","path":"/a"}]`,
+			Error: `jsonutil: patch operation 0 (add "/a"): missing value`,
+		},
+		{
+			Patch: `[{"op":"merge","path":"/a"}]`,
+			Error: `jsonutil: patch operation 0 (merge "/a"): unknown operation "merge"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		var ops []jsonutil.PatchOperation
+		assert.NoError(t, json.Unmarshal([]byte(tc.Patch), &ops))
+
+		out, err := jsonutil.ApplyPatch([]byte(doc), ops)
+		assert.EqualError(t, err, tc.Error)
+		assert.Nil(t, out)
+	}
+
+	_, err := jsonutil.ApplyPatch([]byte(`{`), nil)
+	assert.ErrorIs(t, err, jsonutil.ErrUnexpectedEnd)
+}
+
+func TestGuardPatch(t *testing.T) {
+	stored := []byte(`{"version": 1, "user": {"name": "john", "email": "john@example.com"}}`)
+
+	ops := []jsonutil.PatchOperation{
+		{Op: "replace", Path: "/user/email", Value: json.RawMessage(`"new@example.com"`)},
+		{Op: "add", Path: "/user/phone", Value: json.RawMessage(`"123"`)},
+	}
+
+	guarded, err := jsonutil.GuardPatch(stored, ops)
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonutil.PatchOperation{
+		{Op: "test", Path: "/user/email", Value: json.RawMessage(`"john@example.com"`)},
+		ops[0], ops[1],
+	}, guarded)
+
+	guarded, err = jsonutil.GuardPatch(stored, ops, "version")
+	assert.NoError(t, err)
+	assert.Equal(t, jsonutil.PatchOperation{Op: "test", Path: "/version", Value: json.RawMessage(`1`)}, guarded[0])
+
+	out, err := jsonutil.ApplyPatch(stored, guarded)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"version": 1, "user": {"name": "john", "email": "new@example.com", "phone": "123"}}`, string(out))
+
+	// concurrent writer changed the version, the guarded patch is rejected
+	changed, err := jsonutil.SetBytes(stored, "version", 2)
+	assert.NoError(t, err)
+
+	_, err = jsonutil.ApplyPatch(changed, guarded)
+	assert.True(t, errors.Is(err, jsonutil.ErrTestFailed))
+
+	var patchErr *jsonutil.PatchError
+	assert.True(t, errors.As(err, &patchErr))
+	assert.Equal(t, 0, patchErr.Index)
+
+	_, err = jsonutil.TestOperations(stored, "user.age")
+	assert.EqualError(t, err, `jsonutil: test operation "/user/age": jsonutil: path not found`)
+}
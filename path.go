@@ -0,0 +1,158 @@
+package jsonutil
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PathInfo describe single leaf inside a JSON document.
+type PathInfo struct {
+	Path    string // Path in dotted form, i.e: items.0.name
+	Pointer string // Pointer in JSON Pointer (RFC 6901) form, i.e: /items/0/name
+	Type    Type
+}
+
+// Paths return every leaf path in doc using dotted form, sorted alphabetically.
+// Leaf is any string, number, boolean, null, or empty object and array.
+// Array element is written as its index, i.e: {"items":[{"name":"a"}]} return items.0.name.
+func Paths(doc []byte) ([]string, error) {
+	infos, err := PathTypes(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, len(infos))
+	for i, info := range infos {
+		paths[i] = info.Path
+	}
+
+	return paths, nil
+}
+
+// PathTypes is like Paths but also return the JSON Pointer form and value type of each leaf.
+func PathTypes(doc []byte) ([]PathInfo, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, err
+	}
+
+	infos := make([]PathInfo, 0)
+	walkLeaves(data, []string{}, func(segments []string, v interface{}) {
+		infos = append(infos, PathInfo{
+			Path:    JoinPath(segments),
+			Pointer: JoinPointer(segments),
+			Type:    typeOf(v),
+		})
+	})
+
+	return infos, nil
+}
+
+// walkLeaves call fn for every leaf of decoded data, in the order of Paths.
+func walkLeaves(data interface{}, segments []string, fn func(segments []string, v interface{})) {
+	switch v := data.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 && len(segments) > 0 {
+			break
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			walkLeaves(v[key], append(segments, key), fn)
+		}
+		return
+
+	case []interface{}:
+		if len(v) == 0 && len(segments) > 0 {
+			break
+		}
+
+		for i, val := range v {
+			walkLeaves(val, append(segments, strconv.Itoa(i)), fn)
+		}
+		return
+	}
+
+	fn(segments, data)
+}
+
+// JoinPath join path segments into dotted form.
+// Dot and backslash inside the segment is escaped using backslash, i.e: ["a.b", "c"] become a\.b.c,
+// so does the leading slash which otherwise read as JSON Pointer by SplitPath.
+// The single empty key has no dotted form since empty path is the whole document, it is returned as JSON Pointer "/".
+func JoinPath(segments []string) string {
+	if len(segments) == 1 && segments[0] == "" {
+		return "/"
+	}
+
+	escaped := make([]string, len(segments))
+	for i, seg := range segments {
+		seg = strings.ReplaceAll(seg, `\`, `\\`)
+		escaped[i] = strings.ReplaceAll(seg, `.`, `\.`)
+	}
+
+	if len(escaped) > 0 && strings.HasPrefix(escaped[0], "/") {
+		escaped[0] = `\` + escaped[0]
+	}
+
+	return strings.Join(escaped, ".")
+}
+
+// JoinPointer join path segments into JSON Pointer (RFC 6901) form.
+func JoinPointer(segments []string) string {
+	var sb strings.Builder
+	for _, seg := range segments {
+		seg = strings.ReplaceAll(seg, "~", "~0")
+		seg = strings.ReplaceAll(seg, "/", "~1")
+		sb.WriteString("/")
+		sb.WriteString(seg)
+	}
+
+	return sb.String()
+}
+
+// SplitPath split path into its segments.
+// When path starts with "/" it is parsed as JSON Pointer, otherwise as dotted form (see JoinPath).
+// Only empty path means the whole document and return empty segments, "/" is the empty key as in RFC 6901.
+func SplitPath(path string) []string {
+	if path == "" {
+		return []string{}
+	}
+
+	if strings.HasPrefix(path, "/") {
+		parts := strings.Split(path[1:], "/")
+		for i, part := range parts {
+			part = strings.ReplaceAll(part, "~1", "/")
+			parts[i] = strings.ReplaceAll(part, "~0", "~")
+		}
+
+		return parts
+	}
+
+	segments := make([]string, 0)
+	var sb strings.Builder
+	escaped := false
+	for _, r := range path {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '.':
+			segments = append(segments, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	segments = append(segments, sb.String())
+	return segments
+}
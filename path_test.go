@@ -0,0 +1,46 @@
+package jsonutil_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestPaths(t *testing.T) {
+	in := `{"user":{"name":"a","tags":["x",1]},"a.b":null,"empty":{},"list":[],"ok":true}`
+	paths, err := jsonutil.Paths([]byte(in))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`a\.b`, "empty", "list", "ok", "user.name", "user.tags.0", "user.tags.1"}, paths)
+
+	_, err = jsonutil.Paths([]byte(`{"a":`))
+	assert.Error(t, err)
+}
+
+func TestPathTypes(t *testing.T) {
+	infos, err := jsonutil.PathTypes([]byte(`{"a/b":[{"c":1.5}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonutil.PathInfo{
+		{Path: "a/b.0.c", Pointer: "/a~1b/0/c", Type: jsonutil.Number},
+	}, infos)
+	assert.Equal(t, "number", infos[0].Type.String())
+}
+
+func TestSplitPath(t *testing.T) {
+	assert.Equal(t, []string{}, jsonutil.SplitPath(""))
+	assert.Equal(t, []string{""}, jsonutil.SplitPath("/"))
+	assert.Equal(t, []string{"", ""}, jsonutil.SplitPath("//"))
+	assert.Equal(t, []string{"a", ""}, jsonutil.SplitPath("/a/"))
+	assert.Equal(t, []string{"a.b", "0", "c"}, jsonutil.SplitPath(`a\.b.0.c`))
+	assert.Equal(t, []string{"a/b", "~c"}, jsonutil.SplitPath("/a~1b/~0c"))
+
+	segments := []string{`a.b\`, "c"}
+	assert.Equal(t, segments, jsonutil.SplitPath(jsonutil.JoinPath(segments)))
+	assert.Equal(t, segments, jsonutil.SplitPath(jsonutil.JoinPointer(segments)))
+
+	// RFC 6901 empty key
+	for _, segments := range [][]string{{}, {""}, {"", ""}, {"a", ""}, {"/a", "b"}, {"", "a"}} {
+		assert.Equal(t, segments, jsonutil.SplitPath(jsonutil.JoinPath(segments)), segments)
+		assert.Equal(t, segments, jsonutil.SplitPath(jsonutil.JoinPointer(segments)), segments)
+	}
+}
@@ -0,0 +1,44 @@
+package jsonutil
+
+import (
+	"context"
+	"sort"
+)
+
+// Change is one value which would be changed by Transformer, see Plan.
+type Change struct {
+	Path string      `json:"path"` // Path is in JSON Pointer form, i.e: /items/0/token
+	Key  string      `json:"key"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+// Plan is the dry-run of Transform: it return every change the config would make on data, sorted by the path,
+// without producing the output. The data is never modified, even with Config.InPlace.
+// Value changed by more than one step (i.e: StringTransformer then Detectors) has one Change for every step.
+func (m *Transformer) Plan(ctx context.Context, data interface{}) ([]Change, error) {
+	changes := make([]Change, 0)
+	planning := m.withHook(func(info KVInfo, transformer interface{}, before, after interface{}) {
+		if sameValue(before, after) {
+			return
+		}
+
+		changes = append(changes, Change{
+			Path: JoinPointer(info.Path),
+			Key:  info.Key,
+			Old:  before,
+			New:  after,
+		})
+	})
+	planning.Config.InPlace = false
+
+	if _, err := planning.Transform(ctx, data); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path < changes[j].Path
+	})
+
+	return changes, nil
+}
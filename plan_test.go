@@ -0,0 +1,47 @@
+package jsonutil_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestTransformer_Plan(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		Keys: map[string]jsonutil.StringTransformer{
+			"password": func(ctx context.Context, info jsonutil.KVInfo) string { return "***" },
+		},
+		ValueTransformer: func(ctx context.Context, info jsonutil.KVInfo, value interface{}) interface{} {
+			if info.Key == "pin" {
+				return nil
+			}
+			return value
+		},
+		Detectors: []jsonutil.Detector{jsonutil.DetectEmail},
+		InPlace:   true,
+	})
+
+	var data interface{}
+	doc := `{"users":[{"password":"a","email":"john@example.com","name":"john"}],"pin":1234,"password":"***"}`
+	assert.NoError(t, json.Unmarshal([]byte(doc), &data))
+
+	changes, err := tr.Plan(context.Background(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonutil.Change{
+		{Path: "/pin", Key: "pin", Old: float64(1234), New: nil},
+		{Path: "/users/0/email", Key: "email", Old: "john@example.com", New: "[email]"},
+		{Path: "/users/0/password", Key: "password", Old: "a", New: "***"},
+	}, changes)
+
+	// data is not modified
+	b, err := json.Marshal(data)
+	assert.NoError(t, err)
+	assert.JSONEq(t, doc, string(b))
+
+	changes, err = tr.Plan(context.Background(), map[string]interface{}{"name": "john"})
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,83 @@
+package jsonutil
+
+// RootKind is the kind of the top-level JSON value reported by Probe.
+type RootKind int
+
+const (
+	RootUnknown RootKind = iota
+	RootObject
+	RootArray
+	RootScalar // string, number, boolean or null
+)
+
+func (k RootKind) String() string {
+	switch k {
+	case RootObject:
+		return "object"
+	case RootArray:
+		return "array"
+	case RootScalar:
+		return "scalar"
+	}
+
+	return "unknown"
+}
+
+// utf8BOM is skipped by Probe, some clients prepend it to the body.
+const utf8BOM = "\xef\xbb\xbf"
+
+// Probe cheaply check whether b looks like JSON and return the kind of its root value.
+// It only looks at the first and last non-whitespace bytes (and the whole value for number and literal),
+// so it never allocates and run in constant time for object, array and string.
+// The result is a hint, b may still be invalid JSON when it returns true, use Validate to be sure.
+func Probe(b []byte) (RootKind, bool) {
+	start := skipSpace(b, 0)
+	if len(b)-start >= len(utf8BOM) && string(b[start:start+len(utf8BOM)]) == utf8BOM {
+		start = skipSpace(b, start+len(utf8BOM))
+	}
+
+	end := len(b)
+	for end > start && isSpace(b[end-1]) {
+		end--
+	}
+
+	if start >= end {
+		return RootUnknown, false
+	}
+
+	first, last := b[start], b[end-1]
+	switch {
+	case first == '{':
+		// the first member must start with the key
+		next := skipSpace(b, start+1)
+		if last != '}' || end-start < 2 || (b[next] != '"' && b[next] != '}') {
+			return RootUnknown, false
+		}
+		return RootObject, true
+
+	case first == '[':
+		if last != ']' || end-start < 2 {
+			return RootUnknown, false
+		}
+		return RootArray, true
+
+	case first == '"':
+		if last != '"' || end-start < 2 {
+			return RootUnknown, false
+		}
+		return RootScalar, true
+
+	case first == '-' || (first >= '0' && first <= '9'):
+		if n, err := scanNumber(b[:end], start); err != nil || n != end {
+			return RootUnknown, false
+		}
+		return RootScalar, true
+	}
+
+	switch string(b[start:end]) {
+	case "true", "false", "null":
+		return RootScalar, true
+	}
+
+	return RootUnknown, false
+}
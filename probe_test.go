@@ -0,0 +1,51 @@
+package jsonutil_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		Input string
+		Kind  jsonutil.RootKind
+		OK    bool
+	}{
+		{Input: `{"a": 1}`, Kind: jsonutil.RootObject, OK: true},
+		{Input: " \n{ }\n", Kind: jsonutil.RootObject, OK: true},
+		{Input: "\xef\xbb\xbf{\"a\": 1}", Kind: jsonutil.RootObject, OK: true},
+		{Input: `[1, 2]`, Kind: jsonutil.RootArray, OK: true},
+		{Input: `"abc"`, Kind: jsonutil.RootScalar, OK: true},
+		{Input: `-1.5e10`, Kind: jsonutil.RootScalar, OK: true},
+		{Input: `true`, Kind: jsonutil.RootScalar, OK: true},
+		{Input: ` null `, Kind: jsonutil.RootScalar, OK: true},
+		{Input: ``, Kind: jsonutil.RootUnknown, OK: false},
+		{Input: `   `, Kind: jsonutil.RootUnknown, OK: false},
+		{Input: `{a: 1}`, Kind: jsonutil.RootUnknown, OK: false},
+		{Input: `{"a": 1`, Kind: jsonutil.RootUnknown, OK: false},
+		{Input: `[1, 2`, Kind: jsonutil.RootUnknown, OK: false},
+		{Input: `"abc`, Kind: jsonutil.RootUnknown, OK: false},
+		{Input: `"`, Kind: jsonutil.RootUnknown, OK: false},
+		{Input: `12abc`, Kind: jsonutil.RootUnknown, OK: false},
+		{Input: `nil`, Kind: jsonutil.RootUnknown, OK: false},
+		{Input: `<html></html>`, Kind: jsonutil.RootUnknown, OK: false},
+		{Input: `name=alice`, Kind: jsonutil.RootUnknown, OK: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Input, func(t *testing.T) {
+			kind, ok := jsonutil.Probe([]byte(tc.Input))
+			assert.Equal(t, tc.Kind, kind)
+			assert.Equal(t, tc.OK, ok)
+		})
+	}
+}
+
+func TestRootKind_String(t *testing.T) {
+	assert.Equal(t, "object", jsonutil.RootObject.String())
+	assert.Equal(t, "array", jsonutil.RootArray.String())
+	assert.Equal(t, "scalar", jsonutil.RootScalar.String())
+	assert.Equal(t, "unknown", jsonutil.RootUnknown.String())
+}
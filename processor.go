@@ -0,0 +1,101 @@
+package jsonutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Processor is a single step processing JSON document, such as masking or truncation.
+type Processor interface {
+	Process(ctx context.Context, doc []byte) ([]byte, error)
+}
+
+// ProcessorFunc is an adapter to use ordinary function as Processor.
+type ProcessorFunc func(ctx context.Context, doc []byte) ([]byte, error)
+
+func (f ProcessorFunc) Process(ctx context.Context, doc []byte) ([]byte, error) {
+	return f(ctx, doc)
+}
+
+var _ Processor = (*Transformer)(nil)
+var _ Processor = (*Sanitizer)(nil)
+var _ Processor = (*RuleSet)(nil)
+var _ Processor = (*Pipeline)(nil)
+var _ Processor = ProcessorFunc(nil)
+
+// ErrStopPipeline can be returned by a Processor to stop the Pipeline.
+// The document returned along with it becomes the Pipeline result, and no error is returned.
+var ErrStopPipeline = errors.New("jsonutil: stop pipeline")
+
+// StageError is the error returned by one of the Pipeline stage.
+type StageError struct {
+	Stage     int // Stage is zero-based index of the processor.
+	Processor Processor
+	Err       error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("jsonutil: pipeline stage %d (%T): %v", e.Stage, e.Processor, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+// PartialError is returned along with the partially processed output when the ctx is cancelled,
+// see SanitizerConfig.PartialOnCancel.
+// The output is the processed prefix of the document, it is not a valid JSON.
+type PartialError struct {
+	Offset int // Offset is the input offset where the processing stops.
+	Err    error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("jsonutil: partial result, stopped at offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// Pipeline run the processors in order, output of one processor is the input of the next one.
+type Pipeline struct {
+	processors []Processor
+}
+
+// NewPipeline return Pipeline of processors, i.e: NewPipeline(masking, truncation).
+func NewPipeline(processors ...Processor) *Pipeline {
+	return &Pipeline{processors: processors}
+}
+
+// Processors return the pipeline stages.
+func (p *Pipeline) Processors() []Processor {
+	return p.processors
+}
+
+// Process run doc through every processor.
+// The error of a stage is wrapped as *StageError.
+// When the stage error is *PartialError, the partial output of that stage is returned along with the error.
+func (p *Pipeline) Process(ctx context.Context, doc []byte) ([]byte, error) {
+	out := doc
+	for i, processor := range p.processors {
+		next, err := processor.Process(ctx, out)
+		if err == ErrStopPipeline {
+			return next, nil
+		}
+
+		if err != nil {
+			var partial *PartialError
+			if !errors.As(err, &partial) {
+				next = nil
+			}
+
+			return next, &StageError{Stage: i, Processor: processor, Err: err}
+		}
+
+		out = next
+	}
+
+	return out, nil
+}
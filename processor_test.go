@@ -0,0 +1,69 @@
+package jsonutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestPipeline(t *testing.T) {
+	mask := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{MaskKeys: []string{"password"}})
+	truncate := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{MaxChars: 3})
+
+	p := jsonutil.NewPipeline(mask, truncate)
+	out, err := p.Process(context.Background(), []byte(`{"password":"secret","name":"alice"}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"password":"***","name":"ali **escaped 2 chars**"}`, string(out))
+}
+
+func TestPipeline_Empty(t *testing.T) {
+	in := []byte(`{"a":1}`)
+	out, err := jsonutil.NewPipeline().Process(context.Background(), in)
+	assert.NoError(t, err)
+	assert.Equal(t, string(in), string(out))
+}
+
+func TestPipeline_StageError(t *testing.T) {
+	errBoom := errors.New("boom")
+	fail := jsonutil.ProcessorFunc(func(ctx context.Context, doc []byte) ([]byte, error) {
+		return nil, errBoom
+	})
+
+	p := jsonutil.NewPipeline(jsonutil.NewTransformer(jsonutil.Config{}), fail)
+	out, err := p.Process(context.Background(), []byte(`{}`))
+	assert.Nil(t, out)
+	assert.ErrorIs(t, err, errBoom)
+
+	var stageErr *jsonutil.StageError
+	assert.True(t, errors.As(err, &stageErr))
+	assert.Equal(t, 1, stageErr.Stage)
+}
+
+func TestPipeline_Stop(t *testing.T) {
+	called := false
+	stop := jsonutil.ProcessorFunc(func(ctx context.Context, doc []byte) ([]byte, error) {
+		return []byte(`{"stopped":true}`), jsonutil.ErrStopPipeline
+	})
+	next := jsonutil.ProcessorFunc(func(ctx context.Context, doc []byte) ([]byte, error) {
+		called = true
+		return doc, nil
+	})
+
+	out, err := jsonutil.NewPipeline(stop, next).Process(context.Background(), []byte(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"stopped":true}`, string(out))
+	assert.False(t, called)
+}
+
+func TestPipeline_Partial(t *testing.T) {
+	s := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{PartialOnCancel: true})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out, err := jsonutil.NewPipeline(s).Process(ctx, []byte(`[1,2]`))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, `[`, string(out))
+}
@@ -0,0 +1,74 @@
+package jsonutil
+
+import (
+	"io"
+	"os"
+)
+
+// ProgressFunc is called with the number of input bytes processed so far and the total input size.
+// totalBytes is -1 when the size of the input is unknown.
+type ProgressFunc func(bytesProcessed, totalBytes int64)
+
+// DefaultProgressInterval is the minimum number of processed bytes between two ProgressFunc calls.
+const DefaultProgressInterval = 1 << 20
+
+type progress struct {
+	fn       ProgressFunc
+	total    int64
+	interval int64
+	last     int64
+	done     bool
+}
+
+func newProgress(fn ProgressFunc, total, interval int64) *progress {
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+
+	return &progress{fn: fn, total: total, interval: interval}
+}
+
+// update call fn when at least interval bytes is processed since the last call.
+func (p *progress) update(n int64) {
+	if p == nil || p.done || n-p.last < p.interval {
+		return
+	}
+
+	p.last = n
+	p.fn(n, p.total)
+}
+
+// finish call fn for the last time, so the caller always see the final number.
+func (p *progress) finish(n int64) {
+	if p == nil || p.done {
+		return
+	}
+
+	p.done = true
+	p.last = n
+	p.fn(n, p.total)
+}
+
+// readerSize return the remaining size of r when it is known, otherwise -1.
+func readerSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		// bytes.Reader, strings.Reader and bytes.Buffer
+		return int64(v.Len())
+
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return -1
+		}
+
+		pos, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+
+		return info.Size() - pos
+	}
+
+	return -1
+}
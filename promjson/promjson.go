@@ -0,0 +1,118 @@
+// Package promjson export jsonutil.Metrics as Prometheus metrics.
+package promjson
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+// Config is the configuration of Metrics.
+type Config struct {
+	// Namespace and Subsystem is the prefix of every metric name, Subsystem default to "jsonutil".
+	Namespace string
+	Subsystem string
+
+	// ConstLabels is added to every metric, i.e: to distinguish the pipelines.
+	ConstLabels prometheus.Labels
+
+	// LatencyBuckets default to prometheus.DefBuckets.
+	LatencyBuckets []float64
+	// SizeBuckets default to exponential buckets from 128 bytes to 8MB.
+	SizeBuckets []float64
+}
+
+// Metrics implements jsonutil.Metrics using Prometheus counters and histograms.
+type Metrics struct {
+	documents      prometheus.Counter
+	fieldsMasked   prometheus.Counter
+	bytesTruncated prometheus.Counter
+	errors         prometheus.Counter
+	latency        prometheus.Histogram
+	size           prometheus.Histogram
+}
+
+var _ jsonutil.Metrics = (*Metrics)(nil)
+var _ prometheus.Collector = (*Metrics)(nil)
+
+// NewMetrics return Metrics, register it using prometheus.Registerer.MustRegister before use.
+func NewMetrics(conf Config) *Metrics {
+	if conf.Subsystem == "" {
+		conf.Subsystem = "jsonutil"
+	}
+
+	if len(conf.LatencyBuckets) == 0 {
+		conf.LatencyBuckets = prometheus.DefBuckets
+	}
+
+	if len(conf.SizeBuckets) == 0 {
+		conf.SizeBuckets = prometheus.ExponentialBuckets(128, 4, 10)
+	}
+
+	counter := func(name, help string) prometheus.Counter {
+		return prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   conf.Namespace,
+			Subsystem:   conf.Subsystem,
+			Name:        name,
+			Help:        help,
+			ConstLabels: conf.ConstLabels,
+		})
+	}
+
+	histogram := func(name, help string, buckets []float64) prometheus.Histogram {
+		return prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   conf.Namespace,
+			Subsystem:   conf.Subsystem,
+			Name:        name,
+			Help:        help,
+			ConstLabels: conf.ConstLabels,
+			Buckets:     buckets,
+		})
+	}
+
+	return &Metrics{
+		documents:      counter("documents_processed_total", "Number of processed JSON documents."),
+		fieldsMasked:   counter("fields_masked_total", "Number of masked JSON values."),
+		bytesTruncated: counter("bytes_truncated_total", "Number of bytes removed by truncation."),
+		errors:         counter("errors_total", "Number of JSON documents failed to be processed."),
+		latency:        histogram("processing_duration_seconds", "Latency of processing one JSON document.", conf.LatencyBuckets),
+		size:           histogram("document_size_bytes", "Size of the processed JSON document.", conf.SizeBuckets),
+	}
+}
+
+func (m *Metrics) DocumentProcessed(size int, latency time.Duration) {
+	m.documents.Inc()
+	m.size.Observe(float64(size))
+	m.latency.Observe(latency.Seconds())
+}
+
+func (m *Metrics) FieldsMasked(n int) {
+	m.fieldsMasked.Add(float64(n))
+}
+
+func (m *Metrics) BytesTruncated(n int) {
+	m.bytesTruncated.Add(float64(n))
+}
+
+func (m *Metrics) Error(error) {
+	m.errors.Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.documents, m.fieldsMasked, m.bytesTruncated, m.errors, m.latency, m.size}
+}
@@ -0,0 +1,47 @@
+package promjson_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"github.com/yusufsyaifudin/jsonutil/promjson"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := promjson.NewMetrics(promjson.Config{Namespace: "app"})
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(metrics)
+
+	s := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{
+		MaskKeys: []string{"password"},
+		MaxChars: 3,
+		Metrics:  metrics,
+	})
+
+	_, err := s.Sanitize(context.Background(), []byte(`{"password": "secret", "name": "alice"}`))
+	assert.NoError(t, err)
+
+	_, err = s.Sanitize(context.Background(), []byte(`{`))
+	assert.Error(t, err)
+
+	expected := `
+# HELP app_jsonutil_documents_processed_total Number of processed JSON documents.
+# TYPE app_jsonutil_documents_processed_total counter
+app_jsonutil_documents_processed_total 1
+# HELP app_jsonutil_errors_total Number of JSON documents failed to be processed.
+# TYPE app_jsonutil_errors_total counter
+app_jsonutil_errors_total 1
+# HELP app_jsonutil_fields_masked_total Number of masked JSON values.
+# TYPE app_jsonutil_fields_masked_total counter
+app_jsonutil_fields_masked_total 1
+`
+	err = testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"app_jsonutil_documents_processed_total", "app_jsonutil_errors_total", "app_jsonutil_fields_masked_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics, "app_jsonutil_document_size_bytes"))
+}
@@ -0,0 +1,286 @@
+package jsonutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type queryOptions struct {
+	inferTypes bool
+}
+
+// QueryOption configure FromQuery.
+type QueryOption func(*queryOptions)
+
+// InferQueryTypes convert "true" and "false" into boolean, "null" into null and numeric string into number.
+// Without this option, every value is string.
+func InferQueryTypes() QueryOption {
+	return func(o *queryOptions) {
+		o.inferTypes = true
+	}
+}
+
+// queryList is array being built by FromQuery, nil element is a hole of sparse index.
+type queryList struct {
+	elems []interface{}
+}
+
+// FromQuery convert query parameters into JSON object, supporting bracketed nesting:
+//
+//	filter[status]=active   {"filter":{"status":"active"}}
+//	ids[]=1&ids[]=2         {"ids":["1","2"]}
+//	items[0][name]=a        {"items":[{"name":"a"}]}
+//	tag=a&tag=b             {"tag":["a","b"]}
+//
+// Index is only used for ordering, the holes of sparse index is removed.
+// Conflicting parameters, i.e: a=1&a[b]=2, return error.
+func FromQuery(values url.Values, opts ...QueryOption) ([]byte, error) {
+	o := &queryOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	root := map[string]interface{}{}
+	for _, key := range keys {
+		vals := make([]interface{}, len(values[key]))
+		for i, v := range values[key] {
+			vals[i] = o.value(v)
+		}
+
+		segments := splitQueryKey(key)
+		val, err := insertQuery(root[segments[0]], segments[1:], vals)
+		if err != nil {
+			return nil, fmt.Errorf("jsonutil: query parameter %q: %w", key, err)
+		}
+		root[segments[0]] = val
+	}
+
+	return json.Marshal(finalizeQuery(root))
+}
+
+func (o *queryOptions) value(v string) interface{} {
+	if !o.inferTypes {
+		return v
+	}
+
+	switch v {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	if v != "" && (v[0] == '-' || (v[0] >= '0' && v[0] <= '9')) {
+		if end, err := scanNumber([]byte(v), 0); err == nil && end == len(v) {
+			return json.Number(v)
+		}
+	}
+
+	return v
+}
+
+// splitQueryKey split a[b][] into [a b ""], key with invalid brackets is returned as single segment.
+func splitQueryKey(key string) []string {
+	open := strings.IndexByte(key, '[')
+	if open <= 0 || !strings.HasSuffix(key, "]") {
+		return []string{key}
+	}
+
+	segments := []string{key[:open]}
+	rest := key[open:]
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 || strings.IndexByte(rest[1:end], '[') >= 0 {
+			return []string{key}
+		}
+
+		segments = append(segments, rest[1:end])
+		rest = rest[end+1:]
+	}
+
+	return segments
+}
+
+// insertQuery set vals on segments inside node, and return the new node.
+func insertQuery(node interface{}, segments []string, vals []interface{}) (interface{}, error) {
+	if len(segments) == 0 {
+		if node != nil {
+			return nil, fmt.Errorf("conflict with another parameter")
+		}
+
+		if len(vals) == 1 {
+			return vals[0], nil
+		}
+		return vals, nil
+	}
+
+	seg := segments[0]
+	if seg == "" || isQueryIndex(seg) {
+		list, ok := node.(*queryList)
+		if node == nil {
+			list, ok = &queryList{}, true
+		}
+
+		if !ok {
+			return nil, fmt.Errorf("conflict with another parameter")
+		}
+
+		// append every value as new element
+		if seg == "" {
+			for _, v := range vals {
+				elem, err := insertQuery(nil, segments[1:], []interface{}{v})
+				if err != nil {
+					return nil, err
+				}
+				list.elems = append(list.elems, elem)
+			}
+			return list, nil
+		}
+
+		idx, _ := strconv.Atoi(seg)
+		for len(list.elems) <= idx {
+			list.elems = append(list.elems, nil)
+		}
+
+		elem, err := insertQuery(list.elems[idx], segments[1:], vals)
+		if err != nil {
+			return nil, err
+		}
+		list.elems[idx] = elem
+		return list, nil
+	}
+
+	obj, ok := node.(map[string]interface{})
+	if node == nil {
+		obj, ok = map[string]interface{}{}, true
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("conflict with another parameter")
+	}
+
+	child, err := insertQuery(obj[seg], segments[1:], vals)
+	if err != nil {
+		return nil, err
+	}
+	obj[seg] = child
+	return obj, nil
+}
+
+// isQueryIndex return true for small non-negative integer, bigger number is treated as object key.
+func isQueryIndex(seg string) bool {
+	idx, err := strconv.Atoi(seg)
+	return err == nil && idx >= 0 && idx < 10000 && strconv.Itoa(idx) == seg
+}
+
+// finalizeQuery convert queryList into array without holes.
+func finalizeQuery(node interface{}) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			v[key] = finalizeQuery(child)
+		}
+		return v
+
+	case *queryList:
+		arr := make([]interface{}, 0, len(v.elems))
+		for _, elem := range v.elems {
+			if elem != nil {
+				arr = append(arr, finalizeQuery(elem))
+			}
+		}
+		return arr
+	}
+
+	return node
+}
+
+// ProcessForm sanitize the form-encoded body (application/x-www-form-urlencoded) using p:
+// the body is converted into JSON using FromQuery, processed, and converted back using ToQuery.
+// The form is re-encoded with sorted keys, and repeated key without brackets (tag=a&tag=b) come back as tag[]=a&tag[]=b.
+func ProcessForm(ctx context.Context, p Processor, body []byte) ([]byte, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("jsonutil: invalid form: %w", err)
+	}
+
+	doc, err := FromQuery(values)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := p.Process(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err = ToQuery(out)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(values.Encode()), nil
+}
+
+// ToQuery convert JSON object into query parameters, the reverse of FromQuery:
+// nested object become a[b]=v, array of scalar become a[]=v, and array of object or array become a[0][b]=v.
+// Number is written as is, null as empty string, and empty object or array is omitted.
+func ToQuery(doc []byte) (url.Values, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, err
+	}
+
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("jsonutil: query must be JSON object, got %s", typeOf(data))
+	}
+
+	values := url.Values{}
+	for key, v := range obj {
+		flattenQuery(values, key, v)
+	}
+
+	return values, nil
+}
+
+func flattenQuery(values url.Values, prefix string, v interface{}) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, child := range val {
+			flattenQuery(values, prefix+"["+key+"]", child)
+		}
+
+	case []interface{}:
+		for i, child := range val {
+			switch child.(type) {
+			case map[string]interface{}, []interface{}:
+				flattenQuery(values, prefix+"["+strconv.Itoa(i)+"]", child)
+			default:
+				flattenQuery(values, prefix+"[]", child)
+			}
+		}
+
+	case string:
+		values.Add(prefix, val)
+	case json.Number:
+		values.Add(prefix, val.String())
+	case bool:
+		values.Add(prefix, strconv.FormatBool(val))
+	case nil:
+		values.Add(prefix, "")
+	}
+}
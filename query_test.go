@@ -0,0 +1,105 @@
+package jsonutil_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestFromQuery(t *testing.T) {
+	tests := []struct {
+		Query  string
+		Opts   []jsonutil.QueryOption
+		Output string
+	}{
+		{Query: `a=1&b=x`, Output: `{"a":"1","b":"x"}`},
+		{Query: `filter[status]=active&filter[owner][id]=7`, Output: `{"filter":{"owner":{"id":"7"},"status":"active"}}`},
+		{Query: `ids[]=1&ids[]=2`, Output: `{"ids":["1","2"]}`},
+		{Query: `tag=a&tag=b`, Output: `{"tag":["a","b"]}`},
+		{Query: `items[1][name]=b&items[0][name]=a&items[0][qty]=2`, Output: `{"items":[{"name":"a","qty":"2"},{"name":"b"}]}`},
+		{Query: `items[5]=x&items[2]=y`, Output: `{"items":["y","x"]}`},
+		{Query: `a[][b]=1&a[][b]=2`, Output: `{"a":[{"b":"1"},{"b":"2"}]}`},
+		{Query: `weird[=1&x]=2`, Output: `{"weird[":"1","x]":"2"}`},
+		{Query: `n=-1.5&t=true&f=false&z=null&s=abc&e=`, Opts: []jsonutil.QueryOption{jsonutil.InferQueryTypes()}, Output: `{"e":"","f":false,"n":-1.5,"s":"abc","t":true,"z":null}`},
+		{Query: ``, Output: `{}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Query, func(t *testing.T) {
+			values, err := url.ParseQuery(tc.Query)
+			assert.NoError(t, err)
+
+			out, err := jsonutil.FromQuery(values, tc.Opts...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Output, string(out))
+		})
+	}
+}
+
+func TestFromQuery_Conflict(t *testing.T) {
+	for _, query := range []string{`a=1&a[b]=2`, `a[]=1&a[b]=2`, `a[b]=1&a[0]=2`} {
+		values, err := url.ParseQuery(query)
+		assert.NoError(t, err)
+
+		_, err = jsonutil.FromQuery(values)
+		assert.Error(t, err, query)
+	}
+}
+
+func TestToQuery(t *testing.T) {
+	values, err := jsonutil.ToQuery([]byte(`{
+		"filter": {"status": "active", "owner": {"id": 7}},
+		"ids": [1, 2],
+		"items": [{"name": "a"}, {"name": "b"}],
+		"ok": true,
+		"none": null,
+		"empty": {}
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"filter[status]":    {"active"},
+		"filter[owner][id]": {"7"},
+		"ids[]":             {"1", "2"},
+		"items[0][name]":    {"a"},
+		"items[1][name]":    {"b"},
+		"ok":                {"true"},
+		"none":              {""},
+	}, values)
+
+	// round trip
+	out, err := jsonutil.FromQuery(values, jsonutil.InferQueryTypes())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"filter":{"status":"active","owner":{"id":7}},"ids":[1,2],"items":[{"name":"a"},{"name":"b"}],"ok":true,"none":""}`, string(out))
+
+	_, err = jsonutil.ToQuery([]byte(`[1]`))
+	assert.EqualError(t, err, "jsonutil: query must be JSON object, got array")
+
+	_, err = jsonutil.ToQuery([]byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestProcessForm(t *testing.T) {
+	ruleSet, err := jsonutil.NewRuleSet([]jsonutil.Rule{{Keys: []string{"password"}, Action: jsonutil.ActionMask}})
+	assert.NoError(t, err)
+
+	out, err := jsonutil.ProcessForm(context.Background(), ruleSet, []byte(`username=alice&password=secret&user%5Bpassword%5D=s2&remember=1`))
+	assert.NoError(t, err)
+	assert.Equal(t, `password=%2A%2A%2A&remember=1&user%5Bpassword%5D=%2A%2A%2A&username=alice`, string(out))
+
+	_, err = jsonutil.ProcessForm(context.Background(), ruleSet, []byte(`a=%zz`))
+	assert.Error(t, err)
+
+	_, err = jsonutil.ProcessForm(context.Background(), ruleSet, []byte(`a=1&a[b]=2`))
+	assert.Error(t, err)
+}
+
+func TestIsFormMediaType(t *testing.T) {
+	assert.True(t, jsonutil.IsFormMediaType("application/x-www-form-urlencoded"))
+	assert.True(t, jsonutil.IsFormMediaType("application/x-www-form-urlencoded; charset=utf-8"))
+	assert.False(t, jsonutil.IsFormMediaType("multipart/form-data; boundary=x"))
+	assert.False(t, jsonutil.IsFormMediaType("application/json"))
+	assert.False(t, jsonutil.IsFormMediaType(""))
+}
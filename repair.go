@@ -0,0 +1,310 @@
+package jsonutil
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// FixKind is the kind of damage fixed by Repair.
+type FixKind string
+
+const (
+	FixUnquotedKey       FixKind = "unquoted_key"
+	FixSingleQuote       FixKind = "single_quote"
+	FixTrailingComma     FixKind = "trailing_comma"
+	FixControlCharacter  FixKind = "control_character"
+	FixUnterminated      FixKind = "unterminated_string"
+	FixTruncatedValue    FixKind = "truncated_value"
+	FixUnclosedContainer FixKind = "unclosed_container"
+)
+
+// Fix is a single fix applied by Repair, Offset is the position in the input.
+type Fix struct {
+	Kind   FixKind
+	Offset int
+}
+
+func (f Fix) String() string {
+	return fmt.Sprintf("%s at offset %d", f.Kind, f.Offset)
+}
+
+// Repair fix common damage on JSON-like document:
+// unquoted keys, single quoted strings, trailing commas, unescaped control characters (i.e: newline) inside strings,
+// and truncated document (unterminated string, incomplete literal or number, and unclosed object and array).
+// It returns the repaired document and every fix applied, or error when the document cannot be repaired.
+// Valid JSON is returned as is without fixes.
+func Repair(doc []byte) ([]byte, []Fix, error) {
+	if Validate(doc) == nil {
+		return doc, []Fix{}, nil
+	}
+
+	r := &repairer{data: doc, out: make([]byte, 0, len(doc)+16), fixes: []Fix{}}
+	if err := r.run(); err != nil {
+		return nil, r.fixes, err
+	}
+
+	if err := Validate(r.out); err != nil {
+		return nil, r.fixes, fmt.Errorf("jsonutil: cannot repair document: %w", err)
+	}
+
+	return r.out, r.fixes, nil
+}
+
+type repairer struct {
+	data  []byte
+	out   []byte
+	fixes []Fix
+
+	// stack of open containers, '{' or '['
+	stack []byte
+
+	// expectKey is true when the next string in the object is a key
+	expectKey bool
+	// keyPending is true when the key is written but the colon is not yet
+	keyPending bool
+}
+
+func (r *repairer) fix(kind FixKind, offset int) {
+	r.fixes = append(r.fixes, Fix{Kind: kind, Offset: offset})
+}
+
+func (r *repairer) inObject() bool {
+	return len(r.stack) > 0 && r.stack[len(r.stack)-1] == '{'
+}
+
+// lastSignificant return the last non-whitespace byte written, or 0.
+func (r *repairer) lastSignificant() (byte, int) {
+	i := len(r.out) - 1
+	for i >= 0 && isSpace(r.out[i]) {
+		i--
+	}
+
+	if i < 0 {
+		return 0, -1
+	}
+
+	return r.out[i], i
+}
+
+func (r *repairer) run() error {
+	i := 0
+	for i < len(r.data) {
+		c := r.data[i]
+		switch {
+		case isSpace(c):
+			r.out = append(r.out, c)
+			i++
+
+		case c == '{' || c == '[':
+			r.stack = append(r.stack, c)
+			r.expectKey = c == '{'
+			r.out = append(r.out, c)
+			i++
+
+		case c == '}' || c == ']':
+			if len(r.stack) == 0 || (c == '}') != r.inObject() {
+				return newSyntaxError(r.data, i, fmt.Sprintf("invalid character %q, no matching open bracket", c))
+			}
+
+			r.removeTrailingComma(i)
+			r.stack = r.stack[:len(r.stack)-1]
+			r.expectKey, r.keyPending = false, false
+			r.out = append(r.out, c)
+			i++
+
+		case c == ',':
+			r.expectKey = r.inObject()
+			r.out = append(r.out, c)
+			i++
+
+		case c == ':':
+			r.keyPending = false
+			r.out = append(r.out, c)
+			i++
+
+		case c == '"' || c == '\'':
+			i = r.str(i)
+
+		case c == '-' || (c >= '0' && c <= '9'):
+			i = r.number(i)
+
+		case isIdentStart(c):
+			end := i
+			for end < len(r.data) && isIdentPart(r.data[end]) {
+				end++
+			}
+
+			if err := r.word(i, end); err != nil {
+				return err
+			}
+			i = end
+
+		default:
+			return newSyntaxError(r.data, i, fmt.Sprintf("invalid character %q", c))
+		}
+	}
+
+	return r.closeAll()
+}
+
+// str write the string starts at i (double or single quoted), and return the offset after it.
+func (r *repairer) str(i int) int {
+	quote := r.data[i]
+	if quote == '\'' {
+		r.fix(FixSingleQuote, i)
+	}
+
+	r.out = append(r.out, '"')
+	start := i
+	i++
+	for i < len(r.data) {
+		c := r.data[i]
+		switch {
+		case c == quote:
+			r.out = append(r.out, '"')
+			r.afterString()
+			return i + 1
+
+		case c == '\\' && i+1 < len(r.data):
+			if quote == '\'' && r.data[i+1] == '\'' {
+				// \' is not valid JSON escape, and not needed inside double quoted string
+				r.out = append(r.out, '\'')
+			} else {
+				r.out = append(r.out, c, r.data[i+1])
+			}
+			i += 2
+
+		case c == '"':
+			// double quote inside single quoted string
+			r.out = append(r.out, '\\', '"')
+			i++
+
+		case c < 0x20:
+			r.fix(FixControlCharacter, i)
+			r.out = append(r.out, escapeControl(c)...)
+			i++
+
+		default:
+			r.out = append(r.out, c)
+			i++
+		}
+	}
+
+	// a lone trailing backslash would escape the closing quote
+	if bytes.HasSuffix(r.data[start:], []byte(`\`)) && !bytes.HasSuffix(r.data[start:], []byte(`\\`)) {
+		r.out = r.out[:len(r.out)-1]
+	}
+
+	r.fix(FixUnterminated, len(r.data))
+	r.out = append(r.out, '"')
+	r.afterString()
+	return i
+}
+
+func (r *repairer) afterString() {
+	if r.inObject() && r.expectKey {
+		r.expectKey, r.keyPending = false, true
+	}
+}
+
+func escapeControl(c byte) []byte {
+	switch c {
+	case '\n':
+		return []byte(`\n`)
+	case '\r':
+		return []byte(`\r`)
+	case '\t':
+		return []byte(`\t`)
+	}
+
+	return []byte(fmt.Sprintf(`\u%04x`, c))
+}
+
+// number write the number starts at i, and return the offset after it.
+func (r *repairer) number(i int) int {
+	end := i
+	for end < len(r.data) && strings.IndexByte("+-0123456789.eE", r.data[end]) >= 0 {
+		end++
+	}
+
+	r.out = append(r.out, r.data[i:end]...)
+	if end == len(r.data) && strings.IndexByte("+-.eE", r.data[end-1]) >= 0 {
+		r.fix(FixTruncatedValue, end)
+		r.out = append(r.out, '0')
+	}
+
+	return end
+}
+
+// word write unquoted word data[i:end], which is object key or literal.
+func (r *repairer) word(i, end int) error {
+	word := string(r.data[i:end])
+	if r.inObject() && r.expectKey {
+		r.fix(FixUnquotedKey, i)
+		r.out = append(append(append(r.out, '"'), word...), '"')
+		r.expectKey, r.keyPending = false, true
+		return nil
+	}
+
+	switch word {
+	case "true", "false", "null":
+		r.out = append(r.out, word...)
+		return nil
+	}
+
+	// truncated literal at the end of document
+	if end == len(r.data) {
+		for _, literal := range []string{"true", "false", "null"} {
+			if strings.HasPrefix(literal, word) {
+				r.fix(FixTruncatedValue, end)
+				r.out = append(r.out, literal...)
+				return nil
+			}
+		}
+	}
+
+	return newSyntaxError(r.data, i, fmt.Sprintf("invalid literal %q", word))
+}
+
+// removeTrailingComma remove comma written right before the closing bracket at offset i.
+func (r *repairer) removeTrailingComma(i int) {
+	if c, at := r.lastSignificant(); c == ',' {
+		r.fix(FixTrailingComma, i)
+		r.out = append(r.out[:at], r.out[at+1:]...)
+	}
+}
+
+// closeAll complete the truncated document at the end of data.
+func (r *repairer) closeAll() error {
+	end := len(r.data)
+	if r.keyPending {
+		r.fix(FixTruncatedValue, end)
+		r.out = append(r.out, ":null"...)
+		r.keyPending = false
+	} else if c, _ := r.lastSignificant(); c == ':' {
+		r.fix(FixTruncatedValue, end)
+		r.out = append(r.out, "null"...)
+	}
+
+	for len(r.stack) > 0 {
+		r.removeTrailingComma(end)
+		r.fix(FixUnclosedContainer, end)
+		if r.stack[len(r.stack)-1] == '{' {
+			r.out = append(r.out, '}')
+		} else {
+			r.out = append(r.out, ']')
+		}
+		r.stack = r.stack[:len(r.stack)-1]
+	}
+
+	return nil
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '-'
+}
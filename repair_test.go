@@ -0,0 +1,100 @@
+package jsonutil_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Input  string
+		Output string
+		Fixes  []jsonutil.Fix
+	}{
+		{
+			Name:   "valid",
+			Input:  `{"a": [1, true]}`,
+			Output: `{"a": [1, true]}`,
+			Fixes:  []jsonutil.Fix{},
+		},
+		{
+			Name:   "unquoted key",
+			Input:  `{name: "alice", user_id: 1}`,
+			Output: `{"name": "alice", "user_id": 1}`,
+			Fixes:  []jsonutil.Fix{{Kind: jsonutil.FixUnquotedKey, Offset: 1}, {Kind: jsonutil.FixUnquotedKey, Offset: 16}},
+		},
+		{
+			Name:   "single quote",
+			Input:  `{'a': 'it\'s "ok"'}`,
+			Output: `{"a": "it's \"ok\""}`,
+			Fixes:  []jsonutil.Fix{{Kind: jsonutil.FixSingleQuote, Offset: 1}, {Kind: jsonutil.FixSingleQuote, Offset: 6}},
+		},
+		{
+			Name:   "trailing comma",
+			Input:  `{"a": [1, 2, ], }`,
+			Output: `{"a": [1, 2 ] }`,
+			Fixes:  []jsonutil.Fix{{Kind: jsonutil.FixTrailingComma, Offset: 13}, {Kind: jsonutil.FixTrailingComma, Offset: 16}},
+		},
+		{
+			Name:   "newline inside string",
+			Input:  "{\"a\": \"line1\nline2\tend\"}",
+			Output: `{"a": "line1\nline2\tend"}`,
+			Fixes:  []jsonutil.Fix{{Kind: jsonutil.FixControlCharacter, Offset: 12}, {Kind: jsonutil.FixControlCharacter, Offset: 18}},
+		},
+		{
+			Name:   "truncated",
+			Input:  `{"a": [1, {"b": "unterminated`,
+			Output: `{"a": [1, {"b": "unterminated"}]}`,
+			Fixes: []jsonutil.Fix{
+				{Kind: jsonutil.FixUnterminated, Offset: 29},
+				{Kind: jsonutil.FixUnclosedContainer, Offset: 29},
+				{Kind: jsonutil.FixUnclosedContainer, Offset: 29},
+				{Kind: jsonutil.FixUnclosedContainer, Offset: 29},
+			},
+		},
+		{
+			Name:   "truncated after key",
+			Input:  `{"a": 1, "b`,
+			Output: `{"a": 1, "b":null}`,
+			Fixes: []jsonutil.Fix{
+				{Kind: jsonutil.FixUnterminated, Offset: 11},
+				{Kind: jsonutil.FixTruncatedValue, Offset: 11},
+				{Kind: jsonutil.FixUnclosedContainer, Offset: 11},
+			},
+		},
+		{
+			Name:   "truncated literal and number",
+			Input:  `[tr`,
+			Output: `[true]`,
+			Fixes:  []jsonutil.Fix{{Kind: jsonutil.FixTruncatedValue, Offset: 3}, {Kind: jsonutil.FixUnclosedContainer, Offset: 3}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			out, fixes, err := jsonutil.Repair([]byte(tc.Input))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Output, string(out))
+			assert.Equal(t, tc.Fixes, fixes)
+		})
+	}
+}
+
+func TestRepair_Error(t *testing.T) {
+	_, _, err := jsonutil.Repair([]byte(`{"a": 1]`))
+	assert.Error(t, err)
+
+	_, _, err = jsonutil.Repair([]byte(`{"a": undefined}`))
+	assert.EqualError(t, err, `jsonutil: invalid literal "undefined" at line 1, column 7 (offset 6) near "{\"a\": undefined}"`)
+
+	// incomplete number which is not at the end of document cannot be repaired
+	_, _, err = jsonutil.Repair([]byte(`{"a": 1.5e, "b": 2}`))
+	assert.Error(t, err)
+
+	out, _, err := jsonutil.Repair([]byte(`[1, 2.`))
+	assert.NoError(t, err)
+	assert.Equal(t, `[1, 2.0]`, string(out))
+}
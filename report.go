@@ -0,0 +1,159 @@
+package jsonutil
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RedactionReport record one value redacted by RuleSet, so compliance can prove which fields were redacted per payload.
+// The JSON encoding of this struct is stable, new field is only added and never renamed or removed.
+type RedactionReport struct {
+	DocumentID string    `json:"document_id,omitempty"`
+	RuleID     string    `json:"rule_id"`
+	Path       string    `json:"path"` // Path is in JSON Pointer form, i.e: /items/0/token
+	Action     Action    `json:"action"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+type documentIDKey struct{}
+
+// WithDocumentID return ctx carrying the document id, it is used as RedactionReport.DocumentID.
+func WithDocumentID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, documentIDKey{}, id)
+}
+
+// DocumentIDFromContext return the document id set by WithDocumentID, or empty string.
+func DocumentIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(documentIDKey{}).(string)
+	return id
+}
+
+// addReport append the report of rule applied on segments, the DocumentID and Timestamp is filled by the caller.
+func addReport(report *[]RedactionReport, rule *compiledRule, segments []string) {
+	if report == nil {
+		return
+	}
+
+	*report = append(*report, RedactionReport{
+		RuleID: rule.ID,
+		Path:   JoinPointer(segments),
+		Action: rule.Action,
+	})
+}
+
+// ProcessWithReport is like Process but also return the report of every redacted value, sorted by the path.
+// The document id is taken from ctx, see WithDocumentID.
+func (p *RuleSet) ProcessWithReport(ctx context.Context, doc []byte) ([]byte, []RedactionReport, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, nil, err
+	}
+
+	report := make([]RedactionReport, 0)
+	out, _ := p.walk(data, "", false, []string{}, nil, &report)
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		return pointerLess(report[i].Path, report[j].Path)
+	})
+
+	id, now := DocumentIDFromContext(ctx), time.Now().UTC()
+	for i := range report {
+		report[i].DocumentID = id
+		report[i].Timestamp = now
+	}
+
+	return b, report, nil
+}
+
+// pointerLess return true when JSON Pointer a is sorted before b, segment by segment.
+// Array indexes are compared as numbers, so /items/2 is before /items/10.
+func pointerLess(a, b string) bool {
+	for a != "" && b != "" {
+		segA, restA := nextSegment(a)
+		segB, restB := nextSegment(b)
+		if segA != segB {
+			if isDigits(segA) && isDigits(segB) && len(segA) != len(segB) {
+				return len(segA) < len(segB)
+			}
+
+			return segA < segB
+		}
+
+		a, b = restA, restB
+	}
+
+	// the parent is before its children
+	return a == "" && b != ""
+}
+
+// nextSegment split JSON Pointer into its first segment (still escaped) and the rest.
+func nextSegment(pointer string) (segment, rest string) {
+	pointer = pointer[1:]
+	if i := strings.IndexByte(pointer, '/'); i >= 0 {
+		return pointer[:i], pointer[i:]
+	}
+
+	return pointer, ""
+}
+
+// AuditWriter append RedactionReport into the audit sink as NDJSON, one report per line.
+// It is safe for concurrent use.
+type AuditWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewAuditWriter return AuditWriter writing into w, i.e: an append-only file.
+func NewAuditWriter(w io.Writer) *AuditWriter {
+	return &AuditWriter{w: w}
+}
+
+// Write append the reports, reports of the same call is written in a single Write to w.
+func (a *AuditWriter) Write(reports ...RedactionReport) error {
+	if len(reports) == 0 {
+		return nil
+	}
+
+	var buf []byte
+	for _, report := range reports {
+		b, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+
+		buf = append(append(buf, b...), '\n')
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	_, err := a.w.Write(buf)
+	return err
+}
+
+// Audited return Processor which process the document using p and write its redaction report into w.
+// When the report cannot be written, the error is returned and the output is discarded,
+// so no document leaves without its audit trail.
+func Audited(p *RuleSet, w *AuditWriter) Processor {
+	return ProcessorFunc(func(ctx context.Context, doc []byte) ([]byte, error) {
+		out, report, err := p.ProcessWithReport(ctx, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = w.Write(report...); err != nil {
+			return nil, err
+		}
+
+		return out, nil
+	})
+}
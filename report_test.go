@@ -0,0 +1,135 @@
+package jsonutil_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func reportRuleSet(t *testing.T) *jsonutil.RuleSet {
+	ruleSet, err := jsonutil.NewRuleSet([]jsonutil.Rule{
+		{ID: "credentials", Keys: []string{"password"}, Action: jsonutil.ActionMask},
+		{ID: "internal", Keys: []string{"internal"}, Action: jsonutil.ActionDrop},
+		{ID: "pii", Paths: []string{"users.*.email"}, Action: jsonutil.ActionHash},
+		{ID: "body", Paths: []string{"body"}, Action: jsonutil.ActionTruncate, MaxChars: 3},
+	})
+	assert.NoError(t, err)
+	return ruleSet
+}
+
+func TestRuleSet_ProcessWithReport(t *testing.T) {
+	doc := `{"password":"x","internal":1,"users":[{"email":"a@b.c"}],"body":{"short":"ab","long":"abcdef"}}`
+	ctx := jsonutil.WithDocumentID(context.Background(), "req-1")
+
+	before := time.Now().UTC()
+	out, reports, err := reportRuleSet(t).ProcessWithReport(ctx, []byte(doc))
+	assert.NoError(t, err)
+
+	expectedOut, err := reportRuleSet(t).Process(ctx, []byte(doc))
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(expectedOut), string(out))
+
+	for _, report := range reports {
+		assert.Equal(t, "req-1", report.DocumentID)
+		assert.False(t, report.Timestamp.Before(before))
+	}
+
+	type entry struct {
+		RuleID string
+		Path   string
+		Action jsonutil.Action
+	}
+
+	entries := make([]entry, len(reports))
+	for i, r := range reports {
+		entries[i] = entry{r.RuleID, r.Path, r.Action}
+	}
+
+	assert.Equal(t, []entry{
+		{"body", "/body/long", jsonutil.ActionTruncate},
+		{"internal", "/internal", jsonutil.ActionDrop},
+		{"credentials", "/password", jsonutil.ActionMask},
+		{"pii", "/users/0/email", jsonutil.ActionHash},
+	}, entries)
+
+	_, _, err = reportRuleSet(t).ProcessWithReport(ctx, []byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestRuleSet_ProcessWithReport_IndexOrder(t *testing.T) {
+	items := make([]string, 12)
+	for i := range items {
+		items[i] = `{"token":"x"}`
+	}
+
+	doc := `{"items":[` + strings.Join(items, ",") + `],"items2":[{"token":"y"}]}`
+	ruleSet, err := jsonutil.NewRuleSet([]jsonutil.Rule{{Keys: []string{"token"}, Action: jsonutil.ActionMask}})
+	assert.NoError(t, err)
+
+	_, reports, err := ruleSet.ProcessWithReport(context.Background(), []byte(doc))
+	assert.NoError(t, err)
+
+	paths := make([]string, len(reports))
+	for i, r := range reports {
+		paths[i] = r.Path
+	}
+
+	assert.Equal(t, []string{
+		"/items/0/token", "/items/1/token", "/items/2/token", "/items/3/token", "/items/4/token", "/items/5/token",
+		"/items/6/token", "/items/7/token", "/items/8/token", "/items/9/token", "/items/10/token", "/items/11/token",
+		"/items2/0/token",
+	}, paths)
+}
+
+func TestRedactionReport_JSON(t *testing.T) {
+	report := jsonutil.RedactionReport{
+		DocumentID: "req-1",
+		RuleID:     "credentials",
+		Path:       "/password",
+		Action:     jsonutil.ActionMask,
+		Timestamp:  time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	b, err := json.Marshal(report)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"document_id":"req-1","rule_id":"credentials","path":"/password","action":"mask","timestamp":"2023-01-02T03:04:05Z"}`, string(b))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("sink unavailable")
+}
+
+func TestAudited(t *testing.T) {
+	var sink bytes.Buffer
+	p := jsonutil.Audited(reportRuleSet(t), jsonutil.NewAuditWriter(&sink))
+
+	ctx := jsonutil.WithDocumentID(context.Background(), "req-2")
+	out, err := p.Process(ctx, []byte(`{"password":"x","name":"alice"}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"password":"***","name":"alice"}`, string(out))
+
+	_, err = p.Process(context.Background(), []byte(`{"name":"bob"}`))
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+	assert.Len(t, lines, 1)
+
+	var report jsonutil.RedactionReport
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &report))
+	assert.Equal(t, "req-2", report.DocumentID)
+	assert.Equal(t, "/password", report.Path)
+
+	failing := jsonutil.Audited(reportRuleSet(t), jsonutil.NewAuditWriter(failingWriter{}))
+	out, err = failing.Process(ctx, []byte(`{"password":"x"}`))
+	assert.EqualError(t, err, "sink unavailable")
+	assert.Nil(t, out)
+}
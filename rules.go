@@ -0,0 +1,300 @@
+package jsonutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Action is what to do with the value matched by a Rule.
+type Action string
+
+const (
+	ActionMask     Action = "mask"     // replace the value with Rule.Placeholder
+	ActionHash     Action = "hash"     // replace the value with "sha256:<hex>" of the value
+	ActionTruncate Action = "truncate" // truncate every string value into Rule.MaxChars characters
+	ActionDrop     Action = "drop"     // remove the key (or array element) entirely
+)
+
+// DefaultPlaceholder is used by ActionMask when Rule.Placeholder is empty.
+const DefaultPlaceholder = "***"
+
+// Rule is one declarative sanitization rule.
+// Value is matched when its key is in Keys, its key match one of Patterns (regular expression),
+// or its path match one of Paths (dotted form, "*" match any single segment, i.e: items.*.token).
+// Rule without any matcher is matched against every string value, useful for global truncation.
+type Rule struct {
+	ID          string   `json:"id" yaml:"id"`
+	Keys        []string `json:"keys" yaml:"keys"`
+	Patterns    []string `json:"patterns" yaml:"patterns"`
+	Paths       []string `json:"paths" yaml:"paths"`
+	Action      Action   `json:"action" yaml:"action"`
+	Placeholder string   `json:"placeholder" yaml:"placeholder"`
+	MaxChars    int      `json:"max_chars" yaml:"max_chars"`
+}
+
+// RulesFile is the policy document format accepted by LoadRules, i.e:
+//
+//	rules:
+//	  - id: credentials
+//	    keys: [password, secret]
+//	    patterns: ["(?i)_token$"]
+//	    action: mask
+//	  - id: body
+//	    paths: [request.body]
+//	    action: truncate
+//	    max_chars: 1024
+type RulesFile struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+type compiledRule struct {
+	Rule
+	keys     map[string]struct{}
+	patterns []*regexp.Regexp
+	paths    [][]string
+}
+
+// RuleSet sanitize JSON document according to the rules.
+// The first rule (in order of declaration) that match the value wins.
+type RuleSet struct {
+	rules []*compiledRule
+}
+
+// LoadRules read the YAML or JSON policy document (see RulesFile) and return the Pipeline of the RuleSet.
+func LoadRules(r io.Reader) (*Pipeline, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML, so one decoder is enough for both format
+	var file RulesFile
+	if err = yaml.Unmarshal(b, &file); err != nil {
+		return nil, fmt.Errorf("jsonutil: cannot parse rules: %w", err)
+	}
+
+	ruleSet, err := NewRuleSet(file.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPipeline(ruleSet), nil
+}
+
+// NewRuleSet validate and compile the rules.
+func NewRuleSet(rules []Rule) (*RuleSet, error) {
+	p := &RuleSet{rules: make([]*compiledRule, 0, len(rules))}
+	for i, rule := range rules {
+		c, err := compileRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("jsonutil: rule %d %q: %w", i, rule.ID, err)
+		}
+
+		p.rules = append(p.rules, c)
+	}
+
+	return p, nil
+}
+
+func compileRule(rule Rule) (*compiledRule, error) {
+	switch rule.Action {
+	case ActionMask:
+		if rule.Placeholder == "" {
+			rule.Placeholder = DefaultPlaceholder
+		}
+	case ActionHash, ActionDrop:
+	case ActionTruncate:
+		if rule.MaxChars <= 0 {
+			return nil, fmt.Errorf("max_chars must be greater than 0 for action truncate")
+		}
+	default:
+		return nil, fmt.Errorf("unknown action %q", rule.Action)
+	}
+
+	c := &compiledRule{Rule: rule, keys: make(map[string]struct{}, len(rule.Keys))}
+	for _, key := range rule.Keys {
+		c.keys[key] = struct{}{}
+	}
+
+	for _, pattern := range rule.Patterns {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		c.patterns = append(c.patterns, regex)
+	}
+
+	for _, path := range rule.Paths {
+		c.paths = append(c.paths, SplitPath(path))
+	}
+
+	return c, nil
+}
+
+func (c *compiledRule) isGlobal() bool {
+	return len(c.keys) == 0 && len(c.patterns) == 0 && len(c.paths) == 0
+}
+
+// match return true if the value with key (empty for array element) on path segments is matched.
+func (c *compiledRule) match(key string, isKey bool, segments []string) bool {
+	if isKey {
+		if _, ok := c.keys[key]; ok {
+			return true
+		}
+
+		for _, regex := range c.patterns {
+			if regex.MatchString(key) {
+				return true
+			}
+		}
+	}
+
+	for _, path := range c.paths {
+		if matchSegments(path, segments) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchSegments return true when segments equal to pattern, "*" in pattern match any single segment.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) != len(segments) {
+		return false
+	}
+
+	for i, p := range pattern {
+		if p != "*" && p != segments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Len return the number of rules.
+func (p *RuleSet) Len() int {
+	return len(p.rules)
+}
+
+// Process sanitize doc and return the new document.
+func (p *RuleSet) Process(ctx context.Context, doc []byte) ([]byte, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, err
+	}
+
+	out, _ := p.walk(data, "", false, []string{}, nil, nil)
+	return json.Marshal(out)
+}
+
+// walk return the sanitized value, and drop is true when the value must be removed from its parent.
+// inherited is the truncate rule matched on the ancestor, it applies to string value not matched by other rule.
+// When report is not nil, every applied rule is appended into it.
+func (p *RuleSet) walk(v interface{}, key string, isKey bool, segments []string, inherited *compiledRule, report *[]RedactionReport) (out interface{}, drop bool) {
+	if rule := p.matchRule(v, key, isKey, segments); rule != nil {
+		if rule.Action != ActionTruncate {
+			addReport(report, rule, segments)
+		}
+
+		switch rule.Action {
+		case ActionDrop:
+			return nil, true
+		case ActionMask:
+			return rule.Placeholder, false
+		case ActionHash:
+			return hashValue(v), false
+		case ActionTruncate:
+			inherited = rule
+		}
+	}
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		newMap := make(map[string]interface{}, len(val))
+		for k, child := range val {
+			newVal, drop := p.walk(child, k, true, append(segments, k), inherited, report)
+			if !drop {
+				newMap[k] = newVal
+			}
+		}
+
+		return newMap, false
+
+	case []interface{}:
+		newSlices := make([]interface{}, 0, len(val))
+		for i, child := range val {
+			newVal, drop := p.walk(child, "", false, append(segments, strconv.Itoa(i)), inherited, report)
+			if !drop {
+				newSlices = append(newSlices, newVal)
+			}
+		}
+
+		return newSlices, false
+
+	case string:
+		if inherited != nil {
+			truncated := TruncateString(val, inherited.MaxChars)
+			if truncated != val {
+				addReport(report, inherited, segments)
+			}
+			return truncated, false
+		}
+	}
+
+	return v, false
+}
+
+// matchRule return the first rule matched, or nil.
+func (p *RuleSet) matchRule(v interface{}, key string, isKey bool, segments []string) *compiledRule {
+	_, isString := v.(string)
+	for _, rule := range p.rules {
+		if rule.match(key, isKey, segments) || (isString && rule.isGlobal()) {
+			return rule
+		}
+	}
+
+	return nil
+}
+
+// Match return the first rule matched by the value on path segments, so the rules can be applied to other format (i.e: BSON).
+// isKey is true when the value is an object member (the last segment is its key),
+// and isString is true for string value, since rule without any matcher only match string value.
+func (p *RuleSet) Match(segments []string, isKey, isString bool) (Rule, bool) {
+	key := ""
+	if isKey && len(segments) > 0 {
+		key = segments[len(segments)-1]
+	}
+
+	for _, rule := range p.rules {
+		if rule.match(key, isKey, segments) || (isString && rule.isGlobal()) {
+			return rule.Rule, true
+		}
+	}
+
+	return Rule{}, false
+}
+
+// hashValue return "sha256:<hex>" of string value, or of the JSON encoding for other type.
+func hashValue(v interface{}) string {
+	var b []byte
+	if str, ok := v.(string); ok {
+		b = []byte(str)
+	} else {
+		b, _ = json.Marshal(v)
+	}
+
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
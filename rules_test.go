@@ -0,0 +1,99 @@
+package jsonutil_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+const sampleRulesYAML = `
+rules:
+  - id: credentials
+    keys: [password]
+    patterns: ["(?i)_token$"]
+    action: mask
+  - id: email
+    paths: [users.*.email]
+    action: hash
+  - id: debug
+    keys: [debug_stack]
+    action: drop
+  - id: body
+    paths: [request.body]
+    action: truncate
+    max_chars: 3
+`
+
+func TestLoadRules(t *testing.T) {
+	p, err := jsonutil.LoadRules(strings.NewReader(sampleRulesYAML))
+	assert.NoError(t, err)
+
+	in := `{
+		"password": 12345,
+		"Access_Token": "abc",
+		"users": [{"email": "a@b.c", "name": "alice"}],
+		"debug_stack": ["x"],
+		"request": {"body": {"text": "hello", "list": ["world"], "password": "secret"}},
+		"other": "untouched"
+	}`
+
+	out, err := p.Process(context.Background(), []byte(in))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"password": "***",
+		"Access_Token": "***",
+		"users": [{"email": "sha256:d648b243a3e817eaa3309e00e183483f2867baadf522099f0c2121770536b25a", "name": "alice"}],
+		"request": {"body": {"text": "hel **escaped 2 chars**", "list": ["wor **escaped 2 chars**"], "password": "***"}},
+		"other": "untouched"
+	}`, string(out))
+}
+
+func TestLoadRules_JSON(t *testing.T) {
+	p, err := jsonutil.LoadRules(strings.NewReader(`{"rules":[{"action":"truncate","max_chars":2}]}`))
+	assert.NoError(t, err)
+
+	out, err := p.Process(context.Background(), []byte(`{"a":"abc","b":["xyz"],"n":12345678901234567890}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":"ab **escaped 1 chars**","b":["xy **escaped 1 chars**"],"n":12345678901234567890}`, string(out))
+}
+
+func TestLoadRules_Invalid(t *testing.T) {
+	for _, rules := range []string{
+		`{"rules":[{"action":"unknown"}]}`,
+		`{"rules":[{"action":"truncate"}]}`,
+		`{"rules":[{"action":"mask","patterns":["("]}]}`,
+		`{"rules":[`,
+	} {
+		_, err := jsonutil.LoadRules(strings.NewReader(rules))
+		assert.Error(t, err, rules)
+	}
+}
+
+func TestRuleSet_Match(t *testing.T) {
+	rs, err := jsonutil.NewRuleSet([]jsonutil.Rule{
+		{ID: "credentials", Keys: []string{"password"}, Action: jsonutil.ActionMask},
+		{ID: "email", Paths: []string{"users.*.email"}, Action: jsonutil.ActionHash},
+		{ID: "all", Action: jsonutil.ActionTruncate, MaxChars: 10},
+	})
+	assert.NoError(t, err)
+
+	rule, ok := rs.Match([]string{"user", "password"}, true, false)
+	assert.True(t, ok)
+	assert.Equal(t, "credentials", rule.ID)
+	assert.Equal(t, jsonutil.DefaultPlaceholder, rule.Placeholder)
+
+	rule, ok = rs.Match([]string{"users", "0", "email"}, true, true)
+	assert.True(t, ok)
+	assert.Equal(t, "email", rule.ID)
+
+	// array element has no key
+	_, ok = rs.Match([]string{"list", "password"}, false, false)
+	assert.False(t, ok)
+
+	rule, ok = rs.Match([]string{"list", "0"}, false, true)
+	assert.True(t, ok)
+	assert.Equal(t, "all", rule.ID)
+}
@@ -0,0 +1,127 @@
+package jsonutil
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultWatchInterval is how often RuleWatcher check the file when RuleWatcherConfig.Interval is not set.
+const DefaultWatchInterval = 5 * time.Second
+
+type RuleWatcherConfig struct {
+	// Path is the rules file, see LoadRules for the format.
+	Path string
+
+	// Interval is how often the file modification time and size is checked.
+	Interval time.Duration
+
+	// OnError is called when the changed file cannot be loaded, the active pipeline is kept.
+	OnError func(err error)
+}
+
+// RuleWatcher keep the Pipeline loaded from rules file up to date.
+// The active pipeline is swapped atomically, so it is safe to call Process while the file is reloaded.
+type RuleWatcher struct {
+	conf RuleWatcherConfig
+
+	active atomic.Value // *Pipeline
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// NewRuleWatcher load the rules file and return error if the initial load is failed.
+// Call Run to start watching the file changes.
+func NewRuleWatcher(conf RuleWatcherConfig) (*RuleWatcher, error) {
+	if conf.Interval <= 0 {
+		conf.Interval = DefaultWatchInterval
+	}
+
+	if conf.OnError == nil {
+		conf.OnError = func(err error) {}
+	}
+
+	w := &RuleWatcher{conf: conf}
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// Pipeline return the active pipeline.
+func (w *RuleWatcher) Pipeline() *Pipeline {
+	return w.active.Load().(*Pipeline)
+}
+
+// Process sanitize doc using the active pipeline.
+func (w *RuleWatcher) Process(ctx context.Context, doc []byte) ([]byte, error) {
+	return w.Pipeline().Process(ctx, doc)
+}
+
+// Reload read the rules file and swap the active pipeline.
+// When the file is invalid, error is returned and the active pipeline is kept,
+// Run doesn't retry it until the file is changed again.
+func (w *RuleWatcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	f, err := os.Open(w.conf.Path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	w.modTime, w.size = stat.ModTime(), stat.Size()
+	pipeline, err := LoadRules(f)
+	if err != nil {
+		return err
+	}
+
+	w.active.Store(pipeline)
+	return nil
+}
+
+// Run check the file every interval and reload it when the modification time or size is changed.
+// It blocks until ctx is done.
+func (w *RuleWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.conf.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !w.changed() {
+				continue
+			}
+
+			if err := w.Reload(); err != nil {
+				w.conf.OnError(err)
+			}
+		}
+	}
+}
+
+func (w *RuleWatcher) changed() bool {
+	stat, err := os.Stat(w.conf.Path)
+	if err != nil {
+		w.conf.OnError(err)
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return !stat.ModTime().Equal(w.modTime) || stat.Size() != w.size
+}
@@ -0,0 +1,82 @@
+package jsonutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestRuleWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonutil")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "rules.yaml")
+	err = ioutil.WriteFile(path, []byte(`{"rules":[{"keys":["a"],"action":"mask"}]}`), 0600)
+	assert.NoError(t, err)
+
+	errs := make(chan error, 10)
+	w, err := jsonutil.NewRuleWatcher(jsonutil.RuleWatcherConfig{
+		Path:     path,
+		Interval: 10 * time.Millisecond,
+		OnError: func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		},
+	})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.Run(ctx)
+
+	out, err := w.Process(ctx, []byte(`{"a":"1","b":"2"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":"***","b":"2"}`, string(out))
+
+	// changed file is picked up by Run
+	err = ioutil.WriteFile(path, []byte(`{"rules":[{"keys":["a","b"],"action":"mask","placeholder":"x"}]}`), 0600)
+	assert.NoError(t, err)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		out, _ = w.Process(ctx, []byte(`{"a":"1","b":"2"}`))
+		if string(out) == `{"a":"x","b":"x"}` {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, `{"a":"x","b":"x"}`, string(out))
+
+	// invalid file keep the active pipeline, and is reported once
+	// written to other file first, so Run never see it half written
+	err = ioutil.WriteFile(path+".tmp", []byte(`{"rules":[{"action":"invalid"}]}`), 0600)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Rename(path+".tmp", path))
+
+	select {
+	case err = <-errs:
+		assert.Error(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("invalid file is not reported")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, errs, 0)
+	assert.Error(t, w.Reload())
+
+	out, err = w.Process(ctx, []byte(`{"a":"1"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":"x"}`, string(out))
+
+	_, err = jsonutil.NewRuleWatcher(jsonutil.RuleWatcherConfig{Path: filepath.Join(dir, "missing.yaml")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,233 @@
+package jsonutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+	"unicode/utf8"
+)
+
+// SanitizerConfig is the configuration of Sanitizer.
+type SanitizerConfig struct {
+	// MaskKeys is the keys which string value (or string elements when the value is array) is replaced by Placeholder.
+	MaskKeys []string
+
+	// Placeholder is the masked value, default to DefaultPlaceholder.
+	Placeholder string
+
+	// MaxChars truncate every string value longer than MaxChars characters using TruncateString.
+	// Zero means no truncation.
+	MaxChars int
+
+	// PartialOnCancel when true, Sanitize return the already sanitized prefix with *PartialError
+	// when ctx is cancelled in the middle of the document, instead of nil output.
+	PartialOnCancel bool
+
+	// Metrics receive the number of masked fields, truncated bytes, size and latency of every document.
+	Metrics Metrics
+}
+
+// Sanitizer apply key-based masking and length-based truncation in a single pass over the raw bytes.
+// Unlike Transformer, it never decode the document into interface{},
+// so the key order, whitespace and number precision of the input is preserved.
+type Sanitizer struct {
+	maskKeys    map[string]struct{}
+	placeholder []byte
+	maxChars    int
+	partial     bool
+	metrics     Metrics
+}
+
+// sanitizeStats is the number of masked fields and truncated bytes of one document.
+type sanitizeStats struct {
+	masked    int
+	truncated int
+}
+
+// NewSanitizer return Sanitizer using conf, Placeholder default to DefaultPlaceholder.
+func NewSanitizer(conf SanitizerConfig) *Sanitizer {
+	if conf.Placeholder == "" {
+		conf.Placeholder = DefaultPlaceholder
+	}
+
+	if conf.Metrics == nil {
+		conf.Metrics = NopMetrics{}
+	}
+
+	placeholder, _ := json.Marshal(conf.Placeholder)
+	s := &Sanitizer{
+		maskKeys:    make(map[string]struct{}, len(conf.MaskKeys)),
+		placeholder: placeholder,
+		maxChars:    conf.MaxChars,
+		partial:     conf.PartialOnCancel,
+		metrics:     conf.Metrics,
+	}
+
+	for _, key := range conf.MaskKeys {
+		s.maskKeys[key] = struct{}{}
+	}
+
+	return s
+}
+
+// Sanitize return the sanitized copy of doc.
+// The ctx is checked before every object member and array element,
+// see SanitizerConfig.PartialOnCancel for the result when it is cancelled.
+func (s *Sanitizer) Sanitize(ctx context.Context, doc []byte) (out []byte, err error) {
+	begin := time.Now()
+	stats := &sanitizeStats{}
+	defer func() {
+		observe(s.metrics, doc, begin, stats.masked, stats.truncated, err)
+	}()
+
+	start, err := scanDocument(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	out = make([]byte, 0, len(doc))
+	out = append(out, doc[:start]...)
+	out, end, err := s.value(ctx, out, doc, start, false, stats)
+	if err != nil && s.partial && err == ctx.Err() {
+		return out, &PartialError{Offset: end, Err: err}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append(out, doc[end:]...), nil
+}
+
+// Process implements Processor, it is the same as Sanitize.
+func (s *Sanitizer) Process(ctx context.Context, doc []byte) ([]byte, error) {
+	return s.Sanitize(ctx, doc)
+}
+
+// value write the sanitized value starts at offset i into out, and return the offset after the value.
+// On error, the output written so far is returned along with the offset where it stops.
+func (s *Sanitizer) value(ctx context.Context, out, data []byte, i int, mask bool, stats *sanitizeStats) ([]byte, int, error) {
+	switch data[i] {
+	case '"':
+		return s.str(out, data, i, mask, stats)
+
+	case '{':
+		out = append(out, '{')
+		i++
+		for {
+			next := skipSpace(data, i)
+			out = append(out, data[i:next]...)
+			i = next
+
+			if data[i] == '}' {
+				return append(out, '}'), i + 1, nil
+			}
+
+			if data[i] == ',' {
+				out = append(out, ',')
+				i++
+				continue
+			}
+
+			if err := ctx.Err(); err != nil {
+				return out, i, err
+			}
+
+			keyEnd, err := scanString(data, i)
+			if err != nil {
+				return out, i, err
+			}
+
+			key, err := unquote(data[i:keyEnd])
+			if err != nil {
+				return out, i, err
+			}
+
+			_, masked := s.maskKeys[key]
+
+			// key, whitespace, colon and whitespace is copied as is
+			valueStart := skipSpace(data, skipSpace(data, keyEnd)+1)
+			out = append(out, data[i:valueStart]...)
+
+			out, i, err = s.value(ctx, out, data, valueStart, masked, stats)
+			if err != nil {
+				return out, i, err
+			}
+		}
+
+	case '[':
+		out = append(out, '[')
+		i++
+		for {
+			next := skipSpace(data, i)
+			out = append(out, data[i:next]...)
+			i = next
+
+			switch data[i] {
+			case ']':
+				return append(out, ']'), i + 1, nil
+			case ',':
+				out = append(out, ',')
+				i++
+				continue
+			}
+
+			if err := ctx.Err(); err != nil {
+				return out, i, err
+			}
+
+			var err error
+			out, i, err = s.value(ctx, out, data, i, mask, stats)
+			if err != nil {
+				return out, i, err
+			}
+		}
+	}
+
+	end, err := scanValue(data, i)
+	if err != nil {
+		return out, i, err
+	}
+
+	return append(out, data[i:end]...), end, nil
+}
+
+func (s *Sanitizer) str(out, data []byte, i int, mask bool, stats *sanitizeStats) ([]byte, int, error) {
+	end, err := scanString(data, i)
+	if err != nil {
+		return out, i, err
+	}
+
+	if mask {
+		stats.masked++
+		return append(out, s.placeholder...), end, nil
+	}
+
+	raw := data[i:end]
+	if s.maxChars <= 0 {
+		return append(out, raw...), end, nil
+	}
+
+	// without escape sequence, the raw content length is the actual string length
+	if bytes.IndexByte(raw, '\\') < 0 && utf8.RuneCount(raw[1:len(raw)-1]) <= s.maxChars {
+		return append(out, raw...), end, nil
+	}
+
+	str, err := unquote(raw)
+	if err != nil {
+		return out, i, err
+	}
+
+	if utf8.RuneCountInString(str) <= s.maxChars {
+		return append(out, raw...), end, nil
+	}
+
+	truncated, err := json.Marshal(TruncateString(str, s.maxChars))
+	if err != nil {
+		return out, i, err
+	}
+
+	stats.truncated += truncatedBytes(str, s.maxChars)
+	return append(out, truncated...), end, nil
+}
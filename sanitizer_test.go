@@ -0,0 +1,171 @@
+package jsonutil_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestSanitizer_Sanitize(t *testing.T) {
+	s := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{
+		MaskKeys: []string{"password", "tags"},
+		MaxChars: 5,
+	})
+
+	type testCase struct {
+		Name   string
+		Input  string
+		Output string
+	}
+
+	testCases := []testCase{
+		{
+			Name:   "preserve formatting and order",
+			Input:  "{\n  \"z\": 9007199254740993,\n  \"password\" : \"secret\",\n  \"a\": \"short\"\n}",
+			Output: "{\n  \"z\": 9007199254740993,\n  \"password\" : \"***\",\n  \"a\": \"short\"\n}",
+		},
+		{
+			Name:   "truncate long string",
+			Input:  `{"text":"hello world","nested":{"text":["こんにちは世界"]}}`,
+			Output: `{"text":"hello **escaped 6 chars**","nested":{"text":["こんにちは **escaped 2 chars**"]}}`,
+		},
+		{
+			Name:   "masked array elements but not nested object",
+			Input:  `{"tags":["a",1,{"x":"y"}]}`,
+			Output: `{"tags":["***",1,{"x":"y"}]}`,
+		},
+		{
+			Name:   "escaped string",
+			Input:  `["\"quoted\"", "ABC"]`,
+			Output: `["\"quot **escaped 3 chars**", "ABC"]`,
+		},
+		{
+			Name:   "top level string",
+			Input:  ` "abcdefg" `,
+			Output: ` "abcde **escaped 2 chars**" `,
+		},
+		{
+			Name:   "empty containers",
+			Input:  `{"a":[ ],"b":{ }}`,
+			Output: `{"a":[ ],"b":{ }}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			out, err := s.Sanitize(context.Background(), []byte(tc.Input))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.Output, string(out))
+		})
+	}
+
+	for _, invalid := range []string{`{"a":}`, `{"a":"b"`, `["a" "b"]`, `{"a":1} x`, ``} {
+		_, err := s.Sanitize(context.Background(), []byte(invalid))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func BenchmarkSanitizer_Sanitize(b *testing.B) {
+	keys := []string{"email", "handle"}
+	const maxChars = 20
+
+	b.Run("two pass transformer", func(b *testing.B) {
+		mask := jsonutil.NewTransformer(jsonutil.Config{StringTransformer: transformer(keys)})
+		truncate := jsonutil.NewTransformer(jsonutil.Config{
+			StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+				return jsonutil.TruncateString(info.Value, maxChars)
+			},
+		})
+
+		for i := 0; i < b.N; i++ {
+			out, err := mask.TransformBytes(context.Background(), []byte(largeArray))
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			_, err = truncate.TransformBytes(context.Background(), out)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("single pass sanitizer", func(b *testing.B) {
+		s := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{MaskKeys: keys, MaxChars: maxChars})
+		for i := 0; i < b.N; i++ {
+			_, err := s.Sanitize(context.Background(), []byte(largeArray))
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// countdownContext is cancelled after Err is called n times.
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (c *countdownContext) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+
+	c.n--
+	return nil
+}
+
+func TestSanitizer_PartialOnCancel(t *testing.T) {
+	in := []byte(`{"password":"secret","list":["a","b","c"]}`)
+
+	s := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{MaskKeys: []string{"password"}, PartialOnCancel: true})
+	out, err := s.Sanitize(&countdownContext{Context: context.Background(), n: 3}, in)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	var partial *jsonutil.PartialError
+	assert.True(t, errors.As(err, &partial))
+	assert.Equal(t, `{"password":"***","list":["a",`, string(out))
+	assert.Equal(t, `"b","c"]}`, string(in[partial.Offset:]))
+
+	s = jsonutil.NewSanitizer(jsonutil.SanitizerConfig{MaskKeys: []string{"password"}})
+	out, err = s.Sanitize(&countdownContext{Context: context.Background(), n: 3}, in)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, out)
+}
+
+func BenchmarkSanitizer_LongStrings(b *testing.B) {
+	doc, _ := json.Marshal(map[string]interface{}{
+		"body":  strings.Repeat("Lorem ipsum dolor sit amet, consectetur adipiscing elit. ", 2000),
+		"lines": []string{strings.Repeat("a\\\"b ", 1000), strings.Repeat("こんにちは", 1000)},
+	})
+
+	s := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{MaskKeys: []string{"password"}})
+	b.SetBytes(int64(len(doc)))
+	for i := 0; i < b.N; i++ {
+		if _, err := s.Sanitize(context.Background(), doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestSanitizer_StringValidation(t *testing.T) {
+	s := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{})
+	for _, in := range []string{
+		`"abc"`, `"a\\"`, `"a\"b"`, `"a\\\"b"`, `"é\n\t\/"`, `"long string without escape, longer than eight bytes"`,
+		"\"tab\tinside\"", "\"long string with control\x01character\"", `"\x"`, `"\u12G4"`, `"\u12"`, `"abc`, `"abc\"`, `"abc\`,
+	} {
+		out, err := s.Sanitize(context.Background(), []byte(in))
+		if json.Valid([]byte(in)) {
+			assert.NoError(t, err, in)
+			assert.Equal(t, in, string(out))
+		} else {
+			assert.Error(t, err, in)
+		}
+	}
+}
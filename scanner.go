@@ -0,0 +1,438 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// scanner is a minimal JSON byte scanner.
+// It never decode the document, instead it only returns offsets,
+// so the caller can get or replace one part of the document without touching the rest.
+
+// ErrUnexpectedEnd is matched (using errors.Is) by SyntaxError when the data ends in the middle of a JSON value.
+var ErrUnexpectedEnd = errors.New("jsonutil: unexpected end of JSON input")
+
+// SyntaxError is the position of invalid syntax in JSON document.
+type SyntaxError struct {
+	Msg     string // Msg describe the error, i.e: invalid character 'x' looking for beginning of value
+	Offset  int    // Offset is zero-based byte offset where the error occurred.
+	Line    int    // Line is one-based line number of Offset.
+	Column  int    // Column is one-based byte column of Offset in the Line.
+	Context string // Context is part of the document around Offset.
+
+	unexpectedEnd bool
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("jsonutil: %s at line %d, column %d (offset %d) near %q", e.Msg, e.Line, e.Column, e.Offset, e.Context)
+}
+
+func (e *SyntaxError) Is(target error) bool {
+	return target == ErrUnexpectedEnd && e.unexpectedEnd
+}
+
+// syntaxContextSize is the number of bytes before and after the offset in SyntaxError.Context.
+const syntaxContextSize = 16
+
+// newSyntaxError return SyntaxError with msg at offset i of data.
+func newSyntaxError(data []byte, i int, msg string) *SyntaxError {
+	if i > len(data) {
+		i = len(data)
+	}
+
+	lineStart := bytes.LastIndexByte(data[:i], '\n') + 1
+	from, to := i-syntaxContextSize, i+syntaxContextSize
+	if from < 0 {
+		from = 0
+	}
+
+	if to > len(data) {
+		to = len(data)
+	}
+
+	return &SyntaxError{
+		Msg:     msg,
+		Offset:  i,
+		Line:    bytes.Count(data[:i], []byte("\n")) + 1,
+		Column:  i - lineStart + 1,
+		Context: string(data[from:to]),
+	}
+}
+
+func syntaxErr(data []byte, i int, msg string) error {
+	if i >= len(data) {
+		err := newSyntaxError(data, i, "unexpected end of JSON input")
+		err.unexpectedEnd = true
+		return err
+	}
+
+	return newSyntaxError(data, i, fmt.Sprintf("invalid character %q %s", data[i], msg))
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// skipSpace return the first offset from i which is not a whitespace.
+func skipSpace(data []byte, i int) int {
+	for i < len(data) && isSpace(data[i]) {
+		i++
+	}
+
+	return i
+}
+
+// scanValue scan one JSON value starts at offset i (after whitespace),
+// and return the offset right after the value.
+// Object and array inside the value nested deeper than MaxDepth is rejected.
+func scanValue(data []byte, i int) (int, error) {
+	return scanNested(data, i, 0)
+}
+
+// scanNested is scanValue for value nested at depth.
+func scanNested(data []byte, i int, depth int) (int, error) {
+	if i >= len(data) {
+		return i, syntaxErr(data, i, "")
+	}
+
+	switch c := data[i]; {
+	case c == '"':
+		return scanString(data, i)
+	case c == '{':
+		if depth >= MaxDepth {
+			return i, depthErr(i)
+		}
+		return scanObjectNested(data, i, depth+1, nil)
+	case c == '[':
+		if depth >= MaxDepth {
+			return i, depthErr(i)
+		}
+		return scanArrayNested(data, i, depth+1, nil)
+	case c == 't':
+		return scanLiteral(data, i, "true")
+	case c == 'f':
+		return scanLiteral(data, i, "false")
+	case c == 'n':
+		return scanLiteral(data, i, "null")
+	case c == '-' || (c >= '0' && c <= '9'):
+		return scanNumber(data, i)
+	}
+
+	return i, syntaxErr(data, i, "looking for beginning of value")
+}
+
+func scanLiteral(data []byte, i int, literal string) (int, error) {
+	for n := 0; n < len(literal); n++ {
+		if i+n >= len(data) || data[i+n] != literal[n] {
+			return i, syntaxErr(data, i+n, "in literal "+literal)
+		}
+	}
+
+	return i + len(literal), nil
+}
+
+func scanNumber(data []byte, i int) (int, error) {
+	start := i
+	if data[i] == '-' {
+		i++
+	}
+
+	digits := func() int {
+		n := 0
+		for i < len(data) && data[i] >= '0' && data[i] <= '9' {
+			i++
+			n++
+		}
+		return n
+	}
+
+	if i < len(data) && data[i] == '0' {
+		i++
+	} else if digits() == 0 {
+		return start, syntaxErr(data, i, "in numeric literal")
+	}
+
+	if i < len(data) && data[i] == '.' {
+		i++
+		if digits() == 0 {
+			return start, syntaxErr(data, i, "after decimal point in numeric literal")
+		}
+	}
+
+	if i < len(data) && (data[i] == 'e' || data[i] == 'E') {
+		i++
+		if i < len(data) && (data[i] == '+' || data[i] == '-') {
+			i++
+		}
+
+		if digits() == 0 {
+			return start, syntaxErr(data, i, "in exponent of numeric literal")
+		}
+	}
+
+	return i, nil
+}
+
+// scanString scan JSON string starts at offset i (the opening quote),
+// and return the offset right after the closing quote.
+// Instead of reading byte by byte, it jumps to the next quote using bytes.IndexByte,
+// then looks back for escape sequence and control character in the skipped part.
+func scanString(data []byte, i int) (int, error) {
+	if i >= len(data) || data[i] != '"' {
+		return i, syntaxErr(data, i, "looking for beginning of string")
+	}
+
+	start := i
+	quote := -1 // offset of the next quote, searched again only after the escape sequence passed it
+	i++
+	for {
+		if quote < i {
+			q := bytes.IndexByte(data[i:], '"')
+			if q < 0 {
+				return scanStringSlow(data, start)
+			}
+
+			quote = i + q
+		}
+
+		span := data[i:quote]
+		b := bytes.IndexByte(span, '\\')
+		if b < 0 {
+			if hasControl(span) {
+				return scanStringSlow(data, start)
+			}
+
+			return quote + 1, nil
+		}
+
+		if hasControl(span[:b]) {
+			return scanStringSlow(data, start)
+		}
+
+		// skip the escape sequence, the next quote may be the escaped one
+		i += b + 1
+		if i >= len(data) {
+			return scanStringSlow(data, start)
+		}
+
+		switch data[i] {
+		case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			i++
+		case 'u':
+			if i+4 >= len(data) || !isHex(data[i+1]) || !isHex(data[i+2]) || !isHex(data[i+3]) || !isHex(data[i+4]) {
+				return scanStringSlow(data, start)
+			}
+			i += 5
+		default:
+			return scanStringSlow(data, start)
+		}
+	}
+}
+
+// hasControl return true if b contains byte lower than 0x20, which is not allowed inside JSON string.
+// It checks 8 bytes at once, see https://graphics.stanford.edu/~seander/bithacks.html#HasLessInWord
+func hasControl(b []byte) bool {
+	const lo, hi = 0x0101010101010101, 0x8080808080808080
+
+	i := 0
+	for ; i+8 <= len(b); i += 8 {
+		x := binary.LittleEndian.Uint64(b[i:])
+		if (x-lo*0x20)&^x&hi != 0 {
+			return true
+		}
+	}
+
+	for ; i < len(b); i++ {
+		if b[i] < 0x20 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scanStringSlow is the byte by byte version of scanString,
+// it is used to report the exact position of the syntax error.
+func scanStringSlow(data []byte, i int) (int, error) {
+	i++
+	for i < len(data) {
+		switch c := data[i]; {
+		case c == '"':
+			return i + 1, nil
+
+		case c == '\\':
+			i++
+			if i >= len(data) {
+				return i, syntaxErr(data, i, "")
+			}
+
+			switch data[i] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				i++
+			case 'u':
+				for n := 1; n <= 4; n++ {
+					if i+n >= len(data) || !isHex(data[i+n]) {
+						return i, syntaxErr(data, i+n, "in \\u hexadecimal character escape")
+					}
+				}
+				i += 5
+			default:
+				return i, syntaxErr(data, i, "in string escape code")
+			}
+
+		case c < 0x20:
+			return i, syntaxErr(data, i, "in string literal")
+
+		default:
+			i++
+		}
+	}
+
+	return i, syntaxErr(data, i, "")
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// unquote return the decoded value of JSON string data[start:end] (including quotes).
+func unquote(raw []byte) (string, error) {
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return string(raw[1 : len(raw)-1]), nil
+	}
+
+	var str string
+	err := json.Unmarshal(raw, &str)
+	return str, err
+}
+
+// objectMember is a position of one key-value inside an object.
+type objectMember struct {
+	keyStart   int // offset of key opening quote
+	valueStart int
+	valueEnd   int
+}
+
+// scanObject scan object starts at offset i (the opening brace).
+// When visit is not nil, it is called for every member, returning false will stop the scan
+// and scanObject return the offset of the stopped member value end.
+func scanObject(data []byte, i int, visit func(key string, m objectMember) bool) (int, error) {
+	return scanObjectNested(data, i, 1, visit)
+}
+
+func scanObjectNested(data []byte, i int, depth int, visit func(key string, m objectMember) bool) (int, error) {
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == '}' {
+		return i + 1, nil
+	}
+
+	for {
+		keyStart := i
+		keyEnd, err := scanString(data, i)
+		if err != nil {
+			return i, err
+		}
+
+		i = skipSpace(data, keyEnd)
+		if i >= len(data) || data[i] != ':' {
+			return i, syntaxErr(data, i, "after object key")
+		}
+
+		valueStart := skipSpace(data, i+1)
+		valueEnd, err := scanNested(data, valueStart, depth)
+		if err != nil {
+			return valueEnd, err
+		}
+
+		if visit != nil {
+			key, err := unquote(data[keyStart:keyEnd])
+			if err != nil {
+				return keyStart, err
+			}
+
+			if !visit(key, objectMember{keyStart: keyStart, valueStart: valueStart, valueEnd: valueEnd}) {
+				return valueEnd, nil
+			}
+		}
+
+		i = skipSpace(data, valueEnd)
+		if i >= len(data) {
+			return i, syntaxErr(data, i, "")
+		}
+
+		switch data[i] {
+		case ',':
+			i = skipSpace(data, i+1)
+		case '}':
+			return i + 1, nil
+		default:
+			return i, syntaxErr(data, i, "after object key:value pair")
+		}
+	}
+}
+
+// scanArray scan array starts at offset i (the opening bracket).
+// When visit is not nil, it is called for every element with its index and offsets,
+// returning false will stop the scan.
+func scanArray(data []byte, i int, visit func(idx, start, end int) bool) (int, error) {
+	return scanArrayNested(data, i, 1, visit)
+}
+
+func scanArrayNested(data []byte, i int, depth int, visit func(idx, start, end int) bool) (int, error) {
+	i = skipSpace(data, i+1)
+	if i < len(data) && data[i] == ']' {
+		return i + 1, nil
+	}
+
+	for idx := 0; ; idx++ {
+		start := i
+		end, err := scanNested(data, i, depth)
+		if err != nil {
+			return end, err
+		}
+
+		if visit != nil && !visit(idx, start, end) {
+			return end, nil
+		}
+
+		i = skipSpace(data, end)
+		if i >= len(data) {
+			return i, syntaxErr(data, i, "")
+		}
+
+		switch data[i] {
+		case ',':
+			i = skipSpace(data, i+1)
+		case ']':
+			return i + 1, nil
+		default:
+			return i, syntaxErr(data, i, "after array element")
+		}
+	}
+}
+
+// Validate return nil if doc is exactly one valid JSON value with optional surrounding whitespace,
+// otherwise the *SyntaxError (or ErrMaxDepthExceeded when nested deeper than MaxDepth).
+func Validate(doc []byte) error {
+	_, err := scanDocument(doc)
+	return err
+}
+
+// scanDocument validate that data contains exactly one JSON value, with optional surrounding whitespace.
+// It returns the offset of the value start.
+func scanDocument(data []byte) (int, error) {
+	start := skipSpace(data, 0)
+	end, err := scanValue(data, start)
+	if err != nil {
+		return start, err
+	}
+
+	if end = skipSpace(data, end); end < len(data) {
+		return start, syntaxErr(data, end, "after top-level value")
+	}
+
+	return start, nil
+}
@@ -0,0 +1,76 @@
+package jsonutil_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, jsonutil.Validate([]byte(" {\"a\": [1, \"x\", null]} \n")))
+
+	err := jsonutil.Validate([]byte("{\n  \"a\": 1,\n  \"b\": tru\n}"))
+	var syntaxErr *jsonutil.SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, `invalid character '\n' in literal true`, syntaxErr.Msg)
+	assert.Equal(t, 22, syntaxErr.Offset)
+	assert.Equal(t, 3, syntaxErr.Line)
+	assert.Equal(t, 11, syntaxErr.Column)
+	assert.Equal(t, "\": 1,\n  \"b\": tru\n}", syntaxErr.Context)
+	assert.EqualError(t, err, `jsonutil: invalid character '\n' in literal true at line 3, column 11 (offset 22) near "\": 1,\n  \"b\": tru\n}"`)
+
+	err = jsonutil.Validate([]byte(`{"a": [1, 2`))
+	assert.ErrorIs(t, err, jsonutil.ErrUnexpectedEnd)
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, 11, syntaxErr.Offset)
+
+	err = jsonutil.Validate([]byte(`{"a":1} x`))
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.Equal(t, `invalid character 'x' after top-level value`, syntaxErr.Msg)
+	assert.False(t, errors.Is(err, jsonutil.ErrUnexpectedEnd))
+}
+
+func TestSyntaxError_APIs(t *testing.T) {
+	doc := []byte("[1,\n2,\n{\"a\" 3}]")
+	s := jsonutil.NewSanitizer(jsonutil.SanitizerConfig{})
+
+	errs := map[string]error{}
+	_, errs["GetBytes"] = jsonutil.GetBytes(doc, "2.a")
+	_, errs["Sanitize"] = s.Sanitize(context.Background(), doc)
+	_, errs["Paths"] = jsonutil.Paths(doc)
+
+	for name, err := range errs {
+		var syntaxErr *jsonutil.SyntaxError
+		assert.True(t, errors.As(err, &syntaxErr), name)
+		assert.Equal(t, 3, syntaxErr.Line, name)
+		assert.Equal(t, 6, syntaxErr.Column, name)
+	}
+
+	_, err := jsonutil.Paths([]byte(`{"a":`))
+	assert.ErrorIs(t, err, jsonutil.ErrUnexpectedEnd)
+}
+
+func TestValidate_ManyEscapes(t *testing.T) {
+	doc := []byte(`{"a":"` + strings.Repeat(`\n\"\\é`, 1000) + `","b":"x"}`)
+	assert.NoError(t, jsonutil.Validate(doc))
+
+	doc = []byte(`{"a":"` + strings.Repeat(`\n`, 1000) + `\x"}`)
+	var syntaxErr *jsonutil.SyntaxError
+	assert.True(t, errors.As(jsonutil.Validate(doc), &syntaxErr))
+	assert.Equal(t, 2007, syntaxErr.Offset)
+}
+
+func BenchmarkValidate_ManyEscapes(b *testing.B) {
+	doc := []byte(`{"a":"` + strings.Repeat(`\n`, 100000) + `"}`)
+	b.SetBytes(int64(len(doc)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := jsonutil.Validate(doc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
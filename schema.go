@@ -0,0 +1,209 @@
+package jsonutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SensitiveKeyword is the custom JSON Schema keyword read by RulesFromSchema.
+// Its value is the Action: "mask", "hash", "drop", or "truncate" together with MaxCharsKeyword.
+const SensitiveKeyword = "x-sensitive"
+
+// MaxCharsKeyword is the custom JSON Schema keyword for the max_chars of "truncate" action.
+const MaxCharsKeyword = "x-max-chars"
+
+// RulesFromSchema derive the rules from JSON Schema annotated with SensitiveKeyword, i.e:
+//
+//	{"type": "object", "properties": {
+//	  "password": {"type": "string", "x-sensitive": "mask"},
+//	  "cards": {"type": "array", "items": {"properties": {"number": {"x-sensitive": "hash"}}}}
+//	}}
+//
+// produce rule mask on path "password" and hash on "cards.*.number".
+// It follows "properties", "items", "additionalProperties" (as "*" key), "allOf", "anyOf", "oneOf"
+// and local "$ref" (i.e: #/definitions/card or #/$defs/card). The rule id is "schema:<path>".
+// Rules are sorted by path.
+func RulesFromSchema(schema []byte) ([]Rule, error) {
+	var root interface{}
+	if err := decodeDocument(schema, &root); err != nil {
+		return nil, fmt.Errorf("jsonutil: cannot parse schema: %w", err)
+	}
+
+	w := newSchemaWalker(root, false)
+	if err := w.walk(root, []string{}, map[string]bool{}); err != nil {
+		return nil, err
+	}
+
+	return w.sortedRules(), nil
+}
+
+// NewRuleSetFromSchema is RulesFromSchema followed by NewRuleSet.
+func NewRuleSetFromSchema(schema []byte) (*RuleSet, error) {
+	rules, err := RulesFromSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRuleSet(rules)
+}
+
+type schemaWalker struct {
+	root  interface{} // root is the document where local $ref is resolved
+	rules map[string]Rule
+
+	// passwordFormat when true, schema with "format": "password" is masked unless it has SensitiveKeyword.
+	passwordFormat bool
+}
+
+func newSchemaWalker(root interface{}, passwordFormat bool) *schemaWalker {
+	return &schemaWalker{root: root, rules: map[string]Rule{}, passwordFormat: passwordFormat}
+}
+
+// sortedRules return the collected rules sorted by path.
+func (w *schemaWalker) sortedRules() []Rule {
+	paths := make([]string, 0, len(w.rules))
+	for path := range w.rules {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	rules := make([]Rule, len(paths))
+	for i, path := range paths {
+		rules[i] = w.rules[path]
+	}
+
+	return rules
+}
+
+// walk collect the rules of schema node on path segments, refs is the $ref being resolved to stop the cycle.
+func (w *schemaWalker) walk(node interface{}, segments []string, refs map[string]bool) error {
+	schema, ok := node.(map[string]interface{})
+	if !ok {
+		// boolean schema
+		return nil
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		if refs[ref] {
+			return nil
+		}
+
+		target, err := w.resolve(ref)
+		if err != nil {
+			return err
+		}
+
+		refs[ref] = true
+		err = w.walk(target, segments, refs)
+		delete(refs, ref)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := w.addRule(schema, segments); err != nil {
+		return err
+	}
+
+	if props, ok := schema["properties"].(map[string]interface{}); ok {
+		for key, prop := range props {
+			if err := w.walk(prop, append(segments[:len(segments):len(segments)], key), refs); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, keyword := range []string{"items", "additionalProperties"} {
+		child, ok := schema[keyword]
+		if !ok {
+			continue
+		}
+
+		// items may be array of schema (tuple validation in draft 4 to 2019-09)
+		children, isList := child.([]interface{})
+		if !isList {
+			children = []interface{}{child}
+		}
+
+		for _, c := range children {
+			if err := w.walk(c, append(segments[:len(segments):len(segments)], "*"), refs); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf"} {
+		subs, _ := schema[keyword].([]interface{})
+		for _, sub := range subs {
+			if err := w.walk(sub, segments, refs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *schemaWalker) addRule(schema map[string]interface{}, segments []string) error {
+	path := JoinPath(segments)
+	value, ok := schema[SensitiveKeyword]
+	if !ok {
+		if format, _ := schema["format"].(string); w.passwordFormat && format == "password" {
+			w.rules[path] = Rule{ID: "schema:" + path, Paths: []string{path}, Action: ActionMask}
+		}
+		return nil
+	}
+
+	action, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("jsonutil: schema %s on %q must be string", SensitiveKeyword, path)
+	}
+
+	rule := Rule{ID: "schema:" + path, Paths: []string{path}, Action: Action(action)}
+	if rule.Action == ActionTruncate {
+		maxChars, err := schemaInt(schema[MaxCharsKeyword])
+		if err != nil {
+			return fmt.Errorf("jsonutil: schema %s on %q: %w", MaxCharsKeyword, path, err)
+		}
+		rule.MaxChars = maxChars
+	}
+
+	if _, err := compileRule(rule); err != nil {
+		return fmt.Errorf("jsonutil: schema %s on %q: %w", SensitiveKeyword, path, err)
+	}
+
+	w.rules[path] = rule
+	return nil
+}
+
+// resolve return the schema node of local $ref, i.e: #/definitions/card
+func (w *schemaWalker) resolve(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("jsonutil: schema $ref %q is not supported, only local reference is", ref)
+	}
+
+	node := w.root
+	for _, seg := range SplitPath(strings.TrimPrefix(ref, "#")) {
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("jsonutil: schema $ref %q: %w", ref, ErrPathNotFound)
+		}
+
+		if node, ok = obj[seg]; !ok {
+			return nil, fmt.Errorf("jsonutil: schema $ref %q: %w", ref, ErrPathNotFound)
+		}
+	}
+
+	return node, nil
+}
+
+func schemaInt(v interface{}) (int, error) {
+	n, ok := v.(interface{ Int64() (int64, error) })
+	if !ok {
+		return 0, fmt.Errorf("must be integer")
+	}
+
+	i, err := n.Int64()
+	return int(i), err
+}
@@ -0,0 +1,83 @@
+package jsonutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+const sampleSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"properties": {
+		"password": {"type": "string", "x-sensitive": "mask"},
+		"bio": {"type": "string", "x-sensitive": "truncate", "x-max-chars": 5},
+		"internal": {"type": "object", "x-sensitive": "drop"},
+		"cards": {"type": "array", "items": {"$ref": "#/$defs/card"}},
+		"metadata": {"type": "object", "additionalProperties": {"properties": {"token": {"x-sensitive": "mask"}}}},
+		"contact": {"allOf": [{"properties": {"email": {"x-sensitive": "hash"}}}]},
+		"name": {"type": "string"}
+	},
+	"$defs": {
+		"card": {
+			"type": "object",
+			"properties": {
+				"number": {"type": "string", "x-sensitive": "hash"},
+				"next": {"$ref": "#/$defs/card"}
+			}
+		}
+	}
+}`
+
+func TestRulesFromSchema(t *testing.T) {
+	rules, err := jsonutil.RulesFromSchema([]byte(sampleSchema))
+	assert.NoError(t, err)
+	assert.Equal(t, []jsonutil.Rule{
+		{ID: "schema:bio", Paths: []string{"bio"}, Action: jsonutil.ActionTruncate, MaxChars: 5},
+		{ID: "schema:cards.*.number", Paths: []string{"cards.*.number"}, Action: jsonutil.ActionHash},
+		{ID: "schema:contact.email", Paths: []string{"contact.email"}, Action: jsonutil.ActionHash},
+		{ID: "schema:internal", Paths: []string{"internal"}, Action: jsonutil.ActionDrop},
+		{ID: "schema:metadata.*.token", Paths: []string{"metadata.*.token"}, Action: jsonutil.ActionMask},
+		{ID: "schema:password", Paths: []string{"password"}, Action: jsonutil.ActionMask},
+	}, rules)
+}
+
+func TestNewRuleSetFromSchema(t *testing.T) {
+	ruleSet, err := jsonutil.NewRuleSetFromSchema([]byte(sampleSchema))
+	assert.NoError(t, err)
+
+	doc := `{
+		"password": "secret",
+		"bio": "0123456789",
+		"internal": {"a": 1},
+		"cards": [{"number": "4111"}],
+		"metadata": {"github": {"token": "ghp"}},
+		"name": "alice"
+	}`
+	out, err := ruleSet.Process(context.Background(), []byte(doc))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"password": "***",
+		"bio": "01234 **escaped 5 chars**",
+		"cards": [{"number": "sha256:1f58dbec71994620de8abe61e744f76da60667b7b277ed4bead710a4b8e31ad0"}],
+		"metadata": {"github": {"token": "***"}},
+		"name": "alice"
+	}`, string(out))
+}
+
+func TestRulesFromSchema_Error(t *testing.T) {
+	for _, schema := range []string{
+		`{`,
+		`{"properties": {"a": {"x-sensitive": "encrypt"}}}`,
+		`{"properties": {"a": {"x-sensitive": true}}}`,
+		`{"properties": {"a": {"x-sensitive": "truncate"}}}`,
+		`{"properties": {"a": {"x-sensitive": "truncate", "x-max-chars": 1.5}}}`,
+		`{"properties": {"a": {"$ref": "#/$defs/missing"}}}`,
+		`{"properties": {"a": {"$ref": "other.json#/a"}}}`,
+	} {
+		_, err := jsonutil.RulesFromSchema([]byte(schema))
+		assert.Error(t, err, schema)
+	}
+}
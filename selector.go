@@ -0,0 +1,218 @@
+package jsonutil
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type selectorKind int
+
+const (
+	selectKey     selectorKind = iota // exact object key or array index
+	selectAny                         // * or [*], any single segment
+	selectDescent                     // .. zero or more segments
+)
+
+type selectorSegment struct {
+	kind selectorKind
+	name string
+}
+
+// Selector is a compiled JSONPath-like selector, matched against the path segments of a value.
+type Selector struct {
+	raw      string
+	segments []selectorSegment
+}
+
+// ParseSelector compile the JSONPath-like selector, supported syntax:
+//
+//	$.user.password      object key, the leading $ is optional (user.password)
+//	$['user']["name"]    quoted object key, for key with dot or bracket
+//	$.items[0].token     array index
+//	$.items[*].token     any array element, $.user.* for any object member
+//	$..password          password key at any depth
+func ParseSelector(selector string) (Selector, error) {
+	sel := Selector{raw: selector, segments: make([]selectorSegment, 0)}
+	s := strings.TrimPrefix(selector, "$")
+	if s != selector && s != "" && s[0] != '.' && s[0] != '[' {
+		return Selector{}, fmt.Errorf("jsonutil: invalid selector %q: unexpected %q after $", selector, s[0])
+	}
+
+	if s == selector && s != "" && s[0] != '.' && s[0] != '[' {
+		s = "." + s
+	}
+
+	for len(s) > 0 {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			sel.segments = append(sel.segments, selectorSegment{kind: selectDescent})
+			s = s[1:]
+			if len(s) > 1 && s[1] == '[' {
+				s = s[1:]
+			}
+
+		case s[0] == '.':
+			end := strings.IndexAny(s[1:], ".[]")
+			if end < 0 {
+				end = len(s) - 1
+			}
+
+			name := s[1 : end+1]
+			if name == "" {
+				return Selector{}, fmt.Errorf("jsonutil: invalid selector %q: empty key", selector)
+			}
+
+			sel.segments = append(sel.segments, nameSegment(name))
+			s = s[end+1:]
+
+		case s[0] == '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return Selector{}, fmt.Errorf("jsonutil: invalid selector %q: unclosed bracket", selector)
+			}
+
+			inner := s[1:end]
+			switch {
+			case inner == "*":
+				sel.segments = append(sel.segments, selectorSegment{kind: selectAny})
+
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				sel.segments = append(sel.segments, selectorSegment{kind: selectKey, name: inner[1 : len(inner)-1]})
+
+			default:
+				if idx, err := strconv.Atoi(inner); err != nil || idx < 0 {
+					return Selector{}, fmt.Errorf("jsonutil: invalid selector %q: invalid index %q", selector, inner)
+				}
+				sel.segments = append(sel.segments, selectorSegment{kind: selectKey, name: inner})
+			}
+
+			s = s[end+1:]
+
+		default:
+			return Selector{}, fmt.Errorf("jsonutil: invalid selector %q: unexpected %q", selector, s[0])
+		}
+	}
+
+	if n := len(sel.segments); n > 0 && sel.segments[n-1].kind == selectDescent {
+		return Selector{}, fmt.Errorf("jsonutil: invalid selector %q: missing key after ..", selector)
+	}
+
+	return sel, nil
+}
+
+func nameSegment(name string) selectorSegment {
+	if name == "*" {
+		return selectorSegment{kind: selectAny}
+	}
+
+	return selectorSegment{kind: selectKey, name: name}
+}
+
+// String return the selector as written.
+func (s Selector) String() string {
+	return s.raw
+}
+
+// Match return true if the value on path segments (see KVInfo.Path) is selected.
+func (s Selector) Match(path []string) bool {
+	return matchSelector(s.segments, path)
+}
+
+// specificity is the number of named segments, used to pick the selector when more than one is matched.
+func (s Selector) specificity() int {
+	n := 0
+	for _, seg := range s.segments {
+		if seg.kind == selectKey {
+			n++
+		}
+	}
+
+	return n
+}
+
+func matchSelector(segments []selectorSegment, path []string) bool {
+	if len(segments) == 0 {
+		return len(path) == 0
+	}
+
+	switch seg := segments[0]; seg.kind {
+	case selectDescent:
+		for i := 0; i <= len(path); i++ {
+			if matchSelector(segments[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+
+	case selectAny:
+		return len(path) > 0 && matchSelector(segments[1:], path[1:])
+
+	default:
+		return len(path) > 0 && path[0] == seg.name && matchSelector(segments[1:], path[1:])
+	}
+}
+
+// compileSelectors return the compiled selectors of raw, it panics on invalid selector.
+func compileSelectors(raw []string) []Selector {
+	compiled := make([]Selector, 0, len(raw))
+	for _, r := range raw {
+		compiled = append(compiled, mustParseSelector(r))
+	}
+
+	return compiled
+}
+
+// mustParseSelector is like ParseSelector but panics on invalid selector, same as regexp.MustCompile.
+func mustParseSelector(raw string) Selector {
+	sel, err := ParseSelector(raw)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return sel
+}
+
+// matchSubtree return true if path or one of its ancestors is selected by one of selectors.
+func matchSubtree(selectors []Selector, path []string) bool {
+	for _, sel := range selectors {
+		for n := 0; n <= len(path); n++ {
+			if sel.Match(path[:n]) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// pathTransformer is the compiled entry of Config.Paths.
+type pathTransformer struct {
+	selector    Selector
+	transformer StringTransformer
+}
+
+// compilePaths return the compiled selectors of paths, the most specific first. It panics on invalid selector.
+func compilePaths(paths map[string]StringTransformer) []pathTransformer {
+	compiled := make([]pathTransformer, 0, len(paths))
+	for raw, transformer := range paths {
+		sel := mustParseSelector(raw)
+		if transformer == nil {
+			continue
+		}
+
+		compiled = append(compiled, pathTransformer{selector: sel, transformer: transformer})
+	}
+
+	sort.Slice(compiled, func(i, j int) bool {
+		a, b := compiled[i].selector, compiled[j].selector
+		if a.specificity() != b.specificity() {
+			return a.specificity() > b.specificity()
+		}
+
+		return a.raw < b.raw
+	})
+
+	return compiled
+}
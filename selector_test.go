@@ -0,0 +1,126 @@
+package jsonutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestParseSelector(t *testing.T) {
+	testCases := []struct {
+		Selector string
+		Match    [][]string
+		NotMatch [][]string
+	}{
+		{
+			Selector: "$.user.credentials.password",
+			Match:    [][]string{{"user", "credentials", "password"}},
+			NotMatch: [][]string{{"metadata", "password"}, {"user", "credentials"}, {"user", "credentials", "password", "x"}},
+		},
+		{
+			Selector: "user.password",
+			Match:    [][]string{{"user", "password"}},
+			NotMatch: [][]string{{"password"}},
+		},
+		{
+			Selector: "$.items[*].token",
+			Match:    [][]string{{"items", "0", "token"}, {"items", "12", "token"}},
+			NotMatch: [][]string{{"items", "token"}, {"items", "0", "x", "token"}},
+		},
+		{
+			Selector: "$.items[1]['a.b'][\"c\"]",
+			Match:    [][]string{{"items", "1", "a.b", "c"}},
+			NotMatch: [][]string{{"items", "0", "a.b", "c"}},
+		},
+		{
+			Selector: "$..password",
+			Match:    [][]string{{"password"}, {"a", "b", "password"}},
+			NotMatch: [][]string{{"password", "x"}},
+		},
+		{
+			Selector: "$.user.*",
+			Match:    [][]string{{"user", "name"}},
+			NotMatch: [][]string{{"user"}, {"user", "a", "b"}},
+		},
+		{
+			Selector: "$..[*].id",
+			Match:    [][]string{{"a", "0", "id"}, {"0", "id"}},
+		},
+		{
+			Selector: "$",
+			Match:    [][]string{{}},
+			NotMatch: [][]string{{"a"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		sel, err := jsonutil.ParseSelector(tc.Selector)
+		assert.NoError(t, err, tc.Selector)
+		assert.Equal(t, tc.Selector, sel.String())
+
+		for _, path := range tc.Match {
+			assert.True(t, sel.Match(path), "%s %v", tc.Selector, path)
+		}
+
+		for _, path := range tc.NotMatch {
+			assert.False(t, sel.Match(path), "%s %v", tc.Selector, path)
+		}
+	}
+
+	for _, invalid := range []string{"$x", "$.a..", "$.a[", "$.a[x]", "$.a[-1]", "$.", "a..b..", "$.a]"} {
+		_, err := jsonutil.ParseSelector(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestTransformer_Paths(t *testing.T) {
+	mask := func(ctx context.Context, info jsonutil.KVInfo) string {
+		return "***"
+	}
+
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		Paths: map[string]jsonutil.StringTransformer{
+			"$.user.credentials.password": mask,
+			"$.items[*].token":            mask,
+			"$..secret":                   func(ctx context.Context, info jsonutil.KVInfo) string { return "secret" },
+			"$.config.secret":             func(ctx context.Context, info jsonutil.KVInfo) string { return "config" },
+		},
+	})
+
+	out, err := tr.TransformBytes(context.Background(), []byte(`{
+		"user": {"credentials": {"password": "p1"}},
+		"metadata": {"password": "p2"},
+		"items": [{"token": "t1"}, {"token": ["t2", "t3"]}, {"name": "n"}],
+		"a": {"b": {"secret": "s"}},
+		"config": {"secret": "s"}
+	}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"user": {"credentials": {"password": "***"}},
+		"metadata": {"password": "p2"},
+		"items": [{"token": "***"}, {"token": ["***", "***"]}, {"name": "n"}],
+		"a": {"b": {"secret": "secret"}},
+		"config": {"secret": "config"}
+	}`, string(out))
+
+	assert.PanicsWithValue(t, `jsonutil: invalid selector "$.invalid[": unclosed bracket`, func() {
+		jsonutil.NewTransformer(jsonutil.Config{Paths: map[string]jsonutil.StringTransformer{"$.invalid[": mask}})
+	})
+
+	assert.Panics(t, func() { jsonutil.NewTransformer(jsonutil.Config{Include: []string{"$.user..", "$x"}}) })
+	assert.Panics(t, func() { jsonutil.NewTransformer(jsonutil.Config{Exclude: []string{"$["}}) })
+
+	var paths [][]string
+	tr = jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			paths = append(paths, append([]string(nil), info.Path...))
+			return info.Value
+		},
+	})
+
+	_, err = tr.TransformBytes(context.Background(), []byte(`[{"a":["x"]}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"0", "a", "0"}}, paths)
+}
@@ -0,0 +1,189 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// DocShape is a compact structural summary of a JSON value.
+// For array, all elements are merged into single Elem shape,
+// so field that missing in some elements is marked as Optional.
+type DocShape struct {
+	Types    []Type               // Types seen in this position, excluding null.
+	Optional bool                 // Optional is true when the object field is missing in some elements of an array.
+	Nullable bool                 // Nullable is true when null is seen in this position.
+	Fields   map[string]*DocShape // Fields of object, nil if Types not contains Object.
+	Elem     *DocShape            // Elem is merged shape of array elements, nil if Types not contains Array or array always empty.
+}
+
+var _ json.Marshaler = (*DocShape)(nil)
+
+// Shape return structural summary of doc.
+func Shape(doc []byte) (*DocShape, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, err
+	}
+
+	return shapeOf(data), nil
+}
+
+func shapeOf(data interface{}) *DocShape {
+	switch v := data.(type) {
+	case nil:
+		return &DocShape{Types: []Type{}, Nullable: true}
+
+	case map[string]interface{}:
+		s := &DocShape{Types: []Type{Object}, Fields: make(map[string]*DocShape, len(v))}
+		for key, val := range v {
+			s.Fields[key] = shapeOf(val)
+		}
+		return s
+
+	case []interface{}:
+		s := &DocShape{Types: []Type{Array}}
+		for _, val := range v {
+			s.Elem = mergeShape(s.Elem, shapeOf(val))
+		}
+		return s
+	}
+
+	return &DocShape{Types: []Type{typeOf(data)}}
+}
+
+// mergeShape merge b into a, and return a.
+func mergeShape(a, b *DocShape) *DocShape {
+	if a == nil {
+		return b
+	}
+
+	if b == nil {
+		return a
+	}
+
+	aIsObject, bIsObject := a.hasType(Object), b.hasType(Object)
+	for _, t := range b.Types {
+		if !a.hasType(t) {
+			a.Types = append(a.Types, t)
+		}
+	}
+	sort.Slice(a.Types, func(i, j int) bool { return a.Types[i] < a.Types[j] })
+
+	a.Nullable = a.Nullable || b.Nullable
+	a.Optional = a.Optional || b.Optional
+	a.Elem = mergeShape(a.Elem, b.Elem)
+
+	switch {
+	case aIsObject && bIsObject:
+		for key, field := range a.Fields {
+			if _, exist := b.Fields[key]; !exist {
+				field.Optional = true
+			}
+		}
+
+		for key, field := range b.Fields {
+			if _, exist := a.Fields[key]; !exist {
+				field.Optional = true
+			}
+
+			a.Fields[key] = mergeShape(a.Fields[key], field)
+		}
+
+	case bIsObject:
+		a.Fields = b.Fields
+	}
+
+	return a
+}
+
+func (s *DocShape) hasType(t Type) bool {
+	for _, typ := range s.Types {
+		if typ == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TypeString return all types joined by "|", i.e: "string|null".
+func (s *DocShape) TypeString() string {
+	types := make([]string, 0, len(s.Types)+1)
+	for _, t := range s.Types {
+		types = append(types, t.String())
+	}
+
+	if s.Nullable {
+		types = append(types, Null.String())
+	}
+
+	if len(types) == 0 {
+		return "unknown"
+	}
+
+	return strings.Join(types, "|")
+}
+
+// String render the shape as indented text, field marked with "?" is optional:
+//
+//	object
+//	  items: array
+//	    []: object
+//	      name: string
+//	      note?: string|null
+func (s *DocShape) String() string {
+	var sb strings.Builder
+	sb.WriteString(s.TypeString())
+	sb.WriteString("\n")
+	s.writeChildren(&sb, 1)
+	return sb.String()
+}
+
+func (s *DocShape) writeChildren(sb *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	keys := make([]string, 0, len(s.Fields))
+	for key := range s.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field := s.Fields[key]
+		sb.WriteString(indent)
+		sb.WriteString(key)
+		if field.Optional {
+			sb.WriteString("?")
+		}
+		sb.WriteString(": ")
+		sb.WriteString(field.TypeString())
+		sb.WriteString("\n")
+		field.writeChildren(sb, depth+1)
+	}
+
+	if s.Elem != nil {
+		sb.WriteString(indent)
+		sb.WriteString("[]: ")
+		sb.WriteString(s.Elem.TypeString())
+		sb.WriteString("\n")
+		s.Elem.writeChildren(sb, depth+1)
+	}
+}
+
+// MarshalJSON render the shape as JSON, i.e: {"type":"object","fields":{"name":{"type":"string"}}}
+func (s *DocShape) MarshalJSON() ([]byte, error) {
+	type shapeJSON struct {
+		Type     string               `json:"type"`
+		Optional bool                 `json:"optional,omitempty"`
+		Fields   map[string]*DocShape `json:"fields,omitempty"`
+		Elem     *DocShape            `json:"elem,omitempty"`
+	}
+
+	return json.Marshal(shapeJSON{
+		Type:     s.TypeString(),
+		Optional: s.Optional,
+		Fields:   s.Fields,
+		Elem:     s.Elem,
+	})
+}
@@ -0,0 +1,42 @@
+package jsonutil_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestShape(t *testing.T) {
+	in := `{"id":1,"items":[{"name":"a","note":null},{"name":"b","note":"x","qty":2},null],"tags":[]}`
+	shape, err := jsonutil.Shape([]byte(in))
+	assert.NoError(t, err)
+
+	assert.Equal(t, `object
+  id: number
+  items: array
+    []: object|null
+      name: string
+      note: string|null
+      qty?: number
+  tags: array
+`, shape.String())
+
+	b, err := json.Marshal(shape.Fields["items"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"type":"array","elem":{"type":"object|null","fields":{
+		"name":{"type":"string"},
+		"note":{"type":"string|null"},
+		"qty":{"type":"number","optional":true}
+	}}}`, string(b))
+
+	_, err = jsonutil.Shape([]byte(`[`))
+	assert.Error(t, err)
+}
+
+func TestShape_MixedArray(t *testing.T) {
+	shape, err := jsonutil.Shape([]byte(`["a",1,true,["b"]]`))
+	assert.NoError(t, err)
+	assert.Equal(t, "array\n  []: array|string|number|boolean\n    []: string\n", shape.String())
+}
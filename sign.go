@@ -0,0 +1,134 @@
+package jsonutil
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ErrInvalidSignature is returned by Verify when the signature does not match the document.
+var ErrInvalidSignature = errors.New("jsonutil: invalid signature")
+
+// ErrDuplicateKey is returned by Canonicalize when an object has the same key more than once.
+var ErrDuplicateKey = errors.New("jsonutil: duplicate key")
+
+// ErrInvalidUTF8 is returned by Canonicalize when doc is not valid UTF-8.
+var ErrInvalidUTF8 = errors.New("jsonutil: invalid UTF-8")
+
+// Canonicalize return the canonical form of doc: object keys sorted, no insignificant whitespace,
+// and HTML characters not escaped. Numbers are kept as written, so 1.0 and 1 is different.
+// Two documents with the same content but different key order or formatting have the same canonical form.
+// Document which can be read in more than one way is rejected: duplicate key (ErrDuplicateKey),
+// invalid UTF-8 (ErrInvalidUTF8) and data after the top-level value.
+func Canonicalize(doc []byte) ([]byte, error) {
+	var data interface{}
+	if err := decodeUnambiguous(doc, &data); err != nil {
+		return nil, err
+	}
+
+	return canonicalJSON(data)
+}
+
+// decodeUnambiguous is decodeDocument which also reject invalid UTF-8 and duplicate keys,
+// so every reader of doc see the same value as the one signed or hashed.
+func decodeUnambiguous(doc []byte, data *interface{}) error {
+	if !utf8.Valid(doc) {
+		return ErrInvalidUTF8
+	}
+
+	if err := decodeDocument(doc, data); err != nil {
+		return err
+	}
+
+	return checkDuplicateKeys(json.NewDecoder(bytes.NewReader(doc)), nil)
+}
+
+// checkDuplicateKeys read the next value of dec and return ErrDuplicateKey when one of its objects has the same key twice.
+// The value must be already validated, i.e: using decodeDocument.
+func checkDuplicateKeys(dec *json.Decoder, path []string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		keys := make(map[string]struct{})
+		for dec.More() {
+			tok, err = dec.Token()
+			if err != nil {
+				return err
+			}
+
+			key, _ := tok.(string)
+			if _, exists := keys[key]; exists {
+				return fmt.Errorf("%w: %s", ErrDuplicateKey, JoinPath(append(path, key)))
+			}
+
+			keys[key] = struct{}{}
+			if err = checkDuplicateKeys(dec, append(path, key)); err != nil {
+				return err
+			}
+		}
+
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err = checkDuplicateKeys(dec, append(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return nil
+	}
+
+	// closing delimiter
+	_, err = dec.Token()
+	return err
+}
+
+func canonicalJSON(data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(data); err != nil {
+		return nil, err
+	}
+
+	// Encoder always add newline after the value
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Sign return the hex encoded HMAC-SHA256 of the canonical form of doc (see Canonicalize),
+// so the key order and whitespace does not affect the signature.
+func Sign(doc []byte, key []byte) (signature string, err error) {
+	canonical, err := Canonicalize(doc)
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(canonical)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verify return nil when signature is the valid Sign result of doc with key, otherwise ErrInvalidSignature.
+// The comparison is done in constant time.
+func Verify(doc []byte, key []byte, signature string) error {
+	expected, err := Sign(doc, key)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
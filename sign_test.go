@@ -0,0 +1,60 @@
+package jsonutil_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestCanonicalize(t *testing.T) {
+	out, err := jsonutil.Canonicalize([]byte(` { "b": [1, 2.50, {"z": null, "a": true}], "a": "<tag>&" } `))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":"<tag>&","b":[1,2.50,{"a":true,"z":null}]}`, string(out))
+
+	_, err = jsonutil.Canonicalize([]byte(`{`))
+	assert.Error(t, err)
+}
+
+func TestCanonicalize_Ambiguous(t *testing.T) {
+	_, err := jsonutil.Canonicalize([]byte(`{"amount":1,"amount":1000}`))
+	assert.ErrorIs(t, err, jsonutil.ErrDuplicateKey)
+
+	_, err = jsonutil.Canonicalize([]byte(`{"items":[{"id":1},{"id":2,"x":{"a":1,"a":1}}]}`))
+	assert.ErrorIs(t, err, jsonutil.ErrDuplicateKey)
+	assert.Contains(t, err.Error(), "items.1.x.a")
+
+	// same key in different objects is fine
+	_, err = jsonutil.Canonicalize([]byte(`{"a":{"a":1},"b":[{"a":1},{"a":2}]}`))
+	assert.NoError(t, err)
+
+	_, err = jsonutil.Canonicalize([]byte(`{"amount":1000} {"amount":1}`))
+	assert.Error(t, err)
+
+	_, err = jsonutil.Canonicalize([]byte("\"\xff\""))
+	assert.ErrorIs(t, err, jsonutil.ErrInvalidUTF8)
+
+	_, err = jsonutil.Canonicalize([]byte("{\"a\":\"\xfe\"}"))
+	assert.ErrorIs(t, err, jsonutil.ErrInvalidUTF8)
+}
+
+func TestSign(t *testing.T) {
+	key := []byte("webhook-secret")
+
+	sig, err := jsonutil.Sign([]byte(`{"event":"paid","amount":100}`), key)
+	assert.NoError(t, err)
+	assert.Len(t, sig, 64)
+
+	// same content, different key order and whitespace
+	assert.NoError(t, jsonutil.Verify([]byte("{\n  \"amount\": 100,\n  \"event\": \"paid\"\n}"), key, sig))
+
+	assert.ErrorIs(t, jsonutil.Verify([]byte(`{"event":"paid","amount":1000}`), key, sig), jsonutil.ErrInvalidSignature)
+	assert.ErrorIs(t, jsonutil.Verify([]byte(`{"event":"paid","amount":100}`), []byte("other"), sig), jsonutil.ErrInvalidSignature)
+	assert.Error(t, jsonutil.Verify([]byte(`{`), key, sig))
+
+	_, err = jsonutil.Sign([]byte(`[`), key)
+	assert.Error(t, err)
+
+	assert.ErrorIs(t, jsonutil.Verify([]byte(`{"event":"paid","amount":1,"amount":100}`), key, sig), jsonutil.ErrDuplicateKey)
+	assert.Error(t, jsonutil.Verify([]byte(`{"event":"paid","amount":100} {"amount":1}`), key, sig))
+}
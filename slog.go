@@ -0,0 +1,38 @@
+//go:build go1.21
+// +build go1.21
+
+package jsonutil
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+)
+
+type safeJSON struct {
+	b         []byte
+	processor Processor
+}
+
+var _ slog.LogValuer = (*safeJSON)(nil)
+
+// SafeJSON return slog.LogValuer which sanitize b using processors (i.e: masking then truncation)
+// only when the log record is actually emitted, so the cost is skipped for filtered-out logs.
+// The sanitized JSON is logged as raw JSON by slog.JSONHandler and as quoted string by slog.TextHandler.
+// When b is not a valid JSON, the error is logged instead of the original value.
+func SafeJSON(b []byte, processors ...Processor) slog.LogValuer {
+	return &safeJSON{b: b, processor: NewPipeline(processors...)}
+}
+
+func (s *safeJSON) LogValue() slog.Value {
+	out, err := s.processor.Process(context.Background(), s.b)
+	if err != nil {
+		return slog.StringValue("!ERROR: " + err.Error())
+	}
+
+	if !json.Valid(out) {
+		return slog.StringValue("!ERROR: invalid JSON")
+	}
+
+	return slog.AnyValue(json.RawMessage(out))
+}
@@ -0,0 +1,45 @@
+//go:build go1.21
+// +build go1.21
+
+package jsonutil_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestSafeJSON(t *testing.T) {
+	called := 0
+	mask := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			called++
+			if info.Key == "password" {
+				return "xxx"
+			}
+
+			return info.Value
+		},
+	})
+
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	body := []byte(`{"user":"a","password":"secret"}`)
+	logger.Debug("filtered", "body", jsonutil.SafeJSON(body, mask))
+	assert.Equal(t, 0, called)
+	assert.Empty(t, buf.String())
+
+	logger.Info("request", "body", jsonutil.SafeJSON(body, mask))
+	assert.Equal(t, 2, called)
+	assert.Contains(t, buf.String(), `"body":{"password":"xxx","user":"a"}`)
+
+	buf.Reset()
+	logger.Info("request", "body", jsonutil.SafeJSON([]byte(`{"password":`), mask))
+	assert.Contains(t, buf.String(), `"body":"!ERROR: `)
+	assert.NotContains(t, buf.String(), "password")
+}
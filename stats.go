@@ -0,0 +1,197 @@
+package jsonutil
+
+import (
+	"strconv"
+)
+
+// DocStats is statistics of a JSON document.
+type DocStats struct {
+	Keys     int // Keys is total object keys in all level.
+	MaxDepth int // MaxDepth is the deepest nesting of object or array, scalar-only document has depth 0.
+
+	Objects  int
+	Arrays   int
+	Strings  int
+	Numbers  int
+	Booleans int
+	Nulls    int
+
+	LargestString PathSize // LargestString is the longest string value, Size in bytes of the decoded value.
+	LargestArray  PathSize // LargestArray is the array with most elements, Size in number of elements.
+
+	Bytes ByteStats
+}
+
+// PathSize is the size of value on Path (dotted form).
+type PathSize struct {
+	Path string
+	Size int
+}
+
+// ByteStats is breakdown of document size in bytes.
+// String and key size is measured as written in the document, including the quotes and escape sequences.
+type ByteStats struct {
+	Total   int
+	Keys    int
+	Strings int
+	Numbers int
+	Other   int // Other is everything else: booleans, null, punctuation and whitespace.
+}
+
+// Stats collect statistics of doc, useful to decide a sane truncation budget.
+// The document is read once using the scanner, so the byte sizes are the exact spans of the tokens in doc.
+// When there is a tie, the largest string or array is the first one in the document.
+// Invalid doc is reported as error, instead of the statistics of the valid part.
+func Stats(doc []byte) (DocStats, error) {
+	if _, err := scanDocument(doc); err != nil {
+		return DocStats{}, err
+	}
+
+	c := statsCollector{doc: doc, largestArrayStart: -1}
+	c.collect()
+
+	c.stats.Bytes.Total = len(doc)
+	c.stats.Bytes.Other = c.stats.Bytes.Total - c.stats.Bytes.Keys - c.stats.Bytes.Strings - c.stats.Bytes.Numbers
+	return c.stats, nil
+}
+
+// statsFrame is an object or array which is not closed yet.
+type statsFrame struct {
+	array bool
+	start int // offset of the opening bracket
+	count int // number of elements, only for array
+}
+
+// statsCollector walk the validated document byte by byte, without recursion.
+type statsCollector struct {
+	doc   []byte
+	stats DocStats
+
+	frames    []statsFrame
+	segments  []string // path of the innermost open container
+	key       string   // the last key of the innermost object
+	expectKey bool
+
+	largestArrayStart int // offset of LargestArray opening bracket, -1 when there is no array
+}
+
+func (c *statsCollector) collect() {
+	doc := c.doc
+	for i := skipSpace(doc, 0); i < len(doc); i = skipSpace(doc, i) {
+		switch b := doc[i]; b {
+		case '{', '[':
+			c.open(i, b == '[')
+			i++
+
+		case '}', ']':
+			c.close()
+			i++
+
+		case ',':
+			c.expectKey = len(c.frames) > 0 && !c.frames[len(c.frames)-1].array
+			i++
+
+		case ':':
+			i++
+
+		case '"':
+			end, _ := scanString(doc, i)
+			str, _ := unquote(doc[i:end])
+			if c.expectKey {
+				c.stats.Keys++
+				c.stats.Bytes.Keys += end - i
+				c.key, c.expectKey = str, false
+			} else {
+				segment := c.element()
+				c.stats.Strings++
+				c.stats.Bytes.Strings += end - i
+				if c.stats.Strings == 1 || len(str) > c.stats.LargestString.Size {
+					c.stats.LargestString = PathSize{Path: c.path(segment), Size: len(str)}
+				}
+			}
+			i = end
+
+		default:
+			c.element()
+			end := i
+			switch b {
+			case 't':
+				c.stats.Booleans++
+				end += len("true")
+			case 'f':
+				c.stats.Booleans++
+				end += len("false")
+			case 'n':
+				c.stats.Nulls++
+				end += len("null")
+			default:
+				end, _ = scanNumber(doc, i)
+				c.stats.Numbers++
+				c.stats.Bytes.Numbers += end - i
+			}
+			i = end
+		}
+	}
+}
+
+// element count the value starts in the innermost container, and return its path segment.
+func (c *statsCollector) element() (segment string) {
+	if len(c.frames) == 0 {
+		return ""
+	}
+
+	top := &c.frames[len(c.frames)-1]
+	if !top.array {
+		return c.key
+	}
+
+	top.count++
+	return strconv.Itoa(top.count - 1)
+}
+
+// path return the dotted path of the value with segment inside the innermost container.
+func (c *statsCollector) path(segment string) string {
+	if len(c.frames) == 0 {
+		return ""
+	}
+
+	return JoinPath(append(c.segments[:len(c.segments):len(c.segments)], segment))
+}
+
+func (c *statsCollector) open(i int, array bool) {
+	if len(c.frames) > 0 {
+		c.segments = append(c.segments, c.element())
+	}
+
+	c.frames = append(c.frames, statsFrame{array: array, start: i})
+	if len(c.frames) > c.stats.MaxDepth {
+		c.stats.MaxDepth = len(c.frames)
+	}
+
+	if array {
+		c.stats.Arrays++
+	} else {
+		c.stats.Objects++
+	}
+
+	c.expectKey = !array
+}
+
+func (c *statsCollector) close() {
+	top := c.frames[len(c.frames)-1]
+	if top.array {
+		// the array which opened first wins the tie, the same as reading the document from the start
+		largest := &c.stats.LargestArray
+		if c.largestArrayStart < 0 || top.count > largest.Size || (top.count == largest.Size && top.start < c.largestArrayStart) {
+			*largest = PathSize{Path: JoinPath(c.segments), Size: top.count}
+			c.largestArrayStart = top.start
+		}
+	}
+
+	c.frames = c.frames[:len(c.frames)-1]
+	if len(c.frames) > 0 {
+		c.segments = c.segments[:len(c.segments)-1]
+	}
+
+	c.expectKey = false
+}
@@ -0,0 +1,72 @@
+package jsonutil_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestStats(t *testing.T) {
+	in := `{"a":"hello","b":[1,2,3],"c":{"d":[true,null,"hello world"]}}`
+	stats, err := jsonutil.Stats([]byte(in))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4, stats.Keys)
+	assert.Equal(t, 3, stats.MaxDepth)
+	assert.Equal(t, 2, stats.Objects)
+	assert.Equal(t, 2, stats.Arrays)
+	assert.Equal(t, 2, stats.Strings)
+	assert.Equal(t, 3, stats.Numbers)
+	assert.Equal(t, 1, stats.Booleans)
+	assert.Equal(t, 1, stats.Nulls)
+	assert.Equal(t, jsonutil.PathSize{Path: "c.d.2", Size: 11}, stats.LargestString)
+	assert.Equal(t, jsonutil.PathSize{Path: "b", Size: 3}, stats.LargestArray)
+
+	assert.Equal(t, jsonutil.ByteStats{
+		Total:   len(in),
+		Keys:    12,
+		Strings: 20,
+		Numbers: 3,
+		Other:   len(in) - 12 - 20 - 3,
+	}, stats.Bytes)
+}
+
+func TestStats_Scalar(t *testing.T) {
+	stats, err := jsonutil.Stats([]byte(`1`))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.MaxDepth)
+	assert.Equal(t, 1, stats.Numbers)
+	assert.Equal(t, jsonutil.PathSize{}, stats.LargestArray)
+
+	_, err = jsonutil.Stats([]byte(`{"a"}`))
+	assert.Error(t, err)
+}
+
+func TestStats_RawSize(t *testing.T) {
+	// sizes are measured on the document as is, json.Marshal would escape < into \u003c
+	in := `{"k<": "<<<<<<<<", "n": 1.50e+3, "e": "\u0041"}`
+	stats, err := jsonutil.Stats([]byte(in))
+	assert.NoError(t, err)
+	assert.Equal(t, jsonutil.ByteStats{
+		Total:   len(in),
+		Keys:    10,
+		Strings: 18,
+		Numbers: 7,
+		Other:   len(in) - 10 - 18 - 7,
+	}, stats.Bytes)
+	assert.Equal(t, jsonutil.PathSize{Path: "k<", Size: 8}, stats.LargestString)
+
+	stats, err = jsonutil.Stats([]byte(`"<<<<<<<<"`))
+	assert.NoError(t, err)
+	assert.Equal(t, jsonutil.ByteStats{Total: 10, Strings: 10}, stats.Bytes)
+}
+
+func TestStats_Tie(t *testing.T) {
+	stats, err := jsonutil.Stats([]byte(`{"b":[[1,2],["ab","cd"]],"a":"xy"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, jsonutil.PathSize{Path: "b", Size: 2}, stats.LargestArray)
+	assert.Equal(t, jsonutil.PathSize{Path: "b.1.0", Size: 2}, stats.LargestString)
+	assert.Equal(t, 3, stats.MaxDepth)
+	assert.Equal(t, 2, stats.Keys)
+}
@@ -0,0 +1,262 @@
+package jsonutil
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// ErrDocumentTooLarge is returned when a single document (or array element) exceed the configured maximum size.
+var ErrDocumentTooLarge = errors.New("jsonutil: document too large")
+
+// streamReadSize is the minimum number of bytes read from the underlying reader at once.
+const streamReadSize = 4096
+
+// StreamDecoder read back-to-back JSON documents from a stream, i.e: {"a":1}{"b":2}[3]
+// Documents may be separated by any whitespace, so NDJSON is accepted as well.
+// Unlike json.Decoder, it yields the raw bytes of each document, ready to be passed to Processor.
+type StreamDecoder struct {
+	r   io.Reader
+	buf []byte
+	pos int // start of the unread data in buf
+
+	offset  int64 // stream offset of buf[0]
+	eof     bool
+	err     error
+	maxSize int // maximum size of one document, zero means unlimited
+
+	line      int   // number of line breaks before buf[0]
+	lineStart int64 // stream offset of the line containing buf[0]
+	scan      elementScan
+
+	progress *progress
+}
+
+// NewStreamDecoder return StreamDecoder reading from r.
+func NewStreamDecoder(r io.Reader) *StreamDecoder {
+	return &StreamDecoder{r: r}
+}
+
+// Next return the raw bytes of the next document, or io.EOF when there is no more document.
+// The returned slice is only valid until the next call of Next, copy it to keep it longer.
+// Any error other than io.EOF is permanent: the following calls return the same error.
+func (d *StreamDecoder) Next() ([]byte, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	for {
+		start := skipSpace(d.buf, d.pos)
+		if start >= len(d.buf) {
+			d.pos = start
+			if d.eof {
+				d.err = io.EOF
+				d.progress.finish(d.InputOffset())
+				return nil, d.err
+			}
+
+			if err := d.fill(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// the document is validated once, after its end is read
+		if !d.eof && !d.scan.complete(d.buf[start:]) {
+			if d.maxSize > 0 && len(d.buf)-start > d.maxSize {
+				d.err = ErrDocumentTooLarge
+				return nil, d.err
+			}
+
+			d.pos = start
+			if err := d.fill(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		end, err := scanValue(d.buf, start)
+		if errors.Is(err, ErrUnexpectedEnd) && !d.eof {
+			d.pos = start
+			if err = d.fill(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err != nil {
+			d.err = d.streamError(err)
+			return nil, d.err
+		}
+
+		if d.maxSize > 0 && end-start > d.maxSize {
+			d.err = ErrDocumentTooLarge
+			return nil, d.err
+		}
+
+		d.scan = elementScan{}
+		d.pos = end
+		d.progress.update(d.InputOffset())
+		return d.buf[start:end], nil
+	}
+}
+
+// OnProgress set fn to be called every time at least interval bytes (DefaultProgressInterval when <= 0)
+// is consumed by Next, and once more when the end of stream is reached.
+// The total size is taken from r when it is *os.File or has Len method (i.e: bytes.Reader), otherwise -1.
+func (d *StreamDecoder) OnProgress(fn ProgressFunc, interval int64) {
+	d.setProgress(fn, readerSize(d.r), interval)
+}
+
+func (d *StreamDecoder) setProgress(fn ProgressFunc, total, interval int64) {
+	d.progress = nil
+	if fn != nil {
+		d.progress = newProgress(fn, total, interval)
+	}
+}
+
+// InputOffset return the stream offset right after the last document returned by Next.
+func (d *StreamDecoder) InputOffset() int64 {
+	return d.offset + int64(d.pos)
+}
+
+// peek return the next non-whitespace byte without consuming it, or io.EOF at the end of stream.
+func (d *StreamDecoder) peek() (byte, error) {
+	if d.err != nil {
+		return 0, d.err
+	}
+
+	for {
+		d.pos = skipSpace(d.buf, d.pos)
+		if d.pos < len(d.buf) {
+			return d.buf[d.pos], nil
+		}
+
+		if d.eof {
+			return 0, io.EOF
+		}
+
+		if err := d.fill(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// streamError convert the position of SyntaxError on d.buf into the position in the stream.
+func (d *StreamDecoder) streamError(err error) error {
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return err
+	}
+
+	if syntaxErr.Line == 1 {
+		syntaxErr.Column += int(d.offset - d.lineStart)
+	}
+
+	syntaxErr.Line += d.line
+	syntaxErr.Offset += int(d.offset)
+	return err
+}
+
+// fill discard the consumed data and read more from the underlying reader.
+func (d *StreamDecoder) fill() error {
+	if d.pos > 0 {
+		consumed := d.buf[:d.pos]
+		if lines := bytes.Count(consumed, []byte("\n")); lines > 0 {
+			d.line += lines
+			d.lineStart = d.offset + int64(bytes.LastIndexByte(consumed, '\n')+1)
+		}
+
+		n := copy(d.buf, d.buf[d.pos:])
+		d.offset += int64(d.pos)
+		d.buf = d.buf[:n]
+		d.pos = 0
+	}
+
+	if cap(d.buf)-len(d.buf) < streamReadSize {
+		newBuf := make([]byte, len(d.buf), 2*cap(d.buf)+streamReadSize)
+		copy(newBuf, d.buf)
+		d.buf = newBuf
+	}
+
+	n, err := d.r.Read(d.buf[len(d.buf):cap(d.buf)])
+	d.buf = d.buf[:len(d.buf)+n]
+	if err == io.EOF {
+		d.eof = true
+		return nil
+	}
+
+	if err != nil {
+		d.err = err
+	}
+
+	return err
+}
+
+// elementScan find the end of the document at the start of data, continuing where the previous call stopped,
+// so the document is not scanned again from its start after every read.
+type elementScan struct {
+	n        int // number of scanned bytes
+	depth    int
+	scalar   bool
+	inString bool
+	escaped  bool
+}
+
+// complete return true when data contains the end of the document, it may be still invalid.
+func (s *elementScan) complete(data []byte) bool {
+	if s.n == 0 && len(data) > 0 {
+		switch data[0] {
+		case '"':
+			s.inString = true
+		case '{', '[':
+			s.depth = 1
+		default:
+			s.scalar = true
+		}
+
+		s.n = 1
+	}
+
+	for ; s.n < len(data); s.n++ {
+		c := data[s.n]
+		switch {
+		case s.scalar:
+			// number or literal ends on the first byte which can't be part of it
+			if !isScalarByte(c) {
+				return true
+			}
+
+		case s.inString:
+			switch {
+			case s.escaped:
+				s.escaped = false
+			case c == '\\':
+				s.escaped = true
+			case c == '"':
+				s.inString = false
+				if s.depth == 0 {
+					return true
+				}
+			}
+
+		case c == '"':
+			s.inString = true
+
+		case c == '{' || c == '[':
+			s.depth++
+
+		case c == '}' || c == ']':
+			s.depth--
+			if s.depth == 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func isScalarByte(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '-' || c == '+' || c == '.'
+}
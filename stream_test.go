@@ -0,0 +1,157 @@
+package jsonutil_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func readAll(t *testing.T, d *jsonutil.StreamDecoder) ([]string, error) {
+	t.Helper()
+
+	docs := []string{}
+	for {
+		doc, err := d.Next()
+		if err == io.EOF {
+			return docs, nil
+		}
+
+		if err != nil {
+			return docs, err
+		}
+
+		docs = append(docs, string(doc))
+	}
+}
+
+func TestStreamDecoder(t *testing.T) {
+	input := `{"a":1}{"b":"}{"}[1,2]"str"12 true null` + "\n" + `{"c": {"d": []}}` + "\n"
+	expected := []string{`{"a":1}`, `{"b":"}{"}`, `[1,2]`, `"str"`, `12`, `true`, `null`, `{"c": {"d": []}}`}
+
+	t.Run("full reader", func(t *testing.T) {
+		docs, err := readAll(t, jsonutil.NewStreamDecoder(strings.NewReader(input)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, docs)
+	})
+
+	t.Run("one byte reader", func(t *testing.T) {
+		docs, err := readAll(t, jsonutil.NewStreamDecoder(iotest.OneByteReader(strings.NewReader(input))))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, docs)
+	})
+
+	t.Run("large document", func(t *testing.T) {
+		large := `{"a":"` + strings.Repeat("x", 10000) + `"}`
+		docs, err := readAll(t, jsonutil.NewStreamDecoder(strings.NewReader(large+large+"1")))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{large, large, "1"}, docs)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		docs, err := readAll(t, jsonutil.NewStreamDecoder(strings.NewReader(" \n ")))
+		assert.NoError(t, err)
+		assert.Empty(t, docs)
+	})
+}
+
+func TestStreamDecoder_InputOffset(t *testing.T) {
+	d := jsonutil.NewStreamDecoder(strings.NewReader(`{"a":1} {"b":2}`))
+	_, err := d.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), d.InputOffset())
+
+	_, err = d.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), d.InputOffset())
+}
+
+func TestStreamDecoder_Error(t *testing.T) {
+	t.Run("truncated", func(t *testing.T) {
+		docs, err := readAll(t, jsonutil.NewStreamDecoder(strings.NewReader(`{"a":1}{"b":`)))
+		assert.Equal(t, []string{`{"a":1}`}, docs)
+		assert.ErrorIs(t, err, jsonutil.ErrUnexpectedEnd)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		d := jsonutil.NewStreamDecoder(strings.NewReader(`{"a":1}xyz{"b":2}`))
+		docs, err := readAll(t, d)
+		assert.Equal(t, []string{`{"a":1}`}, docs)
+		assert.Error(t, err)
+
+		// error is permanent
+		_, err2 := d.Next()
+		assert.Equal(t, err, err2)
+	})
+
+	t.Run("reader error", func(t *testing.T) {
+		readErr := errors.New("read failed")
+		_, err := readAll(t, jsonutil.NewStreamDecoder(iotest.TimeoutReader(strings.NewReader(`{"a":1}{"b":`))))
+		assert.ErrorIs(t, err, iotest.ErrTimeout)
+
+		_, err = jsonutil.NewStreamDecoder(errReader{err: readErr}).Next()
+		assert.ErrorIs(t, err, readErr)
+	})
+}
+
+func TestStreamDecoder_OnProgress(t *testing.T) {
+	type call struct{ processed, total int64 }
+
+	input := `{"a":1} {"b":2} {"c":3} `
+	calls := []call{}
+	d := jsonutil.NewStreamDecoder(strings.NewReader(input))
+	d.OnProgress(func(processed, total int64) {
+		calls = append(calls, call{processed, total})
+	}, 10)
+
+	docs, err := readAll(t, d)
+	assert.NoError(t, err)
+	assert.Len(t, docs, 3)
+	assert.Equal(t, []call{{15, 24}, {24, 24}}, calls)
+
+	// unknown size
+	calls = calls[:0]
+	d = jsonutil.NewStreamDecoder(iotest.OneByteReader(strings.NewReader(input)))
+	d.OnProgress(func(processed, total int64) {
+		calls = append(calls, call{processed, total})
+	}, 0)
+
+	_, err = readAll(t, d)
+	assert.NoError(t, err)
+	assert.Equal(t, []call{{24, -1}}, calls)
+}
+
+func TestStreamDecoder_ErrorOffset(t *testing.T) {
+	input := strings.Repeat(`{"a":"`+strings.Repeat("x", 1000)+`"}`+"\n", 10) + `{"a": xyz}`
+	_, err := readAll(t, jsonutil.NewStreamDecoder(iotest.OneByteReader(strings.NewReader(input))))
+
+	var syntaxErr *jsonutil.SyntaxError
+	if assert.True(t, errors.As(err, &syntaxErr)) {
+		assert.Equal(t, len(input)-4, syntaxErr.Offset)
+		assert.Equal(t, 11, syntaxErr.Line)
+		assert.Equal(t, 7, syntaxErr.Column)
+	}
+}
+
+func BenchmarkStreamDecoder_LargeDocument(b *testing.B) {
+	doc := `{"a":"` + strings.Repeat("x", 1024*1024) + `"}`
+	b.SetBytes(int64(len(doc)))
+	for i := 0; i < b.N; i++ {
+		d := jsonutil.NewStreamDecoder(iotest.HalfReader(strings.NewReader(doc)))
+		if _, err := d.Next(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
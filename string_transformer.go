@@ -3,14 +3,70 @@ package jsonutil
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
+	"strconv"
+	"sync"
+	"time"
 )
 
+// Type is the kind of JSON value.
 type Type int
 
 const (
 	Object Type = iota
 	Array
+	String
+	Number
+	Boolean
+	Null
+)
+
+func (t Type) String() string {
+	switch t {
+	case Object:
+		return "object"
+	case Array:
+		return "array"
+	case String:
+		return "string"
+	case Number:
+		return "number"
+	case Boolean:
+		return "boolean"
+	case Null:
+		return "null"
+	}
+
+	return "unknown"
+}
+
+// typeOf return the JSON Type of decoded value v.
+func typeOf(v interface{}) Type {
+	switch v.(type) {
+	case map[string]interface{}:
+		return Object
+	case []interface{}:
+		return Array
+	case string:
+		return String
+	case bool:
+		return Boolean
+	case nil:
+		return Null
+	}
+
+	return Number
+}
+
+// Mode decide what happen to the string value which is not matched by Config.Paths, Config.Keys or Config.KeyPatterns.
+type Mode int
+
+const (
+	// Blacklist pass the value to Config.StringTransformer, so only the value it chooses is masked.
+	Blacklist Mode = iota
+	// Whitelist replace the value with Config.Placeholder unless its key is in Config.AllowKeys.
+	Whitelist
 )
 
 type KVInfo struct {
@@ -18,11 +74,91 @@ type KVInfo struct {
 	Inside     Type // Inside specify whether current Value is inside Object or Array.
 	Key        string
 	Value      string
+
+	// Path is the segments from the root to the Value, array index is written as string, i.e: ["items", "0", "token"].
+	// It is shared with the next call, so it must not be modified or retained.
+	Path []string
+
+	// Index is the position of the Value in its array when Inside is Array, otherwise -1.
+	Index int
+
+	// Depth is the number of object and array containing the Value, it is 1 for the member of top level object or array.
+	Depth int
+}
+
+// withPosition return info with Index and Depth filled from info.Path.
+func withPosition(info KVInfo) KVInfo {
+	info.Depth = len(info.Path)
+	info.Index = -1
+	if info.Inside == Array && len(info.Path) > 0 {
+		if i, err := strconv.Atoi(info.Path[len(info.Path)-1]); err == nil {
+			info.Index = i
+		}
+	}
+
+	return info
 }
 
 // StringTransformer is a function to replace value to new value.
 type StringTransformer func(ctx context.Context, info KVInfo) string
 
+// NumberTransformer is a function to replace number value, see Config.NumberTransformer.
+type NumberTransformer func(ctx context.Context, info KVInfo, value json.Number) json.Number
+
+// BoolTransformer is a function to replace boolean value, see Config.BoolTransformer.
+type BoolTransformer func(ctx context.Context, info KVInfo, value bool) bool
+
+// FilterAction is the decision of KeyFilter for an object member or array element.
+type FilterAction int
+
+const (
+	// FilterTransform transform the value as usual, it is the default.
+	FilterTransform FilterAction = iota
+	// FilterKeep write the value as is, nothing inside it is transformed.
+	FilterKeep
+	// FilterDelete remove the member from the object or the element from the array.
+	FilterDelete
+)
+
+// KeyFilter decide what to do with the value on info.Path, before it is transformed.
+// The info.Value is always empty, so the decision is only based on the location of the value.
+type KeyFilter func(ctx context.Context, info KVInfo) FilterAction
+
+// KeyTransformer is a function to rename object key, see Config.KeyTransformer.
+type KeyTransformer func(ctx context.Context, path []string, key string) string
+
+// ValueTransformer is a function to replace non-string value (number, boolean and null) to new value.
+// The info.Value is empty, value is the decoded value: float64 or json.Number (depends on Config.JSONUnmarshal), bool or nil.
+// Returning value as is keep it unchanged.
+type ValueTransformer func(ctx context.Context, info KVInfo, value interface{}) interface{}
+
+// PathFunc is the StringTransformer which receive the full path of the value explicitly,
+// so the same key on different location (i.e: user.token and admin.token) can be masked differently.
+// The path is the same as KVInfo.Path, it must not be modified or retained.
+type PathFunc func(ctx context.Context, path []string, key, value string) string
+
+// StringTransformer return f as StringTransformer, to be used in Config.
+func (f PathFunc) StringTransformer() StringTransformer {
+	return func(ctx context.Context, info KVInfo) string {
+		return f(ctx, info.Path, info.Key, info.Value)
+	}
+}
+
+// Chain return StringTransformer which call transformers in order, each one receive the value returned by the previous one,
+// i.e: Chain(truncate, mask, hash). Unlike NewPipeline of Transformer, the document is walked only once.
+// Nil transformer is skipped.
+func Chain(transformers ...StringTransformer) StringTransformer {
+	return func(ctx context.Context, info KVInfo) string {
+		for _, transformer := range transformers {
+			if transformer != nil {
+				info.Value = transformer(ctx, info)
+			}
+		}
+
+		return info.Value
+	}
+}
+
 // DefaultStringTransformer will not Transform any value.
 var DefaultStringTransformer StringTransformer = func(ctx context.Context, info KVInfo) string {
 	return info.Value
@@ -34,13 +170,143 @@ type Config struct {
 	// you can define your own json marshal or unmarshal for speed.
 	JSONMarshal   func(v interface{}) ([]byte, error)
 	JSONUnmarshal func(data []byte, v interface{}) error
+
+	// UseNumber make TransformBytes and TransformBytesPooled decode numbers as json.Number instead of float64,
+	// so untouched number such as int64 ID 9007199254740993 is written back exactly.
+	// It is ignored when JSONUnmarshal is set.
+	UseNumber bool
+
+	// Metrics receive the number of changed string values, size and latency of every document in TransformBytes and TransformBytesPooled.
+	Metrics Metrics
+
+	// Stats when not nil collect the number of visited and changed string values per key, see TransformStats.
+	Stats *TransformStats
+
+	// InPlace let Transform write the changed values directly into the nested maps and slices of the input,
+	// instead of copying them first. It saves allocation, but only use it when nobody else holds the input data.
+	// By default Transform never modify the input: the changed object or array is copied (copy-on-write),
+	// while the unchanged one is shared between the input and the output.
+	// TransformBytes always work in place since it owns the decoded data.
+	InPlace bool
+
+	// Paths replace StringTransformer for the string value on the matched location.
+	// The key is JSONPath-like selector, i.e: $.user.credentials.password, $.items[*].token or $..password, see ParseSelector.
+	// When the selector match an array, it applies to its string elements.
+	// When more than one selector match, the one with more named segments wins.
+	// NewTransformer panics on invalid selector, use ParseSelector to validate the configuration.
+	Paths map[string]StringTransformer
+
+	// Keys replace StringTransformer for the string value of these keys (or the string elements of array on these keys).
+	// It is copied by NewTransformer, use Transformer.AddKey, RemoveKey and ReplaceKeys to change it at runtime.
+	Keys map[string]StringTransformer
+
+	// KeyPatterns is like Keys but match the key using regular expression or glob, see RegexKey and GlobKey.
+	// The patterns are evaluated in order after Keys, so the exact key match always takes precedence.
+	KeyPatterns []KeyPattern
+
+	// MaskKeys rewrite the object key matched by the pattern, when the sensitive data is the key itself,
+	// i.e: {"john@example.com": {...}}. The pattern's StringTransformer receive the key as both KVInfo.Key and KVInfo.Value
+	// and return the new key, the value is kept under the new key and transformed as usual (using the original key and path).
+	// When two keys of the same object are rewritten into the same key, only one of them is kept.
+	MaskKeys []KeyPattern
+
+	// KeyTransformer when not nil rename every object key which is not matched by MaskKeys, i.e: snake_case to camelCase.
+	// The path is the same as KVInfo.Path (the key is the last segment), it must not be modified or retained.
+	// The value is transformed as usual using the original key and path.
+	// When the new key is already used in the same object, the transformation fail with ErrKeyCollision.
+	KeyTransformer KeyTransformer
+
+	// Detectors mask the sensitive data found inside every string value regardless of its key, i.e: DefaultDetectors().
+	// They are applied in order on the result of Paths, Keys, KeyPatterns or StringTransformer.
+	Detectors []Detector
+
+	// DecodeNestedJSON when true, string value which is a JSON object or array (i.e: "{\"password\":\"abc\"}")
+	// is decoded and transformed recursively, then encoded back into string. The path of nested value continue from the string,
+	// i.e: payload.password. It is applied after Paths, Keys and KeyPatterns, so those can still replace the whole string.
+	// When nothing inside is changed, the string is kept as is.
+	// It is not applied in Whitelist mode, there the whole string is masked unless its key is in AllowKeys.
+	DecodeNestedJSON bool
+
+	// DecodeBase64JSON is like DecodeNestedJSON, but for base64 string which decode to JSON object or array,
+	// i.e: {"data": "eyJwYXNzd29yZCI6ImEifQ=="}. The transformed JSON is encoded back using the same base64 encoding
+	// (standard or URL, with or without padding), so the downstream consumer still can decode it.
+	DecodeBase64JSON bool
+
+	// Mode is Blacklist (default) or Whitelist. In Whitelist mode every string value is masked with Placeholder,
+	// except the value of AllowKeys (or the string elements of array on these keys) which is kept as is.
+	// Paths, Keys and KeyPatterns still take precedence, i.e: use DefaultStringTransformer in Paths to allow one location.
+	// StringTransformer is not used in Whitelist mode.
+	Mode Mode
+
+	// AllowKeys is the safe keys in Whitelist mode.
+	AllowKeys []string
+
+	// Placeholder is the masked value in Whitelist mode, default to DefaultPlaceholder.
+	Placeholder string
+
+	// ValueTransformer when not nil is called for every number, boolean and null value,
+	// so numeric credit card number or salary can be masked too, i.e: replaced by "***" or 0.
+	// By default (nil) only string values are transformed.
+	ValueTransformer ValueTransformer
+
+	// NumberTransformer when not nil is called for every number value, i.e: to round or redact amount.
+	// The number is passed in its JSON form, and the returned one is encoded as is, so it must be a valid JSON number.
+	// It is called before ValueTransformer.
+	NumberTransformer NumberTransformer
+
+	// BoolTransformer when not nil is called for every boolean value, it is called before ValueTransformer.
+	BoolTransformer BoolTransformer
+
+	// Include when not empty limit Config.StringTransformer to the values inside the subtree selected by one of the selectors
+	// (see ParseSelector), i.e: $.request.body to truncate only the request body. The other values are kept as is.
+	// Paths, Keys and KeyPatterns are not affected. NewTransformer panics on invalid selector.
+	Include []string
+
+	// Exclude is like Include, but the values inside the selected subtree are not passed to Config.StringTransformer.
+	Exclude []string
+
+	// Parallelism when more than 1 split the elements of the top level array into that number of segments
+	// which are transformed concurrently, the output keeps the order of the elements.
+	// The transformers must be safe for concurrent use, and the elements must not share the same map or slice.
+	// TransformStream does not use it.
+	Parallelism int
+
+	// StreamIndent when not empty make TransformStream write every document indented with it (i.e: two spaces),
+	// the same as json.Indent. By default the output is compact.
+	StreamIndent string
+
+	// KeyFilter when not nil is called for every object member and array element of JSON,
+	// i.e: to drop "debug_stack" from the logged payload. It is not used by TransformStruct.
+	KeyFilter KeyFilter
+
+	// MaxDepth limit the nesting of object and array which is transformed, top level object or array is depth 1.
+	// The deeper subtree is returned unmodified, or Transform return ErrMaxDepthExceeded when MaxDepthError is true.
+	// Zero means no limit other than the package MaxDepth for JSON bytes.
+	MaxDepth int
+
+	// MaxDepthError is the policy when MaxDepth is exceeded, see MaxDepth.
+	MaxDepthError bool
 }
 
 type Transformer struct {
 	Config Config
+
+	paths   []pathTransformer
+	include []Selector
+	exclude []Selector
+	keys    *keyRegistry
+	hook    transformHook
+	codec   bool // Config.JSONMarshal or JSONUnmarshal is set by the caller
+	rawHTML bool // TransformStream doesn't escape <, > and & (see Truncate)
+
+	// topLevel when not nil transform the top level string in TransformStream (see Truncate)
+	topLevel func(str string) string
 }
 
+// NewTransformer return Transformer using conf.
+// It panics when a selector in Config.Paths, Include or Exclude is invalid, same as regexp.MustCompile.
 func NewTransformer(conf Config) *Transformer {
+	codec := conf.JSONMarshal != nil || conf.JSONUnmarshal != nil
 	if conf.StringTransformer == nil {
 		conf.StringTransformer = DefaultStringTransformer
 	}
@@ -49,21 +315,87 @@ func NewTransformer(conf Config) *Transformer {
 		conf.JSONMarshal = json.Marshal
 	}
 
+	if conf.JSONUnmarshal == nil && conf.UseNumber {
+		conf.JSONUnmarshal = unmarshalUseNumber
+	}
+
 	if conf.JSONUnmarshal == nil {
 		conf.JSONUnmarshal = json.Unmarshal
 	}
 
-	return &Transformer{Config: conf}
+	if conf.Metrics == nil {
+		conf.Metrics = NopMetrics{}
+	}
+
+	if conf.Placeholder == "" {
+		conf.Placeholder = DefaultPlaceholder
+	}
+
+	if conf.Mode == Whitelist {
+		conf.StringTransformer = allowKeysTransformer(conf.AllowKeys, conf.Placeholder)
+	}
+
+	return &Transformer{
+		Config:  conf,
+		paths:   compilePaths(conf.Paths),
+		include: compileSelectors(conf.Include),
+		exclude: compileSelectors(conf.Exclude),
+		keys:    newKeyRegistry(conf.Keys),
+		codec:   codec,
+	}
+}
+
+func (m *Transformer) TransformBytes(ctx context.Context, b []byte) (out []byte, err error) {
+	if _, nop := m.Config.Metrics.(NopMetrics); m.Config.Metrics == nil || nop {
+		return m.transformBytes(ctx, b)
+	}
+
+	begin := time.Now()
+	changed := 0
+	out, err = m.counting(&changed).transformBytes(ctx, b)
+	observe(m.Config.Metrics, b, begin, changed, 0, err)
+	return out, err
+}
+
+// counting return a copy of m which count the changed values into changed, for Config.Metrics.
+// The counter is on the copy, so concurrent documents don't share it.
+func (m *Transformer) counting(changed *int) *Transformer {
+	return m.withHook(func(info KVInfo, transformer interface{}, before, after interface{}) {
+		if !sameValue(before, after) {
+			*changed++
+		}
+	})
+}
+
+// transformHook is called after every transformer call, transformer is the StringTransformer or ValueTransformer called.
+type transformHook func(info KVInfo, transformer interface{}, before, after interface{})
+
+// withHook return a copy of m which call hook after every transformer call, after the hook of m (if any).
+func (m *Transformer) withHook(hook transformHook) *Transformer {
+	hooked := *m
+	hooked.hook = hook
+	if inner := m.hook; inner != nil {
+		hooked.hook = func(info KVInfo, transformer interface{}, before, after interface{}) {
+			inner(info, transformer, before, after)
+			hook(info, transformer, before, after)
+		}
+	}
+
+	return &hooked
 }
 
-func (m *Transformer) TransformBytes(ctx context.Context, b []byte) ([]byte, error) {
+func (m *Transformer) transformBytes(ctx context.Context, b []byte) ([]byte, error) {
+	if err := checkDepth(b); err != nil {
+		return nil, err
+	}
+
 	var data interface{}
 	err := m.Config.JSONUnmarshal(b, &data)
 	if err != nil {
 		return nil, err
 	}
 
-	out, err := m.Transform(ctx, data)
+	out, err := m.inPlace().transform(ctx, data)
 	if err != nil {
 		return nil, err
 	}
@@ -71,12 +403,70 @@ func (m *Transformer) TransformBytes(ctx context.Context, b []byte) ([]byte, err
 	return m.Config.JSONMarshal(out)
 }
 
+// inPlace return Transformer with Config.InPlace set, for transforming data owned by the caller.
+func (m *Transformer) inPlace() *Transformer {
+	if m.Config.InPlace {
+		return m
+	}
+
+	owner := *m
+	owner.Config.InPlace = true
+	return &owner
+}
+
+// Process implements Processor, it is the same as TransformBytes.
+func (m *Transformer) Process(ctx context.Context, doc []byte) ([]byte, error) {
+	return m.TransformBytes(ctx, doc)
+}
+
 // Transform will handle masking of JSON string value only.
 // Any value like object, array, number and null will not be masked.
 // This function will walk to every JSON array element and object value.
 // Means that if you have an object `{a: {b: ""}}` then you can mask the value on key b.
 // This also applies in array [{a: {b: ""}}].
+// The data is never modified unless Config.InPlace is set, so it can be transformed again or shared elsewhere.
+// Self-referential data (i.e: a map which contains itself) is rejected with ErrCyclicData.
+// Struct or pointer to struct is transformed the same way as TransformStruct on its deep copy,
+// the copy is returned (with the same type) and data is never modified, even with Config.InPlace.
+// Typed map or slice (i.e: map[string]int) is returned as map[string]interface{} or []interface{}
+// when the transformer return a value which doesn't fit its element type, i.e: "***" for a number.
 func (m *Transformer) Transform(ctx context.Context, data interface{}) (interface{}, error) {
+	if err := checkCycle(data, make(map[uintptr]struct{})); err != nil {
+		return nil, err
+	}
+
+	return m.transform(ctx, data)
+}
+
+// transformError is used to abort the transformation from the recursion, it is recovered by transform.
+type transformError struct {
+	err error
+}
+
+// transform is Transform without checking the cycle, for data decoded from JSON which can't have one.
+func (m *Transformer) transform(ctx context.Context, data interface{}) (out interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			abort, ok := r.(transformError)
+			if !ok {
+				panic(r)
+			}
+
+			err = abort.err
+		}
+	}()
+
+	// decoded JSON is handled without reflect, the path backing array is reused by every value
+	switch v := data.(type) {
+	case nil:
+		// null is a valid JSON
+		return nil, nil
+	case map[string]interface{}:
+		return m.maskMapInterface(ctx, v, make([]string, 0, 16)), nil
+	case []interface{}:
+		return m.maskSliceInterface(ctx, "", v, make([]string, 0, 16)), nil
+	}
+
 	original := reflect.ValueOf(data)
 	kind := original.Kind()
 	altered := reflect.New(original.Type()).Elem()
@@ -86,6 +476,15 @@ func (m *Transformer) Transform(ctx context.Context, data interface{}) (interfac
 		altered = m.maskMap(ctx, original)
 	case reflect.Slice, reflect.Array:
 		altered = m.maskSlice(ctx, original)
+	case reflect.Struct, reflect.Ptr:
+		if kind == reflect.Ptr && (original.IsNil() || original.Elem().Kind() != reflect.Struct) {
+			altered.Set(original)
+			break
+		}
+
+		if altered, err = m.transformStructCopy(ctx, original); err != nil {
+			return nil, err
+		}
 	default:
 		// string only such as "abc" is a valid JSON.
 		altered.Set(original)
@@ -94,7 +493,7 @@ func (m *Transformer) Transform(ctx context.Context, data interface{}) (interfac
 	return altered.Interface(), nil
 }
 
-// maskMap will always call when we found top level object, so isTopElem wil always true.
+// maskMap will always call when we found top level object other than map[string]interface{}, so isTopElem wil always true.
 func (m *Transformer) maskMap(ctx context.Context, elem reflect.Value) (altered reflect.Value) {
 	altered = reflect.MakeMapWithSize(elem.Type(), len(elem.MapKeys()))
 	mapRange := elem.MapRange()
@@ -106,39 +505,72 @@ func (m *Transformer) maskMap(ctx context.Context, elem reflect.Value) (altered
 			continue
 		}
 
+		path := []string{mapRange.Key().Interface().(string)}
+		action := m.filterKey(ctx, KVInfo{IsTopLevel: true, Inside: Object, Key: path[0], Path: path})
+		if action == FilterDelete {
+			continue
+		}
+
+		key := mapRange.Key()
+		if newKey := m.transformKey(ctx, path, true); newKey != path[0] {
+			key = reflect.ValueOf(newKey)
+		}
+
+		if m.Config.KeyTransformer != nil && altered.MapIndex(key).IsValid() {
+			m.keyCollision(path, key.String())
+		}
+
+		if action == FilterKeep {
+			altered.SetMapIndex(key, mapRange.Value())
+			continue
+		}
+
 		// value must be string in order to mask
 		switch mapRange.Value().Interface().(type) {
 		case string:
 			// top level kv string, e.g: {"a": "b"}
 			// this will handle on value part: "b"
-			v := m.Config.StringTransformer(ctx, KVInfo{
+			v := m.transformString(ctx, KVInfo{
 				IsTopLevel: true,
 				Inside:     Object,
 				Key:        mapRange.Key().Interface().(string),
 				Value:      mapRange.Value().Interface().(string),
+				Path:       path,
 			})
 
-			altered.SetMapIndex(mapRange.Key(), reflect.ValueOf(v))
+			altered = setMapValue(altered, key, v)
 
 		case map[string]interface{}:
 			// top level kv, with v contains object, e.g: {"foo": {"a": "b"}}
 			// this will handle on value part: {"a": "b"}
-			v := m.maskMapInterface(ctx, mapRange.Value().Interface().(map[string]interface{}))
-			altered.SetMapIndex(mapRange.Key(), reflect.ValueOf(v))
+			v := m.maskMapInterface(ctx, mapRange.Value().Interface().(map[string]interface{}), path)
+			altered.SetMapIndex(key, reflect.ValueOf(v))
 
 		case []interface{}:
 			// top level kv with v contains mixed element on array, e.g: {"foo": ["a",1]}
 			// this will handle on part ["a",1]
 			values := mapRange.Value().Interface().([]interface{})
-			newArr := m.maskSliceInterface(ctx, mapRange.Key().String(), values)
+			newArr := m.maskSliceInterface(ctx, mapRange.Key().String(), values, path)
 
-			altered.SetMapIndex(mapRange.Key(), reflect.ValueOf(newArr))
+			altered.SetMapIndex(key, reflect.ValueOf(newArr))
 
 		default:
 			// top level kv, with v contains type but not string,
 			// e.g: {"foo": 1}
 			// this will handle on value part: 1
-			altered.SetMapIndex(mapRange.Key(), mapRange.Value())
+			if !m.transformsValue() {
+				altered.SetMapIndex(key, mapRange.Value())
+				break
+			}
+
+			v := m.transformValue(ctx, KVInfo{
+				IsTopLevel: true,
+				Inside:     Object,
+				Key:        mapRange.Key().Interface().(string),
+				Path:       path,
+			}, mapRange.Value().Interface())
+
+			altered = setMapValue(altered, key, v)
 		}
 
 	}
@@ -146,21 +578,52 @@ func (m *Transformer) maskMap(ctx context.Context, elem reflect.Value) (altered
 	return
 }
 
-func (m *Transformer) maskMapInterface(ctx context.Context, myMap map[string]interface{}) map[string]interface{} {
+// maskMapInterface transform object, path is empty for the top level one. Unless Config.InPlace is set,
+// myMap is copied before the first changed value is written, so the caller's map is never modified.
+func (m *Transformer) maskMapInterface(ctx context.Context, myMap map[string]interface{}, path []string) map[string]interface{} {
+	if m.tooDeep(path) {
+		return myMap
+	}
+
+	altered, copied := myMap, m.Config.InPlace
+	var renamed map[string]string
 	for k, v := range myMap {
+		var newVal interface{}
+		childPath := append(path, k)
+
+		action := m.filterKey(ctx, KVInfo{IsTopLevel: len(path) == 0, Inside: Object, Key: k, Path: childPath})
+		if action == FilterDelete {
+			if !copied {
+				altered = copyMap(myMap)
+				copied = true
+			}
+
+			delete(altered, k)
+			continue
+		}
+
+		if newKey := m.transformKey(ctx, childPath, len(path) == 0); newKey != k {
+			if renamed == nil {
+				renamed = make(map[string]string)
+			}
+			renamed[k] = newKey
+		}
+
+		if action == FilterKeep {
+			continue
+		}
 
 		switch v.(type) {
 		case string:
 			// when passed object {"foo": "bar"}, this will handle value "bar" as string
-			transformedVal := m.Config.StringTransformer(ctx, KVInfo{
-				IsTopLevel: false,
+			newVal = m.transformString(ctx, KVInfo{
+				IsTopLevel: len(path) == 0,
 				Inside:     Object,
 				Key:        k,
 				Value:      v.(string),
+				Path:       childPath,
 			})
 
-			myMap[k] = transformedVal
-
 		case map[string]interface{}:
 			// When passed object contains object: {"foo":{"another_obj":{"foo":"bar"}}},
 			// this will handle value {"another_obj":{"foo":"bar"}} as map[string]interface{}
@@ -169,92 +632,732 @@ func (m *Transformer) maskMapInterface(ctx context.Context, myMap map[string]int
 			// No need to check if key is in whitelist or not, because we do recursive call.
 			// Hence, only when the final value is string or slice
 			// we must check whether we should continue to mask or not.
-			myMap[k] = m.maskMapInterface(ctx, v.(map[string]interface{}))
+			newVal = m.maskMapInterface(ctx, v.(map[string]interface{}), childPath)
 
 		case []interface{}:
 			// When passed object contains array {"foo":{"another_obj":[{"foo":"bar"}]}}
 			// This will handle each element on foo {"another_obj":[{"foo":"bar"}]} and call to slice interface.
-			myMap[k] = m.maskSliceInterface(ctx, k, v.([]interface{}))
+			newVal = m.maskSliceInterface(ctx, k, v.([]interface{}), childPath)
 
 		default:
-			// When passed object contains elements other than string, object kv string or array, it will keep default.
+			// When passed object contains elements other than string, object kv string or array, it will keep default
+			// unless Config.ValueTransformer is set.
 			// e.g: {"foo": {"foo": 1}}, this will handle {"foo": 1} and
-			// detect that 1 as integer and pass the original value to myMap.
-			myMap[k] = v
+			// detect that 1 as integer and keep the original value.
+			if !m.transformsValue() {
+				continue
+			}
+
+			newVal = m.transformValue(ctx, KVInfo{
+				IsTopLevel: len(path) == 0,
+				Inside:     Object,
+				Key:        k,
+				Path:       childPath,
+			}, v)
+		}
+
+		if sameValue(v, newVal) {
+			continue
 		}
 
+		if !copied {
+			altered = copyMap(myMap)
+			copied = true
+		}
+
+		altered[k] = newVal
+	}
+
+	// renamed after the iteration, since the key added while iterating the map may be visited again
+	if len(renamed) > 0 {
+		if !copied {
+			altered = copyMap(myMap)
+		}
+
+		// remove all the old keys first, so the key can be renamed into another renamed key, i.e: a to b and b to c
+		values := make(map[string]interface{}, len(renamed))
+		for oldKey := range renamed {
+			values[oldKey] = altered[oldKey]
+			delete(altered, oldKey)
+		}
+
+		for oldKey, newKey := range renamed {
+			if _, exists := altered[newKey]; exists && m.Config.KeyTransformer != nil {
+				m.keyCollision(append(path, oldKey), newKey)
+			}
+
+			altered[newKey] = values[oldKey]
+		}
+	}
+
+	return altered
+}
+
+// copyMap return the shallow copy of myMap.
+func copyMap(myMap map[string]interface{}) map[string]interface{} {
+	altered := make(map[string]interface{}, len(myMap))
+	for key, val := range myMap {
+		altered[key] = val
 	}
 
-	return myMap
+	return altered
 }
 
-// maskSlice will always call when we found top level array, so isTopElem wil always true.
+// maskSlice will always call when we found top level array other than []interface{}, so isTopElem wil always true.
 func (m *Transformer) maskSlice(ctx context.Context, elem reflect.Value) (altered reflect.Value) {
 	altered = reflect.MakeSlice(elem.Type(), elem.Len(), elem.Len())
+	n := 0 // number of elements which is not deleted
 	for i := 0; i < elem.Len(); i++ {
 		value := elem.Index(i)
+		path := []string{strconv.Itoa(i)}
+
+		action := m.filterKey(ctx, KVInfo{IsTopLevel: true, Inside: Array, Path: path})
+		if action == FilterDelete {
+			continue
+		}
+
+		if action == FilterKeep {
+			altered.Index(n).Set(value)
+			n++
+			continue
+		}
 
 		switch value.Interface().(type) {
 		case string:
 			// this is top level element, such as ["a","b"]
-			v := m.Config.StringTransformer(ctx, KVInfo{
+			v := m.transformString(ctx, KVInfo{
 				IsTopLevel: true,
 				Inside:     Array,
 				Key:        "",
 				Value:      value.Interface().(string),
+				Path:       path,
 			})
 
-			altered.Index(i).Set(reflect.ValueOf(v))
+			altered = setSliceValue(altered, n, v)
 
 		case map[string]interface{}:
 			// top level with array of object: [{"a":"b"}]
-			v := m.maskMapInterface(ctx, value.Interface().(map[string]interface{}))
-			altered.Index(i).Set(reflect.ValueOf(v))
+			v := m.maskMapInterface(ctx, value.Interface().(map[string]interface{}), path)
+			altered.Index(n).Set(reflect.ValueOf(v))
 
 		case []interface{}:
 			// top level array, contains another array, multi-dimension array, e.g: [[{"foo":"bar"}]]
-			v := m.maskSliceInterface(ctx, "", value.Interface().([]interface{}))
-			altered.Index(i).Set(reflect.ValueOf(v))
+			v := m.maskSliceInterface(ctx, "", value.Interface().([]interface{}), path)
+			altered.Index(n).Set(reflect.ValueOf(v))
 
 		default:
 			// mixed content of top level array, e.g: ["amount", 100, {"a":"b"}]
 			// or [1,2.2]
-			altered.Index(i).Set(value)
+			if !m.transformsValue() {
+				altered.Index(n).Set(value)
+				break
+			}
+
+			v := m.transformValue(ctx, KVInfo{
+				IsTopLevel: true,
+				Inside:     Array,
+				Key:        "",
+				Path:       path,
+			}, value.Interface())
+
+			altered = setSliceValue(altered, n, v)
 		}
+
+		n++
 	}
 
-	return
+	return altered.Slice(0, n)
 }
 
-func (m *Transformer) maskSliceInterface(ctx context.Context, key string, slices []interface{}) []interface{} {
-	newSlices := make([]interface{}, len(slices))
+// maskSliceInterface transform array, with the same copy-on-write rule as maskMapInterface.
+func (m *Transformer) maskSliceInterface(ctx context.Context, key string, slices []interface{}, path []string) []interface{} {
+	if m.tooDeep(path) {
+		return slices
+	}
+
+	var newVals []interface{}
+	var actions []FilterAction
+	if len(path) == 0 && m.Config.Parallelism > 1 && len(slices) > 1 {
+		newVals, actions = m.maskElementsParallel(ctx, key, slices)
+	}
+
+	newSlices, copied := slices, m.Config.InPlace
+	var deleted []bool
 	for i, v := range slices {
-		switch v.(type) {
-		case string:
-			// e.g: [{"foo":["a","b"]}] will iterate over a, b
-			transformedVal := m.Config.StringTransformer(ctx, KVInfo{
-				IsTopLevel: false,
-				Inside:     Array,
-				Key:        key,
-				Value:      v.(string),
-			})
-			newSlices[i] = transformedVal
+		var newVal interface{}
+		var action FilterAction
+		if newVals != nil {
+			newVal, action = newVals[i], actions[i]
+		} else {
+			newVal, action = m.maskElement(ctx, key, append(path, strconv.Itoa(i)), v)
+		}
 
-		case map[string]interface{}:
-			// e.g: {"foo":[{"a":"b"},{"c":"d"}]} will iterate over foo elements
-			newSlices[i] = m.maskMapInterface(ctx, v.(map[string]interface{}))
+		if action == FilterDelete {
+			if deleted == nil {
+				deleted = make([]bool, len(slices))
+			}
 
-		case []interface{}:
-			// array contain multidimensional array, e.g: {"mixed": [[{"foo": "bar"}]]}
-			// will iterate the elements "mixed" and each value will call this func recursively
-			newSlices[i] = m.maskSliceInterface(ctx, key, v.([]interface{}))
+			deleted[i] = true
+			continue
+		}
 
-		default:
-			// if element is not contain string, e.g: [1,2] will iterate over 1 and 2
-			newSlices[i] = v
+		if action == FilterKeep || sameValue(v, newVal) {
+			continue
+		}
+
+		if !copied {
+			newSlices = make([]interface{}, len(slices))
+			copy(newSlices, slices)
+			copied = true
 		}
 
+		newSlices[i] = newVal
+	}
+
+	if deleted != nil {
+		kept := make([]interface{}, 0, len(slices))
+		for i, v := range newSlices {
+			if !deleted[i] {
+				kept = append(kept, v)
+			}
+		}
+
+		return kept
 	}
 
 	return newSlices
 }
+
+// maskElement return the transformed array element v on childPath and the decision of Config.KeyFilter.
+// The returned value is v itself when it is not changed.
+func (m *Transformer) maskElement(ctx context.Context, key string, childPath []string, v interface{}) (interface{}, FilterAction) {
+	isTopLevel := len(childPath) == 1
+	action := m.filterKey(ctx, KVInfo{IsTopLevel: isTopLevel, Inside: Array, Key: key, Path: childPath})
+	if action != FilterTransform {
+		return v, action
+	}
+
+	switch v.(type) {
+	case string:
+		// e.g: [{"foo":["a","b"]}] will iterate over a, b
+		return m.transformString(ctx, KVInfo{
+			IsTopLevel: isTopLevel,
+			Inside:     Array,
+			Key:        key,
+			Value:      v.(string),
+			Path:       childPath,
+		}), action
+
+	case map[string]interface{}:
+		// e.g: {"foo":[{"a":"b"},{"c":"d"}]} will iterate over foo elements
+		return m.maskMapInterface(ctx, v.(map[string]interface{}), childPath), action
+
+	case []interface{}:
+		// array contain multidimensional array, e.g: {"mixed": [[{"foo": "bar"}]]}
+		// will iterate the elements "mixed" and each value will call this func recursively
+		return m.maskSliceInterface(ctx, key, v.([]interface{}), childPath), action
+	}
+
+	// if element is not contain string, e.g: [1,2] will iterate over 1 and 2
+	if !m.transformsValue() {
+		return v, action
+	}
+
+	return m.transformValue(ctx, KVInfo{
+		IsTopLevel: isTopLevel,
+		Inside:     Array,
+		Key:        key,
+		Path:       childPath,
+	}, v), action
+}
+
+// maskElementsParallel transform the elements of the top level array in Config.Parallelism disjoint segments concurrently,
+// and return the result of maskElement of every element in the same order.
+func (m *Transformer) maskElementsParallel(ctx context.Context, key string, slices []interface{}) ([]interface{}, []FilterAction) {
+	workers := m.Config.Parallelism
+	if workers > len(slices) {
+		workers = len(slices)
+	}
+
+	// the hook (i.e: counting for Metrics) is not safe for concurrent use
+	pm := m
+	if m.hook != nil {
+		var mu sync.Mutex
+		hook := m.hook
+		c := *m
+		c.hook = func(info KVInfo, transformer interface{}, before, after interface{}) {
+			mu.Lock()
+			defer mu.Unlock()
+			hook(info, transformer, before, after)
+		}
+		pm = &c
+	}
+
+	newVals := make([]interface{}, len(slices))
+	actions := make([]FilterAction, len(slices))
+
+	var wg sync.WaitGroup
+	var abortOnce sync.Once
+	var abort interface{}
+
+	size := (len(slices) + workers - 1) / workers
+	for start := 0; start < len(slices); start += size {
+		end := start + size
+		if end > len(slices) {
+			end = len(slices)
+		}
+
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			defer func() {
+				// re-panic on the caller, so transformError is recovered by transform
+				if r := recover(); r != nil {
+					abortOnce.Do(func() { abort = r })
+				}
+			}()
+
+			path := make([]string, 0, 16)
+			for i := start; i < end; i++ {
+				newVals[i], actions[i] = pm.maskElement(ctx, key, append(path, strconv.Itoa(i)), slices[i])
+			}
+		}(start, end)
+	}
+
+	wg.Wait()
+	if abort != nil {
+		panic(abort)
+	}
+
+	return newVals, actions
+}
+
+// filterKey return the decision of Config.KeyFilter for the value on info.Path.
+func (m *Transformer) filterKey(ctx context.Context, info KVInfo) FilterAction {
+	if m.Config.KeyFilter == nil {
+		return FilterTransform
+	}
+
+	return m.Config.KeyFilter(ctx, withPosition(info))
+}
+
+// transformKey return the new key of the object member on path, using the first matched Config.MaskKeys
+// or Config.KeyTransformer.
+func (m *Transformer) transformKey(ctx context.Context, path []string, isTopLevel bool) string {
+	key := path[len(path)-1]
+	for _, p := range m.Config.MaskKeys {
+		if p.Match(key) {
+			return m.callString(ctx, p.transformer, withPosition(KVInfo{
+				IsTopLevel: isTopLevel,
+				Inside:     Object,
+				Key:        key,
+				Value:      key,
+				Path:       path,
+			}))
+		}
+	}
+
+	if m.Config.KeyTransformer != nil {
+		return m.Config.KeyTransformer(ctx, path, key)
+	}
+
+	return key
+}
+
+// maskedPath return info.Path and info.Key with the keys matched by Config.MaskKeys masked,
+// so MaskReport and TransformStats don't record the key which MaskKeys hide.
+func (m *Transformer) maskedPath(ctx context.Context, info KVInfo) ([]string, string) {
+	if len(m.Config.MaskKeys) == 0 {
+		return info.Path, info.Key
+	}
+
+	path := make([]string, len(info.Path))
+	for i, segment := range info.Path {
+		path[i] = m.maskedKey(ctx, info.Path[:i+1], segment)
+	}
+
+	return path, m.maskedKey(ctx, info.Path, info.Key)
+}
+
+// maskedKey return key masked by the first matched Config.MaskKeys, without calling the hook.
+func (m *Transformer) maskedKey(ctx context.Context, path []string, key string) string {
+	for _, p := range m.Config.MaskKeys {
+		if p.Match(key) {
+			return p.transformer(ctx, withPosition(KVInfo{
+				IsTopLevel: len(path) == 1,
+				Inside:     Object,
+				Key:        key,
+				Value:      key,
+				Path:       path,
+			}))
+		}
+	}
+
+	return key
+}
+
+// keyCollision abort the transformation because the key on path is renamed into the key which is already used.
+func (m *Transformer) keyCollision(path []string, newKey string) {
+	panic(transformError{err: fmt.Errorf("%w: %s renamed to %q", ErrKeyCollision, JoinPath(path), newKey)})
+}
+
+// tooDeep return true when the object or array on path is nested deeper than Config.MaxDepth,
+// or abort the transformation when Config.MaxDepthError is set.
+func (m *Transformer) tooDeep(path []string) bool {
+	if m.Config.MaxDepth <= 0 || len(path) < m.Config.MaxDepth {
+		return false
+	}
+
+	if m.Config.MaxDepthError {
+		panic(transformError{err: fmt.Errorf("%w %d at %s", ErrMaxDepthExceeded, m.Config.MaxDepth, JoinPath(path))})
+	}
+
+	return true
+}
+
+// transformString return the transformed string value using the first matched of
+// Config.Paths, Config.Keys and Config.KeyPatterns, otherwise Config.StringTransformer, then Config.Detectors.
+func (m *Transformer) transformString(ctx context.Context, info KVInfo) string {
+	info = withPosition(info)
+	v := m.matchString(ctx, info)
+	for _, detector := range m.Config.Detectors {
+		masked := detector.Mask(v)
+		if m.hook != nil {
+			m.hook(info, detector, v, masked)
+		}
+
+		v = masked
+	}
+
+	if m.Config.Stats != nil {
+		_, key := m.maskedPath(ctx, info)
+		m.Config.Stats.record(key, info.Value, v)
+	}
+
+	return v
+}
+
+func (m *Transformer) matchString(ctx context.Context, info KVInfo) string {
+	for _, p := range m.paths {
+		if p.selector.Match(info.Path) || (info.Inside == Array && len(info.Path) > 0 && p.selector.Match(info.Path[:len(info.Path)-1])) {
+			return m.callString(ctx, p.transformer, info)
+		}
+	}
+
+	if transformer, ok := m.keys.get(info.Key); ok {
+		return m.callString(ctx, transformer, info)
+	}
+
+	for _, p := range m.Config.KeyPatterns {
+		if p.Match(info.Key) {
+			return m.callString(ctx, p.transformer, info)
+		}
+	}
+
+	// in Whitelist mode the unchanged nested document would skip the Placeholder
+	whitelist := m.Config.Mode == Whitelist
+	if m.Config.DecodeNestedJSON && !whitelist {
+		if v, ok := m.transformNested(ctx, info); ok {
+			return v
+		}
+	}
+
+	if m.Config.DecodeBase64JSON && !whitelist {
+		if v, ok := m.transformBase64(ctx, info); ok {
+			return v
+		}
+	}
+
+	if !m.inScope(info.Path) {
+		return info.Value
+	}
+
+	return m.callString(ctx, m.Config.StringTransformer, info)
+}
+
+// inScope return true if the value on path is selected by Config.Include and not by Config.Exclude.
+func (m *Transformer) inScope(path []string) bool {
+	if len(m.include) > 0 && !matchSubtree(m.include, path) {
+		return false
+	}
+
+	return !matchSubtree(m.exclude, path)
+}
+
+// callString call the transformer and the hook.
+func (m *Transformer) callString(ctx context.Context, transformer StringTransformer, info KVInfo) string {
+	v := transformer(ctx, info)
+	if m.hook != nil {
+		m.hook(info, transformer, info.Value, v)
+	}
+
+	return v
+}
+
+// transformsValue return true if any of Config.ValueTransformer, NumberTransformer or BoolTransformer is set.
+func (m *Transformer) transformsValue() bool {
+	return m.Config.ValueTransformer != nil || m.Config.NumberTransformer != nil || m.Config.BoolTransformer != nil
+}
+
+// transformValue call Config.NumberTransformer or BoolTransformer, then Config.ValueTransformer, and the hook.
+func (m *Transformer) transformValue(ctx context.Context, info KVInfo, value interface{}) interface{} {
+	info = withPosition(info)
+	v := value
+	switch val := value.(type) {
+	case bool:
+		if m.Config.BoolTransformer != nil {
+			b := m.Config.BoolTransformer(ctx, info, val)
+			if m.hook != nil {
+				m.hook(info, m.Config.BoolTransformer, val, b)
+			}
+			v = b
+		}
+
+	case float64, json.Number:
+		if m.Config.NumberTransformer != nil {
+			num := toNumber(val)
+			n := m.Config.NumberTransformer(ctx, info, num)
+			if m.hook != nil {
+				m.hook(info, m.Config.NumberTransformer, num, n)
+			}
+
+			// keep the original type when nothing is changed
+			if n != num {
+				v = n
+			}
+		}
+	}
+
+	if m.Config.ValueTransformer != nil {
+		before := v
+		v = m.Config.ValueTransformer(ctx, info, before)
+		if m.hook != nil {
+			m.hook(info, m.Config.ValueTransformer, before, v)
+		}
+	}
+
+	return v
+}
+
+// toNumber return the JSON form of float64 (the same as json.Marshal) or json.Number.
+func toNumber(v interface{}) json.Number {
+	if n, ok := v.(json.Number); ok {
+		return n
+	}
+
+	b, _ := json.Marshal(v)
+	return json.Number(b)
+}
+
+// allowKeysTransformer is the StringTransformer of Whitelist mode.
+func allowKeysTransformer(keys []string, placeholder string) StringTransformer {
+	allowed := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		allowed[key] = struct{}{}
+	}
+
+	return func(ctx context.Context, info KVInfo) string {
+		if _, ok := allowed[info.Key]; ok {
+			return info.Value
+		}
+
+		return placeholder
+	}
+}
+
+// transformNested return the transformed JSON object or array encoded inside string value,
+// ok is false when the value is not one.
+func (m *Transformer) transformNested(ctx context.Context, info KVInfo) (string, bool) {
+	out, changed, ok := m.transformNestedDoc(ctx, info, []byte(info.Value))
+	if !ok || !changed {
+		return info.Value, ok
+	}
+
+	return string(out), true
+}
+
+// transformBase64 return the transformed JSON object or array encoded as base64 string value (i.e: "eyJwYXNzd29yZCI6ImEifQ=="),
+// encoded back using the same base64 encoding. The ok is false when the value is not one.
+func (m *Transformer) transformBase64(ctx context.Context, info KVInfo) (string, bool) {
+	// base64 of '{' starts with 'e', and of '[' starts with 'W'
+	if len(info.Value) < 4 || (info.Value[0] != 'e' && info.Value[0] != 'W') {
+		return "", false
+	}
+
+	for _, enc := range base64Encodings {
+		b, err := enc.DecodeString(info.Value)
+		if err != nil {
+			continue
+		}
+
+		out, changed, ok := m.transformNestedDoc(ctx, info, b)
+		if !ok || !changed {
+			return info.Value, ok
+		}
+
+		return enc.EncodeToString(out), true
+	}
+
+	return "", false
+}
+
+// transformNestedDoc transform doc which continue the path of info, and return the encoded output
+// and whether anything is changed. The ok is false when doc is not a JSON object or array.
+func (m *Transformer) transformNestedDoc(ctx context.Context, info KVInfo, doc []byte) (out []byte, changed, ok bool) {
+	if !isContainer(doc) {
+		return nil, false, false
+	}
+
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, false, false
+	}
+
+	// copy-on-write is needed to know whether anything is changed
+	cow := m
+	if m.Config.InPlace {
+		c := *m
+		c.Config.InPlace = false
+		cow = &c
+	}
+
+	var transformed interface{}
+	switch v := data.(type) {
+	case map[string]interface{}:
+		transformed = cow.maskMapInterface(ctx, v, info.Path)
+	case []interface{}:
+		transformed = cow.maskSliceInterface(ctx, info.Key, v, info.Path)
+	}
+
+	if sameValue(data, transformed) {
+		return doc, false, true
+	}
+
+	out, err := m.Config.JSONMarshal(transformed)
+	if err != nil {
+		return nil, false, false
+	}
+
+	return out, true, true
+}
+
+// sameValue return true if the transformed value is the original one:
+// equal string or scalar, or the same (not copied) map or slice.
+func sameValue(original, transformed interface{}) bool {
+	switch v := original.(type) {
+	case string:
+		s, ok := transformed.(string)
+		return ok && s == v
+
+	case map[string]interface{}:
+		t, ok := transformed.(map[string]interface{})
+		return ok && reflect.ValueOf(v).Pointer() == reflect.ValueOf(t).Pointer()
+
+	case []interface{}:
+		t, ok := transformed.([]interface{})
+		return ok && len(v) == len(t) && (len(v) == 0 || &v[0] == &t[0])
+	}
+
+	if original == nil || transformed == nil {
+		return original == transformed
+	}
+
+	// non-string scalar, uncomparable value (i.e: map returned by ValueTransformer) panics on ==
+	return reflect.TypeOf(original).Comparable() && reflect.TypeOf(transformed).Comparable() && original == transformed
+}
+
+var (
+	interfaceMapType   = reflect.TypeOf(map[string]interface{}(nil))
+	interfaceSliceType = reflect.TypeOf([]interface{}(nil))
+)
+
+// reflectValue return reflect.Value of v to be set into map or slice of elemType, nil become the zero value.
+// The ok is false when v can't be stored as elemType, i.e: ValueTransformer return "***" for int.
+func reflectValue(v interface{}, elemType reflect.Type) (value reflect.Value, ok bool) {
+	if v == nil {
+		return reflect.Zero(elemType), true
+	}
+
+	value = reflect.ValueOf(v)
+	switch {
+	case value.Type().AssignableTo(elemType):
+		return value, true
+	case value.Kind() == elemType.Kind() && value.Type().ConvertibleTo(elemType):
+		// i.e: string into the named string type
+		return value.Convert(elemType), true
+	}
+
+	if num, isNumber := v.(json.Number); isNumber {
+		return numberValue(num, elemType)
+	}
+
+	return value, false
+}
+
+// numberValue return json.Number returned by NumberTransformer as the number of elemType, i.e: float64 of map[string]float64.
+// The ok is false when elemType is not a number, or num doesn't fit it (i.e: 1.5 for int, or overflow).
+func numberValue(num json.Number, elemType reflect.Type) (reflect.Value, bool) {
+	value := reflect.New(elemType).Elem()
+	switch elemType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(num.String(), 10, 64)
+		if err != nil || value.OverflowInt(n) {
+			return reflect.ValueOf(num), false
+		}
+		value.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(num.String(), 10, 64)
+		if err != nil || value.OverflowUint(n) {
+			return reflect.ValueOf(num), false
+		}
+		value.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := num.Float64()
+		if err != nil || value.OverflowFloat(f) {
+			return reflect.ValueOf(num), false
+		}
+		value.SetFloat(f)
+
+	default:
+		return reflect.ValueOf(num), false
+	}
+
+	return value, true
+}
+
+// setMapValue set v on key of the typed map altered, and return the map.
+// When v doesn't fit the map value type, altered is copied into map[string]interface{} first,
+// so the transformer may return another type (i.e: string for masked number) the same as for decoded JSON.
+func setMapValue(altered, key reflect.Value, v interface{}) reflect.Value {
+	if value, ok := reflectValue(v, altered.Type().Elem()); ok {
+		altered.SetMapIndex(key, value)
+		return altered
+	}
+
+	widened := reflect.MakeMapWithSize(interfaceMapType, altered.Len()+1)
+	iter := altered.MapRange()
+	for iter.Next() {
+		widened.SetMapIndex(reflect.ValueOf(iter.Key().String()), iter.Value())
+	}
+
+	widened.SetMapIndex(reflect.ValueOf(key.String()), reflect.ValueOf(v))
+	return widened
+}
+
+// setSliceValue is setMapValue for the typed slice altered, it is copied into []interface{} when v doesn't fit.
+func setSliceValue(altered reflect.Value, i int, v interface{}) reflect.Value {
+	if value, ok := reflectValue(v, altered.Type().Elem()); ok {
+		altered.Index(i).Set(value)
+		return altered
+	}
+
+	widened := reflect.MakeSlice(interfaceSliceType, altered.Len(), altered.Len())
+	for n := 0; n < i; n++ {
+		widened.Index(n).Set(altered.Index(n))
+	}
+
+	widened.Index(i).Set(reflect.ValueOf(v))
+	return widened
+}
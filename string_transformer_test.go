@@ -1,8 +1,15 @@
 package jsonutil_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
 
@@ -345,6 +352,677 @@ func TestTransformer_TransformBytes_GoRoutine(t *testing.T) {
 	wg.Wait()
 }
 
+func TestTransformer_Transform_CopyOnWrite(t *testing.T) {
+	const doc = `{"user":{"email":"a@example.com","tags":["email"]},"list":[{"email":"b@example.com"}],"other":{"a":"b"}}`
+
+	config := jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "email" {
+				return "xxx"
+			}
+
+			return info.Value
+		},
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(doc), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := jsonutil.NewTransformer(config).Transform(context.Background(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input, _ := json.Marshal(data)
+	var expectInput interface{}
+	_ = json.Unmarshal([]byte(doc), &expectInput)
+	if !reflect.DeepEqual(data, expectInput) {
+		t.Errorf("input is modified: %s", input)
+	}
+
+	output, _ := json.Marshal(out)
+	if string(output) != `{"list":[{"email":"xxx"}],"other":{"a":"b"},"user":{"email":"xxx","tags":["email"]}}` {
+		t.Errorf("unexpected output: %s", output)
+	}
+
+	// unchanged object is shared
+	if reflect.ValueOf(out.(map[string]interface{})["other"]).Pointer() != reflect.ValueOf(data.(map[string]interface{})["other"]).Pointer() {
+		t.Error("unchanged object is copied")
+	}
+
+	config.InPlace = true
+	if _, err = jsonutil.NewTransformer(config).Transform(context.Background(), data); err != nil {
+		t.Fatal(err)
+	}
+
+	input, _ = json.Marshal(data)
+	if string(input) != `{"list":[{"email":"xxx"}],"other":{"a":"b"},"user":{"email":"xxx","tags":["email"]}}` {
+		t.Errorf("input is not modified in place: %s", input)
+	}
+}
+
+func TestTransformer_ValueTransformer(t *testing.T) {
+	config := jsonutil.Config{
+		ValueTransformer: func(ctx context.Context, info jsonutil.KVInfo, value interface{}) interface{} {
+			switch info.Key {
+			case "card_number", "salary":
+				return "***"
+			case "verified":
+				return nil
+			}
+
+			return value
+		},
+	}
+
+	transformer := jsonutil.NewTransformer(config)
+	out, err := transformer.TransformBytes(context.Background(), []byte(`{"card_number":4111111111111111,"user":{"salary":1000.5,"verified":true,"age":20,"note":null},"salary":[1,2,"x"],"list":[1,false,null]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"card_number":"***","list":[1,false,null],"salary":["***","***","x"],"user":{"age":20,"note":null,"salary":"***","verified":null}}`
+	if string(out) != expected {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	// typed top level map keep the nil value
+	data := map[string]interface{}{"verified": true, "age": 20}
+	transformed, err := transformer.Transform(context.Background(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := transformed.(map[string]interface{})["verified"]; !ok || v != nil {
+		t.Errorf("verified must be null, got %v %v", v, ok)
+	}
+
+	if data["verified"] != true {
+		t.Error("input is modified")
+	}
+
+	// without ValueTransformer non-string value is kept
+	out, err = jsonutil.NewTransformer(jsonutil.Config{}).TransformBytes(context.Background(), []byte(`{"salary":1000}`))
+	if err != nil || string(out) != `{"salary":1000}` {
+		t.Errorf("unexpected output: %s %v", out, err)
+	}
+}
+
+func TestTransformer_NumberAndBoolTransformer(t *testing.T) {
+	config := jsonutil.Config{
+		NumberTransformer: func(ctx context.Context, info jsonutil.KVInfo, value json.Number) json.Number {
+			if info.Key == "salary" {
+				return "0"
+			}
+
+			return value
+		},
+		BoolTransformer: func(ctx context.Context, info jsonutil.KVInfo, value bool) bool {
+			return !value
+		},
+	}
+
+	doc := []byte(`{"salary":1000.5,"age":20,"admin":true,"list":[1,true],"big":12345678901234567890,"note":null}`)
+	expected := `{"admin":false,"age":20,"big":12345678901234567000,"list":[1,false],"note":null,"salary":0}`
+
+	out, err := jsonutil.NewTransformer(config).TransformBytes(context.Background(), doc)
+	if err != nil || string(out) != expected {
+		t.Errorf("unexpected output: %s %v", out, err)
+	}
+
+	// ValueTransformer receive the result of NumberTransformer
+	var received []interface{}
+	config.ValueTransformer = func(ctx context.Context, info jsonutil.KVInfo, value interface{}) interface{} {
+		if info.Key == "salary" {
+			received = append(received, value)
+		}
+		return value
+	}
+
+	var buf bytes.Buffer
+	if err = jsonutil.NewTransformer(config).TransformStream(context.Background(), bytes.NewReader(doc), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) != 1 || received[0] != json.Number("0") {
+		t.Errorf("unexpected value received by ValueTransformer: %v", received)
+	}
+
+	// json.Number precision is kept in stream
+	if !strings.Contains(buf.String(), `"big":12345678901234567890`) || !strings.Contains(buf.String(), `"admin":false`) {
+		t.Errorf("unexpected stream output: %s", buf.String())
+	}
+}
+
+func TestTransformer_Transform_TypedMap(t *testing.T) {
+	type salary int
+
+	transformer := jsonutil.NewTransformer(jsonutil.Config{
+		ValueTransformer: func(ctx context.Context, info jsonutil.KVInfo, value interface{}) interface{} {
+			if info.Key == "card" || info.Index == 1 {
+				return "***"
+			}
+			return value
+		},
+	})
+
+	// the masked value doesn't fit the typed map or slice, so it become map[string]interface{} or []interface{}
+	out, err := transformer.Transform(context.Background(), map[string]int{"card": 4111, "age": 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(out, map[string]interface{}{"card": "***", "age": 30}) {
+		t.Errorf("unexpected map output: %#v", out)
+	}
+
+	out, err = transformer.Transform(context.Background(), []salary{100, 200, 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(out, []interface{}{salary(100), "***", salary(300)}) {
+		t.Errorf("unexpected slice output: %#v", out)
+	}
+
+	// the value which fits keep the original type
+	out, err = transformer.Transform(context.Background(), map[string]salary{"age": 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(out, map[string]salary{"age": 30}) {
+		t.Errorf("unexpected output: %#v", out)
+	}
+}
+
+func TestTransformer_Transform_TypedNumber(t *testing.T) {
+	transformer := jsonutil.NewTransformer(jsonutil.Config{
+		NumberTransformer: func(ctx context.Context, info jsonutil.KVInfo, value json.Number) json.Number {
+			if info.Key == "amount" {
+				return "2.5"
+			}
+			return value
+		},
+	})
+
+	// json.Number is converted back into the map value type
+	out, err := transformer.Transform(context.Background(), map[string]float64{"amount": 1.5, "fee": 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(out, map[string]float64{"amount": 2.5, "fee": 0.1}) {
+		t.Errorf("unexpected output: %#v", out)
+	}
+
+	// the number out of float64 range is kept as json.Number
+	overflow := jsonutil.NewTransformer(jsonutil.Config{
+		NumberTransformer: func(ctx context.Context, info jsonutil.KVInfo, value json.Number) json.Number {
+			return "1e999"
+		},
+	})
+
+	out, err = overflow.Transform(context.Background(), map[string]float64{"amount": 1.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(out, map[string]interface{}{"amount": json.Number("1e999")}) {
+		t.Errorf("unexpected overflow output: %#v", out)
+	}
+}
+
+func TestTransformer_UseNumber(t *testing.T) {
+	var received []interface{}
+	transformer := jsonutil.NewTransformer(jsonutil.Config{
+		UseNumber: true,
+		ValueTransformer: func(ctx context.Context, info jsonutil.KVInfo, value interface{}) interface{} {
+			received = append(received, value)
+			return value
+		},
+	})
+
+	out, err := transformer.TransformBytes(context.Background(), []byte(`{"id":9007199254740993,"list":[1.50,-2e3]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != `{"id":9007199254740993,"list":[1.50,-2e3]}` {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	for _, v := range received {
+		if _, ok := v.(json.Number); !ok {
+			t.Errorf("expected json.Number, got %T", v)
+		}
+	}
+
+	for _, doc := range []string{``, `{"a":1} x`, `{"a":`} {
+		if _, err = transformer.TransformBytes(context.Background(), []byte(doc)); err == nil {
+			t.Errorf("expected error on %q", doc)
+		}
+	}
+
+	// whitespace after the value is allowed
+	if _, err = transformer.TransformBytes(context.Background(), []byte("[1] \n")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTransformer_DecodeNestedJSON(t *testing.T) {
+	var paths []string
+	config := jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "password" {
+				paths = append(paths, jsonutil.JoinPath(info.Path))
+				return "xxx"
+			}
+
+			return info.Value
+		},
+		Keys: map[string]jsonutil.StringTransformer{
+			"raw": func(ctx context.Context, info jsonutil.KVInfo) string { return "replaced" },
+		},
+		DecodeNestedJSON: true,
+	}
+
+	in := `{"payload":"{\"password\":\"abc\",\"id\":12345678901234567890,\"inner\":\"[{\\\"password\\\":\\\"x\\\"}]\"}","list":["{\"a\": 1}"],"raw":"{\"password\":\"abc\"}","text":"{not json","password":"p"}`
+	expected := `{"list":["{\"a\": 1}"],"password":"xxx","payload":"{\"id\":12345678901234567890,\"inner\":\"[{\\\"password\\\":\\\"xxx\\\"}]\",\"password\":\"xxx\"}","raw":"replaced","text":"{not json"}`
+
+	for _, inPlace := range []bool{false, true} {
+		paths = nil
+		config.InPlace = inPlace
+		out, err := jsonutil.NewTransformer(config).TransformBytes(context.Background(), []byte(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(out) != expected {
+			t.Errorf("in place %v, unexpected output: %s", inPlace, out)
+		}
+
+		sort.Strings(paths)
+		if strings.Join(paths, ",") != "password,payload.inner.0.password,payload.password" {
+			t.Errorf("unexpected paths: %v", paths)
+		}
+	}
+}
+
+func TestTransformer_KVInfoPosition(t *testing.T) {
+	var got []string
+	config := jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			got = append(got, fmt.Sprintf("%s index=%d depth=%d", jsonutil.JoinPath(info.Path), info.Index, info.Depth))
+			return info.Value
+		},
+		ValueTransformer: func(ctx context.Context, info jsonutil.KVInfo, value interface{}) interface{} {
+			got = append(got, fmt.Sprintf("%s index=%d depth=%d", jsonutil.JoinPath(info.Path), info.Index, info.Depth))
+			return value
+		},
+	}
+
+	doc := []byte(`{"owner":{"name":"a"},"items":[{"name":"b"},{"name":"c","tags":["x",1]}]}`)
+	expected := []string{
+		"items.0.name index=-1 depth=3",
+		"items.1.name index=-1 depth=3",
+		"items.1.tags.0 index=0 depth=4",
+		"items.1.tags.1 index=1 depth=4",
+		"owner.name index=-1 depth=2",
+	}
+
+	transformer := jsonutil.NewTransformer(config)
+	if _, err := transformer.TransformBytes(context.Background(), doc); err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected info: %v", got)
+	}
+
+	got = nil
+	if err := transformer.TransformStream(context.Background(), bytes.NewReader(doc), ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected info on stream: %v", got)
+	}
+}
+
+func TestTransformer_KeyFilter(t *testing.T) {
+	config := jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			return "xxx"
+		},
+		KeyFilter: func(ctx context.Context, info jsonutil.KVInfo) jsonutil.FilterAction {
+			switch {
+			case info.Key == "debug_stack", info.Key == "list" && info.Index == 1:
+				return jsonutil.FilterDelete
+			case info.Key == "public", info.Key == "list" && info.Index == 3:
+				return jsonutil.FilterKeep
+			}
+
+			return jsonutil.FilterTransform
+		},
+	}
+
+	in := `{"debug_stack":{"a":"b"},"public":{"name":"john", "id":1},"secret":"s","list":["a","b",{"debug_stack":"c","x":"y"},["d"]]}`
+	expected := `{"list":["xxx",{"x":"xxx"},["d"]],"public":{"id":1,"name":"john"},"secret":"xxx"}`
+
+	for _, inPlace := range []bool{false, true} {
+		config.InPlace = inPlace
+		out, err := jsonutil.NewTransformer(config).TransformBytes(context.Background(), []byte(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(out) != expected {
+			t.Errorf("in place %v, unexpected output: %s", inPlace, out)
+		}
+	}
+
+	var buf bytes.Buffer
+	err := jsonutil.NewTransformer(config).TransformStream(context.Background(), strings.NewReader(in), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != `{"public":{"name":"john","id":1},"secret":"xxx","list":["xxx",{"x":"xxx"},["d"]]}`+"\n" {
+		t.Errorf("unexpected stream output: %s", buf.String())
+	}
+
+	// the input is not modified
+	config.InPlace = false
+	data := map[string]interface{}{"debug_stack": "a", "list": []interface{}{"a", "b"}}
+	out, err := jsonutil.NewTransformer(config).Transform(context.Background(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data) != 2 || len(data["list"].([]interface{})) != 2 {
+		t.Errorf("input is modified: %v", data)
+	}
+
+	if !reflect.DeepEqual(out, map[string]interface{}{"list": []interface{}{"xxx"}}) {
+		t.Errorf("unexpected output: %v", out)
+	}
+}
+
+func TestTransformer_KeyTransformer(t *testing.T) {
+	email, err := jsonutil.GlobKey("*@*", func(ctx context.Context, info jsonutil.KVInfo) string { return "***" })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	config := jsonutil.Config{
+		KeyTransformer: func(ctx context.Context, path []string, key string) string {
+			paths = append(paths, jsonutil.JoinPath(path))
+			return jsonutil.ToCase(key, jsonutil.CamelCase)
+		},
+		MaskKeys: []jsonutil.KeyPattern{email},
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "pass_word" {
+				return "xxx"
+			}
+
+			return info.Value
+		},
+	}
+
+	in := `{"user_name":"john","pass_word":"secret","john@example.com":{"last_login":1},"items":[{"item_id":"a"}]}`
+	expected := `{"***":{"lastLogin":1},"items":[{"itemId":"a"}],"passWord":"xxx","userName":"john"}`
+
+	out, err := jsonutil.NewTransformer(config).TransformBytes(context.Background(), []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != expected {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	sort.Strings(paths)
+	if strings.Join(paths, ",") != "items,items.0.item_id,john@example\\.com.last_login,pass_word,user_name" {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+
+	var buf bytes.Buffer
+	if err = jsonutil.NewTransformer(config).TransformStream(context.Background(), strings.NewReader(in), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != `{"userName":"john","passWord":"xxx","***":{"lastLogin":1},"items":[{"itemId":"a"}]}`+"\n" {
+		t.Errorf("unexpected stream output: %s", buf.String())
+	}
+
+	// user_id and userId are both renamed into userId
+	for _, doc := range []string{`{"user_id":1,"userId":2}`, `{"a":{"user_id":1,"userId":2}}`} {
+		_, err = jsonutil.NewTransformer(config).TransformBytes(context.Background(), []byte(doc))
+		if !errors.Is(err, jsonutil.ErrKeyCollision) {
+			t.Errorf("expected ErrKeyCollision on %s, got %v", doc, err)
+		}
+
+		err = jsonutil.NewTransformer(config).TransformStream(context.Background(), strings.NewReader(doc), ioutil.Discard)
+		if !errors.Is(err, jsonutil.ErrKeyCollision) {
+			t.Errorf("expected ErrKeyCollision on stream %s, got %v", doc, err)
+		}
+	}
+
+	// renaming into another renamed key is not a collision
+	config.KeyTransformer = func(ctx context.Context, path []string, key string) string {
+		return map[string]string{"a": "b", "b": "c"}[key]
+	}
+
+	out, err = jsonutil.NewTransformer(config).TransformBytes(context.Background(), []byte(`{"a":1,"b":2}`))
+	if err != nil || string(out) != `{"b":1,"c":2}` {
+		t.Errorf("unexpected output: %s %v", out, err)
+	}
+}
+
+func TestTransformer_IncludeExclude(t *testing.T) {
+	config := jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			return "..."
+		},
+		Keys: map[string]jsonutil.StringTransformer{
+			"password": func(ctx context.Context, info jsonutil.KVInfo) string { return "***" },
+		},
+		Include: []string{"$.request.body", "$.items[*].note"},
+		Exclude: []string{"$.request.body.id"},
+	}
+
+	in := `{"request":{"body":{"text":"long","id":"1","tags":["a"]},"path":"/login"},"items":[{"note":"n","name":"x"}],"password":"p"}`
+	expected := `{"items":[{"name":"x","note":"..."}],"password":"***","request":{"body":{"id":"1","tags":["..."],"text":"..."},"path":"/login"}}`
+
+	out, err := jsonutil.NewTransformer(config).TransformBytes(context.Background(), []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != expected {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	config.Include = nil
+	out, err = jsonutil.NewTransformer(config).TransformBytes(context.Background(), []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `{"items":[{"name":"...","note":"..."}],"password":"***","request":{"body":{"id":"1","tags":["..."],"text":"..."},"path":"..."}}`
+	if string(out) != expected {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
+
+func TestTransformer_Whitelist(t *testing.T) {
+	config := jsonutil.Config{
+		Mode:      jsonutil.Whitelist,
+		AllowKeys: []string{"id", "status", "tags"},
+		Paths:     map[string]jsonutil.StringTransformer{"$.user.name": jsonutil.DefaultStringTransformer},
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			return "not used"
+		},
+	}
+
+	out, err := jsonutil.NewTransformer(config).TransformBytes(context.Background(), []byte(`{"id":"1","status":"ok","tags":["a","b"],"email":"a@example.com","user":{"name":"john","address":"street","id":"2"},"n":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"email":"***","id":"1","n":1,"status":"ok","tags":["a","b"],"user":{"address":"***","id":"2","name":"john"}}`
+	if string(out) != expected {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	config.Placeholder = "[redacted]"
+	out, err = jsonutil.NewTransformer(config).TransformBytes(context.Background(), []byte(`["top",{"email":"a@example.com"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != `["[redacted]",{"email":"[redacted]"}]` {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
+
+func TestTransformer_Whitelist_Nested(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		Mode:             jsonutil.Whitelist,
+		AllowKeys:        []string{"id"},
+		DecodeNestedJSON: true,
+		DecodeBase64JSON: true,
+	})
+
+	in := `{"id":"{\"id\":1}","card":"[4111111111111111]","pin":"{\"pin\":1234}","data":"WzQxMTExMTExMTExMTExMTFd"}`
+	out, err := tr.TransformBytes(context.Background(), []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"card":"***","data":"***","id":"{\"id\":1}","pin":"***"}`
+	if string(out) != expected {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
+
+func TestChain(t *testing.T) {
+	truncate := func(ctx context.Context, info jsonutil.KVInfo) string {
+		if len(info.Value) > 5 {
+			return info.Value[:5]
+		}
+		return info.Value
+	}
+
+	upper := func(ctx context.Context, info jsonutil.KVInfo) string {
+		return strings.ToUpper(info.Value)
+	}
+
+	var calls int
+	count := func(ctx context.Context, info jsonutil.KVInfo) string {
+		calls++
+		return info.Value + "!"
+	}
+
+	transformer := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: jsonutil.Chain(truncate, nil, upper, count),
+	})
+
+	out, err := transformer.TransformBytes(context.Background(), []byte(`{"a":"abcdefgh","b":["xy"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != `{"a":"ABCDE!","b":["XY!"]}` || calls != 2 {
+		t.Errorf("unexpected output: %s, calls %d", out, calls)
+	}
+
+	if v := jsonutil.Chain()(context.Background(), jsonutil.KVInfo{Value: "v"}); v != "v" {
+		t.Errorf("empty chain must keep the value, got %q", v)
+	}
+}
+
+func TestPathFunc(t *testing.T) {
+	mask := jsonutil.PathFunc(func(ctx context.Context, path []string, key, value string) string {
+		if key != "token" {
+			return value
+		}
+
+		if len(path) > 1 && path[0] == "admin" {
+			return "***"
+		}
+
+		return value[:2] + "***"
+	})
+
+	transformer := jsonutil.NewTransformer(jsonutil.Config{StringTransformer: mask.StringTransformer()})
+	out, err := transformer.TransformBytes(context.Background(), []byte(`{"user":{"token":"abcdef"},"admin":{"token":"abcdef"},"tokens":[{"token":"xyz"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != `{"admin":{"token":"***"},"tokens":[{"token":"xy***"}],"user":{"token":"ab***"}}` {
+		t.Errorf("unexpected output: %s", out)
+	}
+}
+
+func TestTransformer_Transform_InputNotModified(t *testing.T) {
+	const doc = `[{"password":"a","n":1,"payload":"{\"password\":\"b\"}","a@example.com":{"password":["c"]}},[["d"]]]`
+
+	email, err := jsonutil.GlobKey("*@*", func(ctx context.Context, info jsonutil.KVInfo) string { return "email" })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "password" || info.Value == "d" {
+				return "xxx"
+			}
+
+			return info.Value
+		},
+		ValueTransformer: func(ctx context.Context, info jsonutil.KVInfo, value interface{}) interface{} {
+			return 0
+		},
+		MaskKeys:         []jsonutil.KeyPattern{email},
+		DecodeNestedJSON: true,
+	})
+
+	var data, expectInput interface{}
+	_ = json.Unmarshal([]byte(doc), &data)
+	_ = json.Unmarshal([]byte(doc), &expectInput)
+
+	// calling it twice on the same input give the same output
+	for i := 0; i < 2; i++ {
+		out, err := tr.Transform(context.Background(), data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		output, _ := json.Marshal(out)
+		if string(output) != `[{"email":{"password":["xxx"]},"n":0,"password":"xxx","payload":"{\"password\":\"xxx\"}"},[["xxx"]]]` {
+			t.Errorf("unexpected output on call %d: %s", i, output)
+		}
+
+		if !reflect.DeepEqual(data, expectInput) {
+			input, _ := json.Marshal(data)
+			t.Errorf("input is modified on call %d: %s", i, input)
+		}
+	}
+
+	out, err := tr.TransformBytes(context.Background(), []byte(`null`))
+	if err != nil || string(out) != `null` {
+		t.Errorf("unexpected null output: %s %v", out, err)
+	}
+}
+
 func BenchmarkTransformer_Transform(b *testing.B) {
 
 	// No transform function defined, this to benchmark the actual process,
@@ -428,3 +1106,31 @@ func BenchmarkTransformer_Transform(b *testing.B) {
 	})
 
 }
+
+func BenchmarkTransformer_TransformMasking(b *testing.B) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(allJSONType), &data); err != nil {
+		b.Fatal(err)
+	}
+
+	mask := func(ctx context.Context, info jsonutil.KVInfo) string {
+		return "***"
+	}
+
+	for _, inPlace := range []bool{false, true} {
+		name := "copy-on-write"
+		if inPlace {
+			name = "in-place"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			tr := jsonutil.NewTransformer(jsonutil.Config{StringTransformer: mask, InPlace: inPlace})
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := tr.Transform(context.Background(), data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
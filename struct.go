@@ -0,0 +1,360 @@
+package jsonutil
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// StructTag is the struct tag read by TransformStruct.
+const StructTag = "mask"
+
+// maskTag is the parsed `mask` struct tag.
+type maskTag struct {
+	skip      bool
+	all       bool
+	keepFirst int
+	keepLast  int
+}
+
+// parseMaskTag parse the tag value: "true" (or "all"), "firstN", "lastN" and "-".
+func parseMaskTag(tag string) (*maskTag, error) {
+	switch {
+	case tag == "":
+		return nil, nil
+	case tag == "-" || tag == "false":
+		return &maskTag{skip: true}, nil
+	case tag == "true" || tag == "all":
+		return &maskTag{all: true}, nil
+	case strings.HasPrefix(tag, "first"):
+		n, err := strconv.Atoi(strings.TrimPrefix(tag, "first"))
+		if err != nil || n < 0 {
+			break
+		}
+		return &maskTag{keepFirst: n}, nil
+	case strings.HasPrefix(tag, "last"):
+		n, err := strconv.Atoi(strings.TrimPrefix(tag, "last"))
+		if err != nil || n < 0 {
+			break
+		}
+		return &maskTag{keepLast: n}, nil
+	}
+
+	return nil, fmt.Errorf("jsonutil: invalid %s tag %q", StructTag, tag)
+}
+
+// apply return the masked str, "true" use placeholder, the others keep some characters and replace the rest with '*'.
+func (t *maskTag) apply(str, placeholder string) string {
+	if t.all {
+		return placeholder
+	}
+
+	runes := []rune(str)
+	for i := range runes {
+		if i >= t.keepFirst && i < len(runes)-t.keepLast {
+			runes[i] = '*'
+		}
+	}
+
+	return string(runes)
+}
+
+// TransformStruct mask the string fields of the struct pointed by v in place, without converting it to JSON first.
+// The field with `mask` tag is masked according to the tag:
+//
+//	mask:"true"   replace with Config.Placeholder
+//	mask:"last4"  keep the last 4 characters, i.e: ************1234
+//	mask:"first2" keep the first 2 characters
+//	mask:"-"      never touch the field, including the nested fields
+//
+// The tag applies to string, *string, and slice, array or map of them.
+// Other string is transformed the same way as Transform, the key is the json tag name (or the field name).
+// Nested structs, pointers, slices, arrays, maps and interfaces are walked, unexported fields are skipped.
+// Pointer, slice or map which refers to one of its ancestors is not walked again, so self-referential data doesn't loop forever,
+// and the string reachable more than once (i.e: two slices sharing the backing array) is transformed once.
+func (m *Transformer) TransformStruct(ctx context.Context, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("jsonutil: TransformStruct requires non-nil pointer, got %T", v)
+	}
+
+	w := newStructWalker(m)
+	return w.walk(ctx, rv, "", make([]string, 0), nil)
+}
+
+// transformStructCopy return the transformed deep copy of v, v is struct or pointer to struct.
+func (m *Transformer) transformStructCopy(ctx context.Context, v reflect.Value) (reflect.Value, error) {
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(deepCopyValue(v, make(map[copiedPointer]reflect.Value)))
+
+	w := newStructWalker(m)
+	if err := w.walk(ctx, ptr, "", make([]string, 0), nil); err != nil {
+		return reflect.Value{}, err
+	}
+
+	return ptr.Elem(), nil
+}
+
+// copiedPointer is the key of copied pointer in deepCopyValue,
+// the pointer to struct and to its first field has the same address but different type.
+type copiedPointer struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// deepCopyValue return the copy of v which doesn't share pointer, slice or map with v (except in unexported fields),
+// so it can be modified in place. The same pointer is copied once, so self-referential struct is kept as is.
+func deepCopyValue(v reflect.Value, copied map[copiedPointer]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		key := copiedPointer{ptr: v.Pointer(), typ: v.Type()}
+		if c, ok := copied[key]; ok {
+			return c
+		}
+
+		c := reflect.New(v.Type().Elem())
+		copied[key] = c
+		c.Elem().Set(deepCopyValue(v.Elem(), copied))
+		return c
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopyValue(v.Elem(), copied))
+		return c
+
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				c.Field(i).Set(deepCopyValue(v.Field(i), copied))
+			}
+		}
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i), copied))
+		}
+		return c
+
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopyValue(v.Index(i), copied))
+		}
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopyValue(iter.Value(), copied))
+		}
+		return c
+	}
+
+	return v
+}
+
+// visitKey identify the pointer, slice or map being walked.
+// The address alone is not enough: pointer to struct and to its first field, or two slices sharing the backing array
+// have the same address, but not the same content.
+type visitKey struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+type structWalker struct {
+	m         *Transformer
+	ancestors map[visitKey]struct{} // the values from the root to the current one, for cycle detection
+	done      map[visitKey]struct{} // the transformed strings and maps, so the memory reachable twice is transformed once
+}
+
+func newStructWalker(m *Transformer) *structWalker {
+	return &structWalker{m: m, ancestors: make(map[visitKey]struct{}), done: make(map[visitKey]struct{})}
+}
+
+// enter add key into the ancestors, it returns false when key is already one of them.
+func (w *structWalker) enter(key visitKey) bool {
+	if _, ok := w.ancestors[key]; ok {
+		return false
+	}
+
+	w.ancestors[key] = struct{}{}
+	return true
+}
+
+// once return true when key is not done yet, and mark it as done.
+func (w *structWalker) once(key visitKey) bool {
+	if _, ok := w.done[key]; ok {
+		return false
+	}
+
+	w.done[key] = struct{}{}
+	return true
+}
+
+// walk transform v in place, v must be settable except for pointer, map and slice which is modified through its reference.
+func (w *structWalker) walk(ctx context.Context, v reflect.Value, key string, path []string, tag *maskTag) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+
+		visit := visitKey{ptr: v.Pointer(), typ: v.Type()}
+		if !w.enter(visit) {
+			return nil
+		}
+		defer delete(w.ancestors, visit)
+
+		return w.walk(ctx, v.Elem(), key, path, tag)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		// the value inside interface is not settable, work on a copy and set it back
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := w.walk(ctx, elem, key, path, tag); err != nil {
+			return err
+		}
+
+		if v.CanSet() {
+			v.Set(elem)
+		}
+		return nil
+
+	case reflect.Struct:
+		return w.walkStruct(ctx, v, path)
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Len() > 0 {
+			visit := visitKey{ptr: v.Pointer(), typ: v.Type(), len: v.Len()}
+			if !w.enter(visit) {
+				return nil
+			}
+			defer delete(w.ancestors, visit)
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			if err := w.walk(ctx, v.Index(i), key, append(path, strconv.Itoa(i)), tag); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+
+		// the map can't be partially shared like slice, so it is never walked twice
+		if !w.once(visitKey{ptr: v.Pointer(), typ: v.Type()}) {
+			return nil
+		}
+
+		iter := v.MapRange()
+		for iter.Next() {
+			k := fmt.Sprint(iter.Key().Interface())
+
+			// map value is not settable, work on a copy and set it back
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			if err := w.walk(ctx, elem, k, append(path, k), tag); err != nil {
+				return err
+			}
+
+			v.SetMapIndex(iter.Key(), elem)
+		}
+		return nil
+
+	case reflect.String:
+		if !v.CanSet() {
+			return nil
+		}
+
+		if !w.once(visitKey{ptr: v.UnsafeAddr(), typ: v.Type()}) {
+			return nil
+		}
+
+		if tag != nil {
+			v.SetString(tag.apply(v.String(), w.m.Config.Placeholder))
+			return nil
+		}
+
+		inside := Object
+		if len(path) > 0 && key != path[len(path)-1] {
+			inside = Array
+		}
+
+		v.SetString(w.m.transformString(ctx, KVInfo{
+			IsTopLevel: len(path) == 1,
+			Inside:     inside,
+			Key:        key,
+			Value:      v.String(),
+			Path:       path,
+		}))
+	}
+
+	return nil
+}
+
+func (w *structWalker) walkStruct(ctx context.Context, v reflect.Value, path []string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		tag, err := parseMaskTag(field.Tag.Get(StructTag))
+		if err != nil {
+			return fmt.Errorf("%w on field %s.%s", err, t.Name(), field.Name)
+		}
+
+		if tag != nil && tag.skip {
+			continue
+		}
+
+		name := field.Name
+		if jsonName := strings.Split(field.Tag.Get("json"), ",")[0]; jsonName != "" && jsonName != "-" {
+			name = jsonName
+		}
+
+		fieldPath := path
+		if !field.Anonymous {
+			fieldPath = append(path, name)
+		}
+
+		if err = w.walk(ctx, v.Field(i), name, fieldPath, tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package jsonutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+type structCard struct {
+	Number string `json:"number" mask:"last4"`
+	Holder string `json:"holder"`
+}
+
+type structUser struct {
+	Name     string                 `json:"name"`
+	Password string                 `json:"password" mask:"true"`
+	Email    *string                `json:"email" mask:"first2"`
+	Tokens   []string               `json:"tokens" mask:"true"`
+	Cards    []structCard           `json:"cards"`
+	Primary  *structCard            `json:"primary"`
+	Labels   map[string]string      `json:"labels"`
+	Extra    map[string]interface{} `json:"extra"`
+	Raw      string                 `json:"raw" mask:"-"`
+	Next     *structUser            `json:"next"`
+	secret   string
+}
+
+func TestTransformer_TransformStruct(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		Keys: map[string]jsonutil.StringTransformer{
+			"holder": func(ctx context.Context, info jsonutil.KVInfo) string { return "holder" },
+			"env":    func(ctx context.Context, info jsonutil.KVInfo) string { return "env" },
+			"key":    func(ctx context.Context, info jsonutil.KVInfo) string { return "key" },
+			"raw":    func(ctx context.Context, info jsonutil.KVInfo) string { return "must not be called" },
+		},
+	})
+
+	email := "john@example.com"
+	user := &structUser{
+		Name:     "john",
+		Password: "secret",
+		Email:    &email,
+		Tokens:   []string{"a", "b"},
+		Cards:    []structCard{{Number: "4111111111111111", Holder: "john"}},
+		Primary:  &structCard{Number: "5500000000000004", Holder: "john"},
+		Labels:   map[string]string{"env": "prod", "team": "core"},
+		Extra:    map[string]interface{}{"key": "value", "card": structCard{Number: "1234567890", Holder: "x"}},
+		Raw:      "raw",
+		secret:   "unexported",
+	}
+	user.Next = user
+
+	err := tr.TransformStruct(context.Background(), user)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "john", user.Name)
+	assert.Equal(t, jsonutil.DefaultPlaceholder, user.Password)
+	assert.Equal(t, "jo**************", email)
+	assert.Equal(t, []string{jsonutil.DefaultPlaceholder, jsonutil.DefaultPlaceholder}, user.Tokens)
+	assert.Equal(t, structCard{Number: "************1111", Holder: "holder"}, user.Cards[0])
+	assert.Equal(t, &structCard{Number: "************0004", Holder: "holder"}, user.Primary)
+	assert.Equal(t, map[string]string{"env": "env", "team": "core"}, user.Labels)
+	assert.Equal(t, "key", user.Extra["key"])
+	assert.Equal(t, structCard{Number: "******7890", Holder: "holder"}, user.Extra["card"])
+	assert.Equal(t, "raw", user.Raw)
+	assert.Equal(t, "unexported", user.secret)
+}
+
+type structWallet struct {
+	Recent  []string          `json:"recent" mask:"last4"`
+	All     []string          `json:"all" mask:"last4"`
+	Primary *structCard       `json:"primary"`
+	Number  *string           `json:"number" mask:"last4"`
+	Labels  map[string]string `json:"labels" mask:"first2"`
+	Same    map[string]string `json:"same" mask:"first2"`
+}
+
+func TestTransformer_TransformStruct_Aliasing(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{})
+
+	all := []string{"4111111111111111", "5500000000000004"}
+	labels := map[string]string{"a": "abcdef"}
+	card := &structCard{Number: "1234567890", Holder: "x"}
+	wallet := &structWallet{
+		Recent:  all[:1],
+		All:     all,
+		Primary: card,
+		Number:  &card.Number, // the same address as Primary, but a different type
+		Labels:  labels,
+		Same:    labels,
+	}
+
+	assert.NoError(t, tr.TransformStruct(context.Background(), wallet))
+	assert.Equal(t, []string{"************1111", "************0004"}, all)
+	assert.Equal(t, "******7890", card.Number)
+	assert.Equal(t, map[string]string{"a": "ab****"}, labels)
+}
+
+func TestTransformer_TransformStructError(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{})
+
+	err := tr.TransformStruct(context.Background(), structUser{})
+	assert.Error(t, err)
+
+	var nilUser *structUser
+	err = tr.TransformStruct(context.Background(), nilUser)
+	assert.Error(t, err)
+
+	type invalid struct {
+		Name string `mask:"middle"`
+	}
+	err = tr.TransformStruct(context.Background(), &invalid{Name: "x"})
+	assert.Error(t, err)
+}
+
+func TestTransformer_Transform_Struct(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		Keys: map[string]jsonutil.StringTransformer{
+			"holder": func(ctx context.Context, info jsonutil.KVInfo) string { return "holder" },
+			"env":    func(ctx context.Context, info jsonutil.KVInfo) string { return "env" },
+		},
+		InPlace: true,
+	})
+
+	email := "john@example.com"
+	user := &structUser{
+		Name:     "john",
+		Password: "secret",
+		Email:    &email,
+		Tokens:   []string{"a"},
+		Cards:    []structCard{{Number: "4111111111111111", Holder: "john"}},
+		Labels:   map[string]string{"env": "prod"},
+		Extra:    map[string]interface{}{"card": &structCard{Number: "1234567890", Holder: "x"}},
+		secret:   "unexported",
+	}
+	user.Next = user
+
+	out, err := tr.Transform(context.Background(), user)
+	assert.NoError(t, err)
+
+	masked, ok := out.(*structUser)
+	assert.True(t, ok)
+	assert.True(t, masked != user)
+	assert.True(t, masked.Next == masked, "self reference must point to the copy")
+	assert.Equal(t, jsonutil.DefaultPlaceholder, masked.Password)
+	assert.Equal(t, "jo**************", *masked.Email)
+	assert.Equal(t, []string{jsonutil.DefaultPlaceholder}, masked.Tokens)
+	assert.Equal(t, structCard{Number: "************1111", Holder: "holder"}, masked.Cards[0])
+	assert.Equal(t, map[string]string{"env": "env"}, masked.Labels)
+	assert.Equal(t, &structCard{Number: "******7890", Holder: "holder"}, masked.Extra["card"])
+	assert.Equal(t, "unexported", masked.secret)
+
+	// the input is not modified
+	assert.Equal(t, "secret", user.Password)
+	assert.Equal(t, "john@example.com", email)
+	assert.Equal(t, []string{"a"}, user.Tokens)
+	assert.Equal(t, structCard{Number: "4111111111111111", Holder: "john"}, user.Cards[0])
+	assert.Equal(t, map[string]string{"env": "prod"}, user.Labels)
+	assert.Equal(t, &structCard{Number: "1234567890", Holder: "x"}, user.Extra["card"])
+
+	// struct value return struct value
+	out, err = tr.Transform(context.Background(), structCard{Number: "4111111111111111", Holder: "john"})
+	assert.NoError(t, err)
+	assert.Equal(t, structCard{Number: "************1111", Holder: "holder"}, out)
+
+	type invalid struct {
+		Name string `mask:"middle"`
+	}
+	_, err = tr.Transform(context.Background(), invalid{Name: "x"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package jsonutil
+
+import (
+	"context"
+	"reflect"
+)
+
+// Summary describe what the Processor did to a document, see ProcessWithSummary.
+type Summary struct {
+	FieldsVisited int      // FieldsVisited is the number of leaves in the input (see Paths for the definition of leaf).
+	FieldsChanged int      // FieldsChanged is the number of input leaves which value is changed or removed.
+	BytesIn       int      // BytesIn is the input size.
+	BytesOut      int      // BytesOut is the output size.
+	Paths         []string // Paths is the dotted path of the changed leaves, in the order of Paths.
+}
+
+// ProcessWithSummary process doc using p (i.e: Transformer, Sanitizer or RuleSet) and return the Summary along with the output,
+// so the caller can log and alert on the sanitization effectiveness.
+// The Summary is computed by comparing the leaves of doc and the output, it costs one more decoding of both.
+func ProcessWithSummary(ctx context.Context, p Processor, doc []byte) ([]byte, Summary, error) {
+	out, err := p.Process(ctx, doc)
+	if err != nil {
+		return out, Summary{}, err
+	}
+
+	summary, err := summarize(doc, out)
+	return out, summary, err
+}
+
+func summarize(in, out []byte) (Summary, error) {
+	summary := Summary{BytesIn: len(in), BytesOut: len(out), Paths: []string{}}
+
+	var before, after interface{}
+	if err := decodeDocument(in, &before); err != nil {
+		return summary, err
+	}
+
+	if err := decodeDocument(out, &after); err != nil {
+		return summary, err
+	}
+
+	afterLeaves := map[string]interface{}{}
+	walkLeaves(after, []string{}, func(segments []string, v interface{}) {
+		afterLeaves[JoinPath(segments)] = v
+	})
+
+	walkLeaves(before, []string{}, func(segments []string, v interface{}) {
+		summary.FieldsVisited++
+
+		path := JoinPath(segments)
+		if newVal, ok := afterLeaves[path]; ok && reflect.DeepEqual(v, newVal) {
+			return
+		}
+
+		summary.FieldsChanged++
+		summary.Paths = append(summary.Paths, path)
+	})
+
+	return summary, nil
+}
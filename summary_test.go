@@ -0,0 +1,52 @@
+package jsonutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestProcessWithSummary(t *testing.T) {
+	ruleSet, err := jsonutil.NewRuleSet([]jsonutil.Rule{
+		{Keys: []string{"password"}, Action: jsonutil.ActionMask},
+		{Keys: []string{"internal"}, Action: jsonutil.ActionDrop},
+		{Paths: []string{"bio"}, Action: jsonutil.ActionTruncate, MaxChars: 3},
+	})
+	assert.NoError(t, err)
+
+	doc := `{"password":"secret","name":"alice","bio":"abcdef","internal":{"a":1,"b":[true]},"tags":["x"],"age":10}`
+	out, summary, err := jsonutil.ProcessWithSummary(context.Background(), ruleSet, []byte(doc))
+	assert.NoError(t, err)
+	assert.Equal(t, jsonutil.Summary{
+		FieldsVisited: 7,
+		FieldsChanged: 4,
+		BytesIn:       len(doc),
+		BytesOut:      len(out),
+		Paths:         []string{"bio", "internal.a", "internal.b.0", "password"},
+	}, summary)
+
+	// nothing changed
+	_, summary, err = jsonutil.ProcessWithSummary(context.Background(), jsonutil.NewSanitizer(jsonutil.SanitizerConfig{}), []byte(`[1, "a"]`))
+	assert.NoError(t, err)
+	assert.Equal(t, jsonutil.Summary{FieldsVisited: 2, BytesIn: 8, BytesOut: 8, Paths: []string{}}, summary)
+}
+
+func TestProcessWithSummary_Error(t *testing.T) {
+	failErr := errors.New("failed")
+	p := jsonutil.ProcessorFunc(func(ctx context.Context, doc []byte) ([]byte, error) {
+		return nil, failErr
+	})
+
+	_, _, err := jsonutil.ProcessWithSummary(context.Background(), p, []byte(`{}`))
+	assert.ErrorIs(t, err, failErr)
+
+	invalidOutput := jsonutil.ProcessorFunc(func(ctx context.Context, doc []byte) ([]byte, error) {
+		return []byte(`{`), nil
+	})
+
+	_, _, err = jsonutil.ProcessWithSummary(context.Background(), invalidOutput, []byte(`{}`))
+	assert.Error(t, err)
+}
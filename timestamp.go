@@ -0,0 +1,121 @@
+package jsonutil
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// DefaultTimestampLayouts is the layouts tried by NormalizeTimestamps when TimestampConfig.Layouts is empty.
+// Layout which is ambiguous (i.e: 01/02/2006 vs 02/01/2006) is not included, add it explicitly when the partner format is known.
+var DefaultTimestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999",
+	"2006/01/02 15:04:05",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.ANSIC,
+	"2006-01-02",
+}
+
+// TimestampConfig configure NormalizeTimestamps.
+type TimestampConfig struct {
+	// Keys limit the normalization only to the value of these keys.
+	// Empty means every string value is tried, and only the one which fully match one of the Layouts is changed.
+	Keys []string
+
+	// Layouts is the time.Parse layouts tried in order, default to DefaultTimestampLayouts.
+	Layouts []string
+
+	// Location is used for the layout without time zone, default to time.UTC.
+	Location *time.Location
+}
+
+// NormalizeTimestamps return StringTransformer which parse date/time string using the configured layouts
+// and re-emit it in RFC 3339 UTC, i.e: "Sun, 02 Jan 2022 10:04:05 +0700" become "2022-01-02T03:04:05Z".
+// Fractional second is kept when present. The value which cannot be parsed is returned as is.
+func NormalizeTimestamps(conf TimestampConfig) StringTransformer {
+	if len(conf.Layouts) == 0 {
+		conf.Layouts = DefaultTimestampLayouts
+	}
+
+	if conf.Location == nil {
+		conf.Location = time.UTC
+	}
+
+	keys := make(map[string]struct{}, len(conf.Keys))
+	for _, key := range conf.Keys {
+		keys[key] = struct{}{}
+	}
+
+	return func(ctx context.Context, info KVInfo) string {
+		if len(keys) > 0 {
+			if _, ok := keys[info.Key]; !ok {
+				return info.Value
+			}
+		} else if !looksLikeTimestamp(info.Value) {
+			return info.Value
+		}
+
+		t, ok := ParseTimestamp(info.Value, conf.Layouts, conf.Location)
+		if !ok {
+			return info.Value
+		}
+
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+}
+
+// ParseTimestamp parse str using the first matched layout, layout without time zone use loc.
+// Zone abbreviation which is not UTC, GMT or known by loc (i.e: EST when loc is UTC) is rejected,
+// because time.Parse would silently read it as UTC.
+func ParseTimestamp(str string, layouts []string, loc *time.Location) (time.Time, bool) {
+	for _, layout := range layouts {
+		t, err := time.ParseInLocation(layout, str, loc)
+		if err != nil {
+			continue
+		}
+
+		if strings.Contains(layout, "MST") && unknownZone(t, loc) {
+			continue
+		}
+
+		return t, true
+	}
+
+	return time.Time{}, false
+}
+
+// unknownZone return true when the zone abbreviation of t is made up by time.Parse with zero offset.
+func unknownZone(t time.Time, loc *time.Location) bool {
+	name, offset := t.Zone()
+	if offset != 0 || t.Location() == loc {
+		return false
+	}
+
+	return name != "UTC" && name != "GMT" && name != "Z"
+}
+
+// looksLikeTimestamp is a cheap check before trying every layout on every string value:
+// the shortest supported timestamp is a date (10 characters) and every timestamp has at least 4 digits.
+func looksLikeTimestamp(str string) bool {
+	if len(str) < 10 || len(str) > 64 {
+		return false
+	}
+
+	digits := 0
+	for i := 0; i < len(str); i++ {
+		if str[i] >= '0' && str[i] <= '9' {
+			digits++
+		}
+	}
+
+	return digits >= 4
+}
@@ -0,0 +1,83 @@
+package jsonutil_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestNormalizeTimestamps(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: jsonutil.NormalizeTimestamps(jsonutil.TimestampConfig{}),
+	})
+
+	out, err := tr.TransformBytes(context.Background(), []byte(`{
+		"a": "2022-01-02T10:04:05+07:00",
+		"b": "2022-01-02 03:04:05.123",
+		"c": "Sun, 02 Jan 2022 10:04:05 +0700",
+		"d": "2022-01-02",
+		"e": "Sun Jan  2 03:04:05 2022",
+		"f": ["2022/01/02 03:04:05", "not a date", "1234567890", "02/01/2022"],
+		"g": "2022-01-02 03:04:05 +0000 UTC"
+	}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"a": "2022-01-02T03:04:05Z",
+		"b": "2022-01-02T03:04:05.123Z",
+		"c": "2022-01-02T03:04:05Z",
+		"d": "2022-01-02T00:00:00Z",
+		"e": "2022-01-02T03:04:05Z",
+		"f": ["2022-01-02T03:04:05Z", "not a date", "1234567890", "02/01/2022"],
+		"g": "2022-01-02T03:04:05Z"
+	}`, string(out))
+}
+
+func TestNormalizeTimestamps_Config(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*3600)
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: jsonutil.NormalizeTimestamps(jsonutil.TimestampConfig{
+			Keys:     []string{"created_at"},
+			Layouts:  []string{"02/01/2006 15:04"},
+			Location: jakarta,
+		}),
+	})
+
+	out, err := tr.TransformBytes(context.Background(), []byte(`{"created_at":"02/01/2022 10:04","note":"02/01/2022 10:04","updated_at":"2022-01-02T10:04:05Z"}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"created_at":"2022-01-02T03:04:00Z","note":"02/01/2022 10:04","updated_at":"2022-01-02T10:04:05Z"}`, string(out))
+}
+
+func TestParseTimestamp(t *testing.T) {
+	ts, ok := jsonutil.ParseTimestamp("2022-01-02", jsonutil.DefaultTimestampLayouts, time.UTC)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), ts)
+
+	_, ok = jsonutil.ParseTimestamp("yesterday", jsonutil.DefaultTimestampLayouts, time.UTC)
+	assert.False(t, ok)
+}
+
+func TestParseTimestamp_ZoneAbbreviation(t *testing.T) {
+	for _, str := range []string{"Sun, 02 Jan 2022 10:04:05 EST", "Sunday, 02-Jan-22 10:04:05 PST", "02 Jan 22 10:04 CET"} {
+		_, ok := jsonutil.ParseTimestamp(str, jsonutil.DefaultTimestampLayouts, time.UTC)
+		assert.False(t, ok, str)
+	}
+
+	for _, str := range []string{"Sun, 02 Jan 2022 10:04:05 UTC", "Sun, 02 Jan 2022 10:04:05 GMT", "2022-01-02 10:04:05 +0000 UTC"} {
+		ts, ok := jsonutil.ParseTimestamp(str, jsonutil.DefaultTimestampLayouts, time.UTC)
+		assert.True(t, ok, str)
+		assert.True(t, time.Date(2022, 1, 2, 10, 4, 5, 0, time.UTC).Equal(ts), str)
+	}
+
+	// the abbreviation known by the location
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	ts, ok := jsonutil.ParseTimestamp("Sun, 02 Jan 2022 10:04:05 EST", jsonutil.DefaultTimestampLayouts, newYork)
+	assert.True(t, ok)
+	assert.True(t, time.Date(2022, 1, 2, 15, 4, 5, 0, time.UTC).Equal(ts))
+}
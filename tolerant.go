@@ -0,0 +1,218 @@
+package jsonutil
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SkippedError is a syntax error recovered by ParseTolerant.
+// Start and End is the span of the input which is skipped because of the error.
+type SkippedError struct {
+	Err        error
+	Start, End int
+}
+
+func (e *SkippedError) Error() string {
+	return fmt.Sprintf("jsonutil: skipped offset %d to %d: %v", e.Start, e.End, e.Err)
+}
+
+func (e *SkippedError) Unwrap() error {
+	return e.Err
+}
+
+// ParseTolerant is best-effort parser for partially corrupted document, such as truncated or corrupted log line.
+// On invalid syntax, the broken object member or array element is skipped until the next member or element,
+// and the skipped span is recorded in the returned errors. Unclosed object and array at the end is closed.
+// Object or array nested deeper than MaxDepth is skipped the same way.
+// The salvaged document is always valid JSON (written without insignificant whitespace),
+// or nil when there is nothing to salvage.
+func ParseTolerant(doc []byte) ([]byte, []*SkippedError) {
+	p := &tolerantParser{data: doc}
+
+	start := skipSpace(doc, 0)
+	out, end, err := p.value(make([]byte, 0, len(doc)), start, 0)
+	if err != nil {
+		p.skip(start, len(doc), err)
+		return nil, p.errs
+	}
+
+	if end = skipSpace(doc, end); end < len(doc) {
+		p.skip(end, len(doc), syntaxErr(doc, end, "after top-level value"))
+	}
+
+	return out, p.errs
+}
+
+type tolerantParser struct {
+	data []byte
+	errs []*SkippedError
+	eof  error // the unexpected end error, shared by every unclosed object and array
+}
+
+func (p *tolerantParser) skip(start, end int, err error) {
+	p.errs = append(p.errs, &SkippedError{Err: err, Start: start, End: end})
+}
+
+// value append the value starts at i, nested at depth, into out. Object and array is always recovered
+// unless it is nested deeper than MaxDepth, other value is returned with error when it is invalid.
+func (p *tolerantParser) value(out []byte, i int, depth int) ([]byte, int, error) {
+	if i >= len(p.data) {
+		return out, i, syntaxErr(p.data, i, "")
+	}
+
+	switch p.data[i] {
+	case '{', '[':
+		if depth >= MaxDepth {
+			return out, i, depthErr(i)
+		}
+
+		closing := byte('}')
+		if p.data[i] == '[' {
+			closing = ']'
+		}
+
+		return p.container(out, i, closing, depth+1)
+	}
+
+	end, err := scanValue(p.data, i)
+	if err != nil {
+		return out, i, err
+	}
+
+	return append(out, p.data[i:end]...), end, nil
+}
+
+// container append object (closing is '}') or array (closing is ']') starts at i into out,
+// depth is the nesting of its members or elements.
+func (p *tolerantParser) container(out []byte, i int, closing byte, depth int) ([]byte, int, error) {
+	after := "array element"
+	if closing == '}' {
+		after = "object key:value pair"
+	}
+
+	out = append(out, p.data[i])
+	i++
+	written := 0
+	for {
+		i = skipSpace(p.data, i)
+		if i >= len(p.data) {
+			if p.eof == nil {
+				p.eof = syntaxErr(p.data, i, "")
+			}
+
+			p.skip(i, i, p.eof)
+			return append(out, closing), i, nil
+		}
+
+		if p.data[i] == closing {
+			return append(out, closing), i + 1, nil
+		}
+
+		if p.data[i] == ',' {
+			// empty element, i.e: [1,,2]
+			p.skip(i, i+1, syntaxErr(p.data, i, "looking for beginning of value"))
+			i++
+			continue
+		}
+
+		// the broken member or element is removed from out
+		mark := len(out)
+		if written > 0 {
+			out = append(out, ',')
+		}
+
+		var (
+			end int
+			err error
+		)
+
+		if closing == '}' {
+			out, end, err = p.member(out, i, depth)
+		} else {
+			out, end, err = p.value(out, i, depth)
+		}
+
+		if err != nil {
+			out = out[:mark]
+			end = p.skipTo(i, closing)
+			p.skip(i, end, err)
+		} else {
+			written++
+
+			// the value must be followed by separator or closing, otherwise skip the garbage after it
+			end = skipSpace(p.data, end)
+			if end < len(p.data) && p.data[end] != ',' && p.data[end] != closing {
+				garbage := end
+				end = p.skipTo(garbage, closing)
+				p.skip(garbage, end, syntaxErr(p.data, garbage, "after "+after))
+			}
+		}
+
+		i = end
+		if i < len(p.data) && p.data[i] == ',' {
+			i++
+		}
+	}
+}
+
+// member append object member starts at i as `"key":value` into out.
+func (p *tolerantParser) member(out []byte, i int, depth int) ([]byte, int, error) {
+	keyEnd, err := scanString(p.data, i)
+	if err != nil {
+		return out, i, err
+	}
+
+	colon := skipSpace(p.data, keyEnd)
+	if colon >= len(p.data) || p.data[colon] != ':' {
+		return out, i, syntaxErr(p.data, colon, "after object key")
+	}
+
+	out = append(append(out, p.data[i:keyEnd]...), ':')
+	return p.value(out, skipSpace(p.data, colon+1), depth)
+}
+
+// skipTo return the offset of the next separator or closing at the same nesting level from i,
+// or the end of data.
+func (p *tolerantParser) skipTo(i int, closing byte) int {
+	depth := 0
+	for ; i < len(p.data); i++ {
+		switch c := p.data[i]; c {
+		case '"':
+			end, err := scanString(p.data, i)
+			if errors.Is(err, ErrUnexpectedEnd) {
+				return len(p.data)
+			}
+
+			if err != nil {
+				// broken string, skip until the closing quote
+				end = i + 1
+				for end < len(p.data) && p.data[end] != '"' {
+					end++
+				}
+				end++
+			}
+
+			i = end - 1
+
+		case '{', '[':
+			depth++
+
+		case '}', ']':
+			if depth == 0 && c == closing {
+				return i
+			}
+
+			// stray closing of other type on the same level is skipped as well
+			if depth > 0 {
+				depth--
+			}
+
+		case ',':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(p.data)
+}
@@ -0,0 +1,76 @@
+package jsonutil_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestParseTolerant(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Input   string
+		Output  string
+		Skipped []string
+	}{
+		{Name: "valid", Input: ` {"a": [1, "x"], "b": null} `, Output: `{"a":[1,"x"],"b":null}`},
+		{Name: "broken member", Input: `{"a":1,"b":tru,"c":3}`, Output: `{"a":1,"c":3}`, Skipped: []string{`"b":tru`}},
+		{Name: "missing colon", Input: `{"a" 1, "b": 2}`, Output: `{"b":2}`, Skipped: []string{`"a" 1`}},
+		{Name: "broken element", Input: `[1, 2x, {"a": [oops]}, 4]`, Output: `[1,2,{"a":[]},4]`, Skipped: []string{`x`, `oops`}},
+		{Name: "empty element", Input: `[1,,2]`, Output: `[1,2]`, Skipped: []string{`,`}},
+		{Name: "truncated", Input: `{"a":{"b":[1,2`, Output: `{"a":{"b":[1,2]}}`, Skipped: []string{``, ``, ``}},
+		{Name: "truncated string", Input: `{"a":1,"b":"unterminated`, Output: `{"a":1}`, Skipped: []string{`"b":"unterminated`, ``}},
+		{Name: "mismatched closing", Input: `{"a":[1,2}`, Output: `{"a":[1,2]}`, Skipped: []string{`}`, ``, ``}},
+		{Name: "trailing garbage", Input: `{"a":1} xyz`, Output: `{"a":1}`, Skipped: []string{`xyz`}},
+		{Name: "garbage after value", Input: `{"a":{"b":x} y,"c":1}`, Output: `{"a":{},"c":1}`, Skipped: []string{`"b":x`, `y`}},
+		{Name: "garbage after nested", Input: `[{"a":x} [oops]]`, Output: `[{}]`, Skipped: []string{`"a":x`, `[oops]`}},
+		{Name: "nothing to salvage", Input: `oops`, Output: ``, Skipped: []string{`oops`}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			out, errs := jsonutil.ParseTolerant([]byte(tc.Input))
+			assert.Equal(t, tc.Output, string(out))
+
+			skipped := make([]string, 0)
+			for _, err := range errs {
+				skipped = append(skipped, tc.Input[err.Start:err.End])
+
+				var syntaxErr *jsonutil.SyntaxError
+				assert.True(t, errors.As(err, &syntaxErr))
+			}
+
+			if tc.Skipped == nil {
+				tc.Skipped = []string{}
+			}
+			assert.Equal(t, tc.Skipped, skipped)
+
+			if out != nil {
+				assert.NoError(t, jsonutil.Validate(out))
+			}
+		})
+	}
+}
+
+func TestParseTolerant_Deep(t *testing.T) {
+	defer func(depth int) { jsonutil.MaxDepth = depth }(jsonutil.MaxDepth)
+	jsonutil.MaxDepth = 3
+
+	out, errs := jsonutil.ParseTolerant([]byte(`{"a":[[{"b":1}],2],"c":[3]}`))
+	assert.Equal(t, `{"a":[[],2],"c":[3]}`, string(out))
+	if assert.Len(t, errs, 1) {
+		assert.True(t, errors.Is(errs[0], jsonutil.ErrMaxDepthExceeded))
+		assert.Equal(t, 7, errs[0].Start)
+	}
+
+	jsonutil.MaxDepth = 10000
+	start := time.Now()
+	out, errs = jsonutil.ParseTolerant([]byte(strings.Repeat("[", 160*1024)))
+	assert.True(t, time.Since(start) < 5*time.Second, "took %s", time.Since(start))
+	assert.NoError(t, jsonutil.Validate(out))
+	assert.True(t, errors.Is(errs[0], jsonutil.ErrMaxDepthExceeded))
+}
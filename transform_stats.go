@@ -0,0 +1,80 @@
+package jsonutil
+
+import "sync"
+
+// KeyStats is the statistics of the string values with the same key, see TransformStats.
+// Bytes is measured on the decoded string, without quotes.
+type KeyStats struct {
+	Visited     int
+	Changed     int
+	BytesBefore int
+	BytesAfter  int
+}
+
+func (s *KeyStats) add(other KeyStats) {
+	s.Visited += other.Visited
+	s.Changed += other.Changed
+	s.BytesBefore += other.BytesBefore
+	s.BytesAfter += other.BytesAfter
+}
+
+// TransformStats collect the string values visited by Transformer with Config.Stats, i.e: to verify the redaction coverage.
+// It accumulates every transformed document until Reset, and is safe for concurrent use.
+type TransformStats struct {
+	mu   sync.Mutex
+	keys map[string]*KeyStats
+}
+
+// Keys return the copy of statistics per key, array element without key is counted under the empty key.
+func (s *TransformStats) Keys() map[string]KeyStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := make(map[string]KeyStats, len(s.keys))
+	for key, stats := range s.keys {
+		keys[key] = *stats
+	}
+
+	return keys
+}
+
+// Total return the sum of statistics of all keys.
+func (s *TransformStats) Total() KeyStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var total KeyStats
+	for _, stats := range s.keys {
+		total.add(*stats)
+	}
+
+	return total
+}
+
+// Reset remove all collected statistics.
+func (s *TransformStats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = nil
+}
+
+// record count the string value of key which is transformed from before into after.
+func (s *TransformStats) record(key, before, after string) {
+	stats := KeyStats{Visited: 1, BytesBefore: len(before), BytesAfter: len(after)}
+	if before != after {
+		stats.Changed = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys == nil {
+		s.keys = make(map[string]*KeyStats)
+	}
+
+	if _, ok := s.keys[key]; !ok {
+		s.keys[key] = &KeyStats{}
+	}
+
+	s.keys[key].add(stats)
+}
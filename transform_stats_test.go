@@ -0,0 +1,58 @@
+package jsonutil_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestTransformStats(t *testing.T) {
+	stats := &jsonutil.TransformStats{}
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		Keys: map[string]jsonutil.StringTransformer{
+			"password": func(ctx context.Context, info jsonutil.KVInfo) string { return "***" },
+		},
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if len(info.Value) > 4 {
+				return info.Value[:4]
+			}
+			return info.Value
+		},
+		Stats: stats,
+	})
+
+	_, err := tr.TransformBytes(context.Background(), []byte(`{"password":"secret","name":"john","bio":"long text","n":1}`))
+	assert.NoError(t, err)
+
+	err = tr.TransformStream(context.Background(), strings.NewReader(`{"password":"pw"} ["a"]`), &strings.Builder{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]jsonutil.KeyStats{
+		"password": {Visited: 2, Changed: 2, BytesBefore: 8, BytesAfter: 6},
+		"name":     {Visited: 1, Changed: 0, BytesBefore: 4, BytesAfter: 4},
+		"bio":      {Visited: 1, Changed: 1, BytesBefore: 9, BytesAfter: 4},
+		"":         {Visited: 1, Changed: 0, BytesBefore: 1, BytesAfter: 1},
+	}, stats.Keys())
+	assert.Equal(t, jsonutil.KeyStats{Visited: 5, Changed: 3, BytesBefore: 22, BytesAfter: 15}, stats.Total())
+
+	stats.Reset()
+	assert.Empty(t, stats.Keys())
+	assert.Equal(t, jsonutil.KeyStats{}, stats.Total())
+}
+
+func TestTransformStats_MaskKeys(t *testing.T) {
+	email, err := jsonutil.GlobKey("*@*", func(ctx context.Context, info jsonutil.KVInfo) string { return "[email]" })
+	assert.NoError(t, err)
+
+	stats := &jsonutil.TransformStats{}
+	tr := jsonutil.NewTransformer(jsonutil.Config{MaskKeys: []jsonutil.KeyPattern{email}, Stats: stats})
+
+	_, err = tr.TransformBytes(context.Background(), []byte(`{"john@example.com":"online","jane@example.com":["away"]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]jsonutil.KeyStats{
+		"[email]": {Visited: 2, Changed: 0, BytesBefore: 10, BytesAfter: 10},
+	}, stats.Keys())
+}
@@ -0,0 +1,345 @@
+package jsonutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// streamCheckInterval is the number of tokens between ctx checks in TransformStream.
+const streamCheckInterval = 1024
+
+// streamFrame is an open object or array in TransformStream.
+type streamFrame struct {
+	object bool
+	key    string // current member key, or the inherited key for array (see KVInfo.Key)
+	n      int    // number of written members or elements
+	skip   int    // number of elements deleted by Config.KeyFilter
+	raw    bool   // nested deeper than Config.MaxDepth, written as is
+
+	keys map[string]struct{} // written keys, only when Config.KeyTransformer is set
+}
+
+// TransformStream is like TransformBytes but read the documents from r token by token and write them into w,
+// so a multi-hundred-MB payload is never held in memory as a whole.
+// Every value is transformed the same way as Transform (StringTransformer, Paths, Keys, KeyPatterns and ValueTransformer),
+// numbers are written as is. Back-to-back documents (i.e: NDJSON) are accepted, each one is written followed by a newline.
+// The key order is preserved, the output is compact unless Config.StreamIndent is set.
+// Config.JSONMarshal and Config.JSONUnmarshal is not used in this mode. On error, w may contain partial output.
+func (m *Transformer) TransformStream(ctx context.Context, r io.Reader, w io.Writer) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			abort, ok := r.(transformError)
+			if !ok {
+				panic(r)
+			}
+
+			err = abort.err
+		}
+	}()
+
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	bw := bufio.NewWriter(w)
+	stack := make([]*streamFrame, 0)
+	path := make([]string, 0)
+
+	for tokens := 0; ; tokens++ {
+		if tokens%streamCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		// array element is filtered before reading it, so the deleted or kept one is read as a whole
+		if len(stack) > 0 && !stack[len(stack)-1].object && !stack[len(stack)-1].raw && m.Config.KeyFilter != nil && dec.More() {
+			top := stack[len(stack)-1]
+			path = append(path, strconv.Itoa(top.n+top.skip))
+			action := m.filterKey(ctx, KVInfo{IsTopLevel: len(stack) == 1, Inside: Array, Key: top.key, Path: path})
+
+			switch action {
+			case FilterDelete:
+				if err := skipStreamValue(dec); err != nil {
+					return err
+				}
+
+				top.skip++
+				path = path[:len(path)-1]
+				continue
+
+			case FilterKeep:
+				m.writeSeparator(bw, stack)
+				if err := m.copyStreamValue(dec, bw, len(stack)); err != nil {
+					return err
+				}
+
+				path = m.endStreamValue(bw, stack, path)
+				continue
+			}
+
+			path = path[:len(path)-1]
+		}
+
+		tok, err := dec.Token()
+		if err == io.EOF && len(stack) == 0 {
+			return bw.Flush()
+		}
+
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var top *streamFrame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		// object key
+		if key, ok := tok.(string); ok && top != nil && top.object && len(path) == len(stack)-1 {
+			top.key = key
+			path = append(path, key)
+
+			if top.raw {
+				m.writeSeparator(bw, stack)
+				m.writeStreamKey(bw, key)
+				continue
+			}
+
+			action := m.filterKey(ctx, KVInfo{IsTopLevel: len(stack) == 1, Inside: Object, Key: key, Path: path})
+			if action == FilterDelete {
+				if err := skipStreamValue(dec); err != nil {
+					return err
+				}
+
+				path = path[:len(path)-1]
+				continue
+			}
+
+			m.writeSeparator(bw, stack)
+			newKey := m.transformKey(ctx, path, len(stack) == 1)
+			if m.Config.KeyTransformer != nil {
+				if _, exists := top.keys[newKey]; exists {
+					return fmt.Errorf("%w: %s renamed to %q", ErrKeyCollision, JoinPath(path), newKey)
+				}
+
+				if top.keys == nil {
+					top.keys = make(map[string]struct{})
+				}
+				top.keys[newKey] = struct{}{}
+			}
+
+			m.writeStreamKey(bw, newKey)
+
+			if action == FilterKeep {
+				if err := m.copyStreamValue(dec, bw, len(stack)); err != nil {
+					return err
+				}
+
+				path = m.endStreamValue(bw, stack, path)
+			}
+			continue
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			if top.n > 0 {
+				m.writeIndent(bw, len(stack)-1)
+			}
+
+			bw.WriteByte(byte(delim))
+			stack = stack[:len(stack)-1]
+			path = m.endStreamValue(bw, stack, path)
+			continue
+		}
+
+		// value inside array
+		if top != nil && !top.object {
+			m.writeSeparator(bw, stack)
+			path = append(path, strconv.Itoa(top.n+top.skip))
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			if len(stack) >= MaxDepth {
+				return depthErr(int(dec.InputOffset()))
+			}
+
+			frame := &streamFrame{object: delim == '{'}
+			if top != nil {
+				frame.key = top.key
+				frame.raw = top.raw
+			}
+
+			// tooDeep return the ErrMaxDepthExceeded through the recover above when Config.MaxDepthError is set
+			if !frame.raw {
+				frame.raw = m.tooDeep(path)
+			}
+
+			bw.WriteByte(byte(delim))
+			stack = append(stack, frame)
+			continue
+		}
+
+		var out []byte
+		if top != nil && top.raw {
+			out, err = m.marshalStream(tok)
+		} else {
+			out, err = m.transformToken(ctx, top, len(stack) == 1, path, tok)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		bw.Write(out)
+		path = m.endStreamValue(bw, stack, path)
+	}
+}
+
+// endStreamValue mark the value on path as written.
+func (m *Transformer) endStreamValue(bw *bufio.Writer, stack []*streamFrame, path []string) []string {
+	if len(stack) == 0 {
+		bw.WriteByte('\n')
+		return path
+	}
+
+	stack[len(stack)-1].n++
+	return path[:len(path)-1]
+}
+
+// skipStreamValue read the next value of dec without writing it.
+func skipStreamValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			if delim == '{' || delim == '[' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// copyStreamValue write the next value of dec as is on the given depth, the value is held in memory as a whole.
+func (m *Transformer) copyStreamValue(dec *json.Decoder, bw *bufio.Writer, depth int) error {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if m.Config.StreamIndent == "" {
+		err = json.Compact(&buf, raw)
+	} else {
+		err = json.Indent(&buf, raw, strings.Repeat(m.Config.StreamIndent, depth), m.Config.StreamIndent)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(bw)
+	return err
+}
+
+// writeStreamKey write the object key followed by the colon.
+func (m *Transformer) writeStreamKey(bw *bufio.Writer, key string) {
+	b, _ := m.marshalStream(key)
+	bw.Write(b)
+	bw.WriteByte(':')
+	if m.Config.StreamIndent != "" {
+		bw.WriteByte(' ')
+	}
+}
+
+// writeSeparator write the comma before the next member or element of the innermost frame,
+// followed by the line break and indentation when Config.StreamIndent is set.
+func (m *Transformer) writeSeparator(bw *bufio.Writer, stack []*streamFrame) {
+	if stack[len(stack)-1].n > 0 {
+		bw.WriteByte(',')
+	}
+
+	m.writeIndent(bw, len(stack))
+}
+
+// writeIndent write the line break and the Config.StreamIndent depth times, nothing when it is empty.
+func (m *Transformer) writeIndent(bw *bufio.Writer, depth int) {
+	if m.Config.StreamIndent == "" {
+		return
+	}
+
+	bw.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		bw.WriteString(m.Config.StreamIndent)
+	}
+}
+
+// transformToken return the encoded transformed scalar token.
+func (m *Transformer) transformToken(ctx context.Context, top *streamFrame, isTopLevel bool, path []string, tok json.Token) ([]byte, error) {
+	if top == nil {
+		// top level scalar is not transformed, same as Transform
+		if str, ok := tok.(string); ok && m.topLevel != nil {
+			return m.marshalStream(m.topLevel(str))
+		}
+
+		return m.marshalStream(tok)
+	}
+
+	info := KVInfo{
+		IsTopLevel: isTopLevel,
+		Inside:     Array,
+		Key:        top.key,
+		Path:       path,
+	}
+
+	if top.object {
+		info.Inside = Object
+	}
+
+	var v interface{} = tok
+	if str, ok := tok.(string); ok {
+		info.Value = str
+		v = m.transformString(ctx, info)
+	} else if m.transformsValue() {
+		v = m.transformValue(ctx, info, tok)
+	}
+
+	out, err := m.marshalStream(v)
+	if err != nil {
+		return nil, fmt.Errorf("jsonutil: cannot encode transformed value on %q: %w", JoinPath(path), err)
+	}
+
+	return out, nil
+}
+
+// marshalStream encode the key or scalar written by TransformStream.
+func (m *Transformer) marshalStream(v interface{}) ([]byte, error) {
+	if m.rawHTML {
+		return canonicalJSON(v)
+	}
+
+	return json.Marshal(v)
+}
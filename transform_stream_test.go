@@ -0,0 +1,112 @@
+package jsonutil_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestTransformer_TransformStream(t *testing.T) {
+	password, err := jsonutil.GlobKey("pass*", func(ctx context.Context, info jsonutil.KVInfo) string { return "***" })
+	assert.NoError(t, err)
+
+	tr := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "email" {
+				return "xxx"
+			}
+			return info.Value
+		},
+		KeyPatterns: []jsonutil.KeyPattern{password},
+		Paths:       map[string]jsonutil.StringTransformer{"$.items[*].token": func(ctx context.Context, info jsonutil.KVInfo) string { return "tok" }},
+		ValueTransformer: func(ctx context.Context, info jsonutil.KVInfo, value interface{}) interface{} {
+			if info.Key == "pin" {
+				return nil
+			}
+			return value
+		},
+	})
+
+	in := `{"email":"a@example.com","password":"p","id":12345678901234567890,"items":[{"token":"t","email":["b@example.com"]},[1,"x"]],"empty":{},"arr":[],"pin":1234}
+["top", {"email": "c@example.com"}]
+"scalar" 10 null`
+
+	var out bytes.Buffer
+	assert.NoError(t, tr.TransformStream(context.Background(), strings.NewReader(in), &out))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Equal(t, 5, len(lines))
+	assert.Equal(t, `{"email":"xxx","password":"***","id":12345678901234567890,"items":[{"token":"tok","email":["xxx"]},[1,"x"]],"empty":{},"arr":[],"pin":null}`, lines[0])
+	assert.Equal(t, `["top",{"email":"xxx"}]`, lines[1])
+	assert.Equal(t, []string{`"scalar"`, `10`, `null`}, lines[2:])
+
+	// same result as TransformBytes
+	expected, err := tr.TransformBytes(context.Background(), []byte(largeArray))
+	assert.NoError(t, err)
+
+	out.Reset()
+	assert.NoError(t, tr.TransformStream(context.Background(), strings.NewReader(largeArray), &out))
+	assert.JSONEq(t, string(expected), out.String())
+}
+
+func TestTransformer_TransformStream_Error(t *testing.T) {
+	tr := jsonutil.NewTransformer(jsonutil.Config{})
+
+	err := tr.TransformStream(context.Background(), strings.NewReader(`{"a":[1,2`), ioutil.Discard)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+
+	err = tr.TransformStream(context.Background(), strings.NewReader(`{"a":}`), ioutil.Discard)
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = tr.TransformStream(ctx, strings.NewReader(`{}`), ioutil.Discard)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	defer func(depth int) { jsonutil.MaxDepth = depth }(jsonutil.MaxDepth)
+	jsonutil.MaxDepth = 2
+	err = tr.TransformStream(context.Background(), strings.NewReader(`[[[1]]]`), ioutil.Discard)
+	assert.True(t, errors.Is(err, jsonutil.ErrMaxDepthExceeded))
+}
+
+func TestTransformer_TransformStream_Indent(t *testing.T) {
+	config := jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "email" {
+				return "xxx"
+			}
+			return info.Value
+		},
+		KeyFilter: func(ctx context.Context, info jsonutil.KVInfo) jsonutil.FilterAction {
+			if info.Key == "raw" {
+				return jsonutil.FilterKeep
+			}
+			return jsonutil.FilterTransform
+		},
+		StreamIndent: "  ",
+	}
+
+	in := `{"email":"a@example.com","id":1,"items":[{"email":"b"},[]],"empty":{},"raw":{"email":["c", {}]}} ["x"]`
+
+	var expected bytes.Buffer
+	for _, doc := range []string{
+		`{"email":"xxx","id":1,"items":[{"email":"xxx"},[]],"empty":{},"raw":{"email":["c", {}]}}`,
+		`["x"]`,
+	} {
+		assert.NoError(t, json.Indent(&expected, []byte(doc), "", "  "))
+		expected.WriteByte('\n')
+	}
+
+	var buf bytes.Buffer
+	err := jsonutil.NewTransformer(config).TransformStream(context.Background(), strings.NewReader(in), &buf)
+	assert.NoError(t, err)
+	assert.Equal(t, expected.String(), buf.String())
+}
@@ -0,0 +1,285 @@
+package jsonutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// TruncateInfo describe the removed part of the truncated string, see TruncateOptions.Marker.
+// All of them is in characters (rune, not byte).
+type TruncateInfo struct {
+	Length  int // Length of the original string.
+	Escaped int // Escaped is the number of removed characters, End - Start.
+	Start   int // Start of the removed part.
+	End     int // End of the removed part, it is Length when nothing is kept at the end.
+}
+
+// TruncateOptions is the options of TruncateWithOptions.
+type TruncateOptions struct {
+	// MaxChars is the number of kept characters, negative means no truncation.
+	MaxChars int
+
+	// Padding is the number of characters (part of MaxChars) kept from the end of the string.
+	Padding int
+
+	// Marker return the text written in place of the removed part, default to DefaultTruncateMarker.
+	// i.e: "…" for ellipsis only, or fmt.Sprintf("…(+%d chars)", info.Escaped).
+	Marker func(info TruncateInfo) string
+
+	// Keys and Values select which strings of the document is cut by Truncate, only values when both are false.
+	Keys   bool
+	Values bool
+
+	// MaxDepth when more than zero, Truncate keep the strings nested deeper than MaxDepth as is (see KVInfo.Depth).
+	MaxDepth int
+
+	// Only when not empty limit Truncate to the strings inside the subtree selected by one of the selectors (see ParseSelector),
+	// everything else is kept as is. A plain key name (i.e: stacktrace) select that key at any depth, same as $..stacktrace.
+	Only []string
+}
+
+// TruncateOption configure Truncate.
+type TruncateOption func(*TruncateOptions)
+
+// TruncateMaxChars set TruncateOptions.MaxChars, without this option nothing is truncated.
+func TruncateMaxChars(n int) TruncateOption {
+	return func(o *TruncateOptions) {
+		o.MaxChars = n
+	}
+}
+
+// TruncatePadding set TruncateOptions.Padding.
+func TruncatePadding(n int) TruncateOption {
+	return func(o *TruncateOptions) {
+		o.Padding = n
+	}
+}
+
+// TruncateMarker set TruncateOptions.Marker.
+func TruncateMarker(marker func(info TruncateInfo) string) TruncateOption {
+	return func(o *TruncateOptions) {
+		o.Marker = marker
+	}
+}
+
+// TruncateMaxDepth set TruncateOptions.MaxDepth.
+func TruncateMaxDepth(n int) TruncateOption {
+	return func(o *TruncateOptions) {
+		o.MaxDepth = n
+	}
+}
+
+// TruncateOnly append the selectors to TruncateOptions.Only, i.e: TruncateOnly("stacktrace", "$.response.body").
+func TruncateOnly(selectors ...string) TruncateOption {
+	return func(o *TruncateOptions) {
+		o.Only = append(o.Only, selectors...)
+	}
+}
+
+// TruncateKeysOnly cut the object keys, the values are kept as is.
+// Keys of the same object cut into the same key fail with ErrKeyCollision.
+func TruncateKeysOnly() TruncateOption {
+	return func(o *TruncateOptions) {
+		o.Keys, o.Values = true, false
+	}
+}
+
+// TruncateValuesOnly cut the string values, the object keys are kept as is. It is the default.
+func TruncateValuesOnly() TruncateOption {
+	return func(o *TruncateOptions) {
+		o.Keys, o.Values = false, true
+	}
+}
+
+// Truncate cut the strings of JSON document data according to opts, i.e:
+//
+//	jsonutil.Truncate(ctx, data, jsonutil.TruncateMaxChars(100), jsonutil.TruncatePadding(20))
+//
+// The key order and number precision is preserved, the output is compact. Invalid JSON is rejected before anything is cut,
+// the escaped characters are decoded and written back in the shortest form (i.e: "\u00e9" as "é"), <, > and & are not escaped.
+func Truncate(ctx context.Context, data []byte, opts ...TruncateOption) ([]byte, error) {
+	m, err := truncateTransformer(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = scanDocument(data); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = m.TransformStream(ctx, bytes.NewReader(data), &buf); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// TruncateStream is like Truncate but read the documents from r token by token and write them into w,
+// so a multi-GB export can be cut for preview without loading it into memory.
+// Back-to-back documents (i.e: NDJSON) are accepted, each one is written followed by a newline.
+// On error, w may contain partial output.
+func TruncateStream(ctx context.Context, r io.Reader, w io.Writer, opts ...TruncateOption) error {
+	m, err := truncateTransformer(opts)
+	if err != nil {
+		return err
+	}
+
+	return m.TransformStream(ctx, r, w)
+}
+
+// truncateTransformer apply opts and return the Transformer for Truncate and TruncateStream.
+func truncateTransformer(opts []TruncateOption) (*Transformer, error) {
+	o := TruncateOptions{MaxChars: -1}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	for _, selector := range o.selectors() {
+		if _, err := ParseSelector(selector); err != nil {
+			return nil, err
+		}
+	}
+
+	return o.transformer(), nil
+}
+
+// transformer return Transformer which cut the strings selected by o.
+func (o TruncateOptions) transformer() *Transformer {
+	conf := Config{Include: o.selectors()}
+	include := compileSelectors(conf.Include)
+	if o.Values || !o.Keys {
+		conf.StringTransformer = func(ctx context.Context, info KVInfo) string {
+			if o.MaxDepth > 0 && info.Depth > o.MaxDepth {
+				return info.Value
+			}
+
+			return TruncateWithOptions(info.Value, o)
+		}
+	}
+
+	if o.Keys {
+		conf.KeyTransformer = func(ctx context.Context, path []string, key string) string {
+			if o.MaxDepth > 0 && len(path) > o.MaxDepth {
+				return key
+			}
+
+			if len(include) > 0 && !matchSubtree(include, path) {
+				return key
+			}
+
+			return TruncateWithOptions(key, o)
+		}
+	}
+
+	m := NewTransformer(conf)
+	m.rawHTML = true
+
+	// top level string is not transformed by Config.StringTransformer
+	if (o.Values || !o.Keys) && (len(include) == 0 || matchSubtree(include, nil)) {
+		m.topLevel = func(str string) string {
+			return TruncateWithOptions(str, o)
+		}
+	}
+
+	return m
+}
+
+// selectors return TruncateOptions.Only as selectors, with plain key name turned into $..key.
+func (o TruncateOptions) selectors() []string {
+	selectors := make([]string, 0, len(o.Only))
+	for _, only := range o.Only {
+		if only != "" && !strings.ContainsAny(only, "$.[]") {
+			only = "$.." + only
+		}
+
+		selectors = append(selectors, only)
+	}
+
+	return selectors
+}
+
+// DefaultTruncateMarker return " **escaped N chars**", followed by a space when the end of the string is kept.
+func DefaultTruncateMarker(info TruncateInfo) string {
+	if info.End == info.Length {
+		return fmt.Sprintf(" **escaped %d chars**", info.Escaped)
+	}
+
+	return fmt.Sprintf(" **escaped %d chars** ", info.Escaped)
+}
+
+// TruncateWithOptions keep opts.MaxChars characters (rune, not byte) of str, the last opts.Padding of them from the end,
+// and replace the rest with the marker. It never split a multi-byte UTF-8 character.
+// String which length is not more than opts.MaxChars is returned as is.
+func TruncateWithOptions(str string, opts TruncateOptions) string {
+	if opts.MaxChars < 0 || utf8.RuneCountInString(str) <= opts.MaxChars {
+		return str
+	}
+
+	padding := opts.Padding
+	if padding < 0 {
+		padding = 0
+	}
+
+	if padding > opts.MaxChars {
+		padding = opts.MaxChars
+	}
+
+	marker := opts.Marker
+	if marker == nil {
+		marker = DefaultTruncateMarker
+	}
+
+	runes := []rune(str)
+	info := TruncateInfo{
+		Length: len(runes),
+		Start:  opts.MaxChars - padding,
+		End:    len(runes) - padding,
+	}
+	info.Escaped = info.End - info.Start
+
+	return string(runes[:info.Start]) + marker(info) + string(runes[info.End:])
+}
+
+// TruncateString cut str into maxChars characters (rune, not byte) and append marker
+// telling how many characters is escaped, i.e: "Lorem ipsu **escaped 435 chars**".
+// String which length is not more than maxChars is returned as is.
+func TruncateString(str string, maxChars int) string {
+	return TruncateWithOptions(str, TruncateOptions{MaxChars: maxChars})
+}
+
+// TruncateHeadTail keep the first head and the last tail characters (rune, not byte) of str
+// and replace the middle with marker, i.e: "Lorem ipsu **escaped 415 chars** id est laborum.".
+// It never split a multi-byte UTF-8 character. String which length is not more than head+tail is returned as is.
+func TruncateHeadTail(str string, head, tail int) string {
+	if head < 0 {
+		head = 0
+	}
+
+	if tail < 0 {
+		tail = 0
+	}
+
+	return TruncateWithOptions(str, TruncateOptions{MaxChars: head + tail, Padding: tail})
+}
+
+// truncatedBytes return the number of bytes of str removed by TruncateString(str, maxChars).
+func truncatedBytes(str string, maxChars int) int {
+	if maxChars < 0 {
+		return 0
+	}
+
+	n := 0
+	for i := range str {
+		if n == maxChars {
+			return len(str) - i
+		}
+		n++
+	}
+
+	return 0
+}
@@ -0,0 +1,157 @@
+package jsonutil
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+)
+
+// shrinkCandidate is the string or array which is going to be shortened by TruncateToSize.
+type shrinkCandidate struct {
+	size  int // size of the encoded value
+	value interface{}
+	set   func(v interface{})
+}
+
+// truncatedString is the string already shortened by TruncateToSize.
+// It keeps the original, so cutting it again start from the original and the marker counts every removed character.
+type truncatedString struct {
+	original string
+	keep     int
+}
+
+func (s truncatedString) MarshalJSON() ([]byte, error) {
+	return canonicalJSON(TruncateString(s.original, s.keep))
+}
+
+// TruncateToSize return the minified data which is not longer than maxBytes, i.e: to fit the log line limit.
+// Until the output fits, the largest string is cut using TruncateString (to what is needed, or half of it at most)
+// and the largest array loses half of its elements from the end, so the output is always valid JSON.
+// Numbers keep their precision and HTML characters are not escaped.
+// Like Canonicalize, object keys in the output are sorted, the original key order is not kept.
+// When there is nothing left to shorten, ErrDocumentTooLarge is returned.
+func TruncateToSize(ctx context.Context, data []byte, maxBytes int) ([]byte, error) {
+	if _, err := scanDocument(data); err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	if err := decodeDocument(data, &doc); err != nil {
+		return nil, err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		out, err := canonicalJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(out) <= maxBytes {
+			return out, nil
+		}
+
+		var largest shrinkCandidate
+		findLargest(doc, func(v interface{}) { doc = v }, &largest)
+		if largest.set == nil {
+			return nil, fmt.Errorf("%w: cannot fit into %d bytes, %d bytes left", ErrDocumentTooLarge, maxBytes, len(out))
+		}
+
+		shrink(largest, len(out)-maxBytes)
+	}
+}
+
+// findLargest return the encoded size of v, and record the largest shrinkable string or array inside v into largest.
+func findLargest(v interface{}, set func(v interface{}), largest *shrinkCandidate) int {
+	size := 0
+	switch val := v.(type) {
+	case map[string]interface{}:
+		size = 2 + len(val) - 1
+		for key, child := range val {
+			k, _ := canonicalJSON(key)
+			key := key
+			size += len(k) + 1 + findLargest(child, func(v interface{}) { val[key] = v }, largest)
+		}
+
+		if len(val) == 0 {
+			size = 2
+		}
+		return size
+
+	case []interface{}:
+		size = 2 + len(val) - 1
+		for i, child := range val {
+			i := i
+			size += findLargest(child, func(v interface{}) { val[i] = v }, largest)
+		}
+
+		if len(val) == 0 {
+			return 2
+		}
+
+	case string:
+		b, _ := canonicalJSON(val)
+		size = len(b)
+		// the string not longer than the marker would grow
+		if n := utf8.RuneCountInString(val); n <= markerLength(n) {
+			return size
+		}
+
+	case truncatedString:
+		b, _ := canonicalJSON(val)
+		size = len(b)
+		if val.keep == 0 {
+			return size
+		}
+
+	default:
+		b, _ := canonicalJSON(val)
+		return len(b)
+	}
+
+	if size > largest.size {
+		*largest = shrinkCandidate{size: size, value: v, set: set}
+	}
+
+	return size
+}
+
+// markerLength return the maximum length of DefaultTruncateMarker for string of n characters.
+func markerLength(n int) int {
+	return len(DefaultTruncateMarker(TruncateInfo{Length: n, Escaped: n, End: n}))
+}
+
+// shrink shorten the candidate by excess bytes when possible, or by half.
+func shrink(c shrinkCandidate, excess int) {
+	switch val := c.value.(type) {
+	case string:
+		n := utf8.RuneCountInString(val)
+		marker := markerLength(n)
+		keep := n - excess - marker
+		if keep < 0 {
+			keep = 0
+		}
+
+		// halving leave some content for the other strings, unless it doesn't make the string shorter
+		if keep < n/2 && n/2+marker < n {
+			keep = n / 2
+		}
+
+		c.set(truncatedString{original: val, keep: keep})
+
+	case truncatedString:
+		// the marker is already there, only the kept characters are removed
+		keep := val.keep - excess
+		if keep < val.keep/2 {
+			keep = val.keep / 2
+		}
+
+		c.set(truncatedString{original: val.original, keep: keep})
+
+	case []interface{}:
+		c.set(val[:len(val)/2])
+	}
+}
@@ -0,0 +1,64 @@
+package jsonutil_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestTruncateToSize(t *testing.T) {
+	ctx := context.Background()
+	long := strings.Repeat("a", 1000)
+	doc := []byte(`{"id": 12345678901234567890, "message": "` + long + `", "stack": "` + strings.Repeat("こ", 500) + `", "items": [` +
+		strings.TrimSuffix(strings.Repeat(`{"n":1},`, 200), ",") + `]}`)
+
+	out, err := jsonutil.TruncateToSize(ctx, doc, 100000)
+	assert.NoError(t, err)
+	assert.True(t, json.Valid(out))
+	assert.Equal(t, len(doc)-7, len(out), "only whitespace is removed")
+
+	for _, maxBytes := range []int{4096, 1024, 512, 256, 128} {
+		out, err = jsonutil.TruncateToSize(ctx, doc, maxBytes)
+		assert.NoError(t, err, maxBytes)
+		assert.True(t, len(out) <= maxBytes, "%d bytes output for budget %d", len(out), maxBytes)
+		assert.True(t, json.Valid(out), string(out))
+		assert.Contains(t, string(out), `"id":12345678901234567890`)
+		if maxBytes <= 1024 {
+			assert.Contains(t, string(out), "escaped")
+		}
+	}
+
+	// short strings and object keys can't be shortened
+	_, err = jsonutil.TruncateToSize(ctx, []byte(`{"a":"b","c":"d"}`), 10)
+	assert.True(t, errors.Is(err, jsonutil.ErrDocumentTooLarge))
+
+	out, err = jsonutil.TruncateToSize(ctx, []byte(`"`+long+`"`), 50)
+	assert.NoError(t, err)
+	assert.True(t, len(out) <= 50, string(out))
+
+	_, err = jsonutil.TruncateToSize(ctx, []byte(`{"a":`), 50)
+	assert.Error(t, err)
+}
+
+func TestTruncateToSize_Marker(t *testing.T) {
+	doc := []byte(`{"a":"` + strings.Repeat("a", 1000) + `","b":"` + strings.Repeat("b", 300) + `"}`)
+	out, err := jsonutil.TruncateToSize(context.Background(), doc, 100)
+	assert.NoError(t, err)
+	assert.True(t, len(out) <= 100, string(out))
+
+	var values map[string]string
+	assert.NoError(t, json.Unmarshal(out, &values))
+	for key, length := range map[string]int{"a": 1000, "b": 300} {
+		// the string is cut once from the original, so there is only one marker with the total removed characters
+		value := values[key]
+		assert.Equal(t, 1, strings.Count(value, "escaped"), value)
+
+		kept := strings.Index(value, " **")
+		assert.Equal(t, jsonutil.TruncateString(strings.Repeat(key, length), kept), value)
+	}
+}
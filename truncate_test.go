@@ -0,0 +1,241 @@
+package jsonutil_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestTruncateString(t *testing.T) {
+	assert.Equal(t, "hello", jsonutil.TruncateString("hello", 5))
+	assert.Equal(t, "hel **escaped 2 chars**", jsonutil.TruncateString("hello", 3))
+	assert.Equal(t, "こん **escaped 3 chars**", jsonutil.TruncateString("こんにちは", 2))
+	assert.Equal(t, "hello", jsonutil.TruncateString("hello", -1))
+}
+
+// multilingualCorpus is strings with multi-byte UTF-8 characters, the truncation must never split them.
+var multilingualCorpus = []string{
+	"こんにちは世界、今日はいい天気ですね",
+	"😀😃😄😁😆😅🤣😂🙂🙃",
+	"👨‍👩‍👧‍👦 family emoji with zero width joiner 👩🏽‍💻",
+	"مرحبا بالعالم، كيف حالك اليوم",
+	"Приветствую тебя, мир",
+	"e\u0301 combining acute accent e\u0301",
+	"한국어 텍스트와 English mixed 中文",
+	"\u00e9\u00e8\u00ea ascii tail",
+}
+
+func TestTruncateString_Multilingual(t *testing.T) {
+	for _, str := range multilingualCorpus {
+		for maxChars := 0; maxChars <= utf8.RuneCountInString(str)+1; maxChars++ {
+			out := jsonutil.TruncateString(str, maxChars)
+			assert.True(t, utf8.ValidString(out), "%q truncated into %d chars: %q", str, maxChars, out)
+		}
+	}
+}
+
+func TestTruncateHeadTail(t *testing.T) {
+	assert.Equal(t, "hello", jsonutil.TruncateHeadTail("hello", 3, 2))
+	assert.Equal(t, "he **escaped 1 chars** lo", jsonutil.TruncateHeadTail("hello", 2, 2))
+	assert.Equal(t, "h **escaped 4 chars**", jsonutil.TruncateHeadTail("hello", 1, 0))
+	assert.Equal(t, " **escaped 4 chars** o", jsonutil.TruncateHeadTail("hello", -1, 1))
+	assert.Equal(t, "こ **escaped 2 chars** ちは", jsonutil.TruncateHeadTail("こんにちは", 1, 2))
+	assert.Equal(t, "😀 **escaped 8 chars** 🙃", jsonutil.TruncateHeadTail("😀😃😄😁😆😅🤣😂🙂🙃", 1, 1))
+
+	for _, str := range multilingualCorpus {
+		length := utf8.RuneCountInString(str)
+		for head := 0; head <= length; head++ {
+			for tail := 0; head+tail <= length+1; tail++ {
+				out := jsonutil.TruncateHeadTail(str, head, tail)
+				assert.True(t, utf8.ValidString(out), "%q truncated with %d:%d: %q", str, head, tail, out)
+			}
+		}
+	}
+}
+
+func TestTruncateWithOptions(t *testing.T) {
+	str := "Lorem ipsum dolor sit amet"
+
+	assert.Equal(t, str, jsonutil.TruncateWithOptions(str, jsonutil.TruncateOptions{MaxChars: -1}))
+	assert.Equal(t, str, jsonutil.TruncateWithOptions(str, jsonutil.TruncateOptions{MaxChars: 26}))
+	assert.Equal(t, "Lorem **escaped 21 chars**", jsonutil.TruncateWithOptions(str, jsonutil.TruncateOptions{MaxChars: 5}))
+	assert.Equal(t, "Lor **escaped 21 chars** et", jsonutil.TruncateWithOptions(str, jsonutil.TruncateOptions{MaxChars: 5, Padding: 2}))
+	assert.Equal(t, " **escaped 21 chars**  amet", jsonutil.TruncateWithOptions(str, jsonutil.TruncateOptions{MaxChars: 5, Padding: 10}))
+
+	ellipsis := func(info jsonutil.TruncateInfo) string { return "…" }
+	assert.Equal(t, "Lorem…", jsonutil.TruncateWithOptions(str, jsonutil.TruncateOptions{MaxChars: 5, Marker: ellipsis}))
+
+	var got jsonutil.TruncateInfo
+	count := func(info jsonutil.TruncateInfo) string {
+		got = info
+		return fmt.Sprintf("…(+%d chars)", info.Escaped)
+	}
+	assert.Equal(t, "こん…(+6 chars)す", jsonutil.TruncateWithOptions("こんにちは世界です", jsonutil.TruncateOptions{MaxChars: 3, Padding: 1, Marker: count}))
+	assert.Equal(t, jsonutil.TruncateInfo{Length: 9, Escaped: 6, Start: 2, End: 8}, got)
+}
+
+func TestTruncate(t *testing.T) {
+	ctx := context.Background()
+	doc := []byte(`{"name":"john doe","id":12345678901234567890,"items":[{"description":"long description"}],"long_key_name":"ok"}`)
+
+	out, err := jsonutil.Truncate(ctx, doc)
+	assert.NoError(t, err)
+	assert.Equal(t, string(doc), string(out))
+
+	out, err = jsonutil.Truncate(ctx, doc, jsonutil.TruncateMaxChars(4))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"john **escaped 4 chars**","id":12345678901234567890,"items":[{"description":"long **escaped 12 chars**"}],"long_key_name":"ok"}`, string(out))
+
+	ellipsis := jsonutil.TruncateMarker(func(info jsonutil.TruncateInfo) string { return "…" })
+	out, err = jsonutil.Truncate(ctx, doc, jsonutil.TruncateMaxChars(4), jsonutil.TruncatePadding(1), ellipsis, jsonutil.TruncateMaxDepth(1))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"joh…e","id":12345678901234567890,"items":[{"description":"long description"}],"long_key_name":"ok"}`, string(out))
+
+	out, err = jsonutil.Truncate(ctx, doc, jsonutil.TruncateMaxChars(5), jsonutil.TruncateKeysOnly(), ellipsis)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"john doe","id":12345678901234567890,"items":[{"descr…":"long description"}],"long_…":"ok"}`, string(out))
+
+	both := func(o *jsonutil.TruncateOptions) { o.Keys, o.Values = true, true }
+	out, err = jsonutil.Truncate(ctx, []byte(`{"long_key":"long value"}`), jsonutil.TruncateMaxChars(4), ellipsis, both)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"long…":"long…"}`, string(out))
+
+	_, err = jsonutil.Truncate(ctx, []byte(`{"long_a":1,"long_b":2}`), jsonutil.TruncateMaxChars(4), jsonutil.TruncateKeysOnly())
+	assert.True(t, errors.Is(err, jsonutil.ErrKeyCollision))
+
+	out, err = jsonutil.Truncate(ctx, []byte(` "top level" `), jsonutil.TruncateMaxChars(3), jsonutil.TruncateValuesOnly(), ellipsis)
+	assert.NoError(t, err)
+	assert.Equal(t, `"top…"`, string(out))
+
+	for _, invalid := range []string{``, `{"a":`, `{} {}`, `"a`} {
+		_, err = jsonutil.Truncate(ctx, []byte(invalid), jsonutil.TruncateMaxChars(1))
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestTruncate_Only(t *testing.T) {
+	ctx := context.Background()
+	doc := []byte(`{"message":"request failed","error":{"stacktrace":"main.go:10 main.go:20","code":"E_TIMEOUT"},` +
+		`"response":{"body":"upstream error page","status":"gateway timeout"}}`)
+	ellipsis := jsonutil.TruncateMarker(func(info jsonutil.TruncateInfo) string { return "…" })
+
+	out, err := jsonutil.Truncate(ctx, doc, jsonutil.TruncateMaxChars(4), ellipsis, jsonutil.TruncateOnly("stacktrace", "$.response.body"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"message":"request failed","error":{"stacktrace":"main…","code":"E_TIMEOUT"},`+
+		`"response":{"body":"upst…","status":"gateway timeout"}}`, string(out))
+
+	// whole subtree
+	out, err = jsonutil.Truncate(ctx, doc, jsonutil.TruncateMaxChars(4), ellipsis, jsonutil.TruncateOnly("$.response"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"message":"request failed","error":{"stacktrace":"main.go:10 main.go:20","code":"E_TIMEOUT"},`+
+		`"response":{"body":"upst…","status":"gate…"}}`, string(out))
+
+	out, err = jsonutil.Truncate(ctx, doc, jsonutil.TruncateMaxChars(4), ellipsis, jsonutil.TruncateKeysOnly(), jsonutil.TruncateOnly("error"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"message":"request failed","erro…":{"stac…":"main.go:10 main.go:20","code":"E_TIMEOUT"},`+
+		`"response":{"body":"upstream error page","status":"gateway timeout"}}`, string(out))
+
+	out, err = jsonutil.Truncate(ctx, []byte(`"top level"`), jsonutil.TruncateMaxChars(3), ellipsis, jsonutil.TruncateOnly("stacktrace"))
+	assert.NoError(t, err)
+	assert.Equal(t, `"top level"`, string(out))
+
+	_, err = jsonutil.Truncate(ctx, doc, jsonutil.TruncateMaxChars(4), jsonutil.TruncateOnly("$.items[0"))
+	assert.Error(t, err)
+}
+
+func TestTruncate_Escapes(t *testing.T) {
+	ctx := context.Background()
+	cases := map[string]string{
+		`{"a":"quote \" and backslash \\"}`:     `{"a":"quote \" and backslash \\"}`,
+		`{"a":"\u00e9\/\n\r\t"}`:                `{"a":"é/\n\r\t"}`,
+		`{"a":"\ud83d\ude00 \u0000"}`:           `{"a":"😀 \u0000"}`,
+		`{"<b>":"<a href='x'>&amp;</a>"}`:       `{"<b>":"<a href='x'>&amp;</a>"}`,
+		`{"\"":"\\\""}`:                         `{"\"":"\\\""}`,
+		`["",  "\\", "\"\""]`:                   `["","\\","\"\""]`,
+		`{"a":"line\u2028separator","b":"\\u"}`: "{\"a\":\"line\\u2028separator\",\"b\":\"\\\\u\"}",
+	}
+
+	for in, expected := range cases {
+		out, err := jsonutil.Truncate(ctx, []byte(in), jsonutil.TruncateMaxChars(100))
+		assert.NoError(t, err, in)
+		assert.Equal(t, expected, string(out), in)
+	}
+
+	out, err := jsonutil.Truncate(ctx, []byte(`{"a":"\"\\\"\\\""}`), jsonutil.TruncateMaxChars(3), jsonutil.TruncateMarker(func(info jsonutil.TruncateInfo) string { return "…" }))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":"\"\\\"…"}`, string(out))
+
+	out, err = jsonutil.Truncate(ctx, []byte(`"<tag> & \u00e9"`), jsonutil.TruncateMaxChars(100))
+	assert.NoError(t, err)
+	assert.Equal(t, `"<tag> & é"`, string(out))
+
+	invalid := []string{`""""`, `"\x"`, `"\u12"`, `"\u12g4"`, `"a` + "\n" + `b"`, `{"a":"b\"}`, `["a" "b"]`, `{"a" 1}`, `'a'`}
+	for _, in := range invalid {
+		_, err = jsonutil.Truncate(ctx, []byte(in), jsonutil.TruncateMaxChars(1))
+		assert.Error(t, err, in)
+	}
+}
+
+func TestTruncateStream(t *testing.T) {
+	ctx := context.Background()
+	ellipsis := jsonutil.TruncateMarker(func(info jsonutil.TruncateInfo) string { return "…" })
+	in := `{"id":1,"body":"first body"}
+{"id":2,"body":"second <body>","tags":["alpha","beta"]}
+"top level string"
+12345678901234567890
+`
+
+	var out bytes.Buffer
+	err := jsonutil.TruncateStream(ctx, strings.NewReader(in), &out, jsonutil.TruncateMaxChars(4), ellipsis)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":1,"body":"firs…"}
+{"id":2,"body":"seco…","tags":["alph…","beta"]}
+"top …"
+12345678901234567890
+`, out.String())
+
+	out.Reset()
+	err = jsonutil.TruncateStream(ctx, strings.NewReader(in), &out, jsonutil.TruncateMaxChars(4), ellipsis, jsonutil.TruncateOnly("tags"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":1,"body":"first body"}
+{"id":2,"body":"second <body>","tags":["alph…","beta"]}
+"top level string"
+12345678901234567890
+`, out.String())
+
+	// large input is read as it goes
+	r, w := io.Pipe()
+	go func() {
+		for i := 0; i < 10000; i++ {
+			fmt.Fprintf(w, `{"n":%d,"text":"%s"}`+"\n", i, strings.Repeat("x", 100))
+		}
+		w.Close()
+	}()
+
+	out.Reset()
+	err = jsonutil.TruncateStream(ctx, r, &out, jsonutil.TruncateMaxChars(2), ellipsis)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	assert.Len(t, lines, 10000)
+	assert.Equal(t, `{"n":9999,"text":"xx…"}`, lines[len(lines)-1])
+
+	err = jsonutil.TruncateStream(ctx, strings.NewReader(`{"a":"b"} {"a":`), ioutil.Discard, jsonutil.TruncateMaxChars(1))
+	assert.Error(t, err)
+
+	err = jsonutil.TruncateStream(ctx, strings.NewReader(`{}`), ioutil.Discard, jsonutil.TruncateOnly("$["))
+	assert.Error(t, err)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = jsonutil.TruncateStream(cancelled, strings.NewReader(in), ioutil.Discard)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
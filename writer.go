@@ -0,0 +1,158 @@
+package jsonutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// DefaultMaxBufferSize is the maximum size of incomplete document held by MaskWriter when MaxBufferSize is not set.
+const DefaultMaxBufferSize = 1 << 20
+
+// MaskWriter is an io.Writer which buffer the written bytes until it forms complete JSON document,
+// sanitize it using Processor and forward it to the underlying writer followed by a newline.
+// It accepts NDJSON lines as well as documents written in multiple Write call.
+type MaskWriter struct {
+	// MaxBufferSize is the maximum size in bytes of incomplete document waiting for the next Write.
+	// When it is exceeded, the document is dropped until the next newline and ErrDocumentTooLarge is returned.
+	// Default to DefaultMaxBufferSize, it must be set before the first Write.
+	MaxBufferSize int
+
+	w         io.Writer
+	processor Processor
+
+	mu   sync.Mutex
+	buf  []byte
+	skip bool // the rest of dropped document is discarded until the next newline
+}
+
+var _ io.Writer = (*MaskWriter)(nil)
+
+// NewMaskWriter return MaskWriter which sanitize every JSON document using p before written to w.
+func NewMaskWriter(w io.Writer, p Processor) *MaskWriter {
+	return &MaskWriter{w: w, processor: p}
+}
+
+// Write buffer p and write every complete document to the underlying writer.
+// When the data is not a valid JSON, it is dropped until the next newline and an error is returned,
+// so the unsanitized data is never forwarded. On error, the returned n is the number of bytes of p
+// before the failed document, the rest of p is already consumed (buffered or dropped) and must not be written again.
+func (m *MaskWriter) Write(p []byte) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dropped := 0
+	if m.skip {
+		idx := bytes.IndexByte(p, '\n')
+		if idx < 0 {
+			return len(p), nil
+		}
+
+		m.skip = false
+		dropped = idx + 1
+	}
+
+	// the buffer start with the previously buffered data, and p[dropped:] when the line is dropped
+	buffered := len(m.buf) - dropped
+	m.buf = append(m.buf, p[dropped:]...)
+	total := len(m.buf)
+	failedAt, err := m.process(false)
+	if err == nil {
+		// the incomplete document is the one left in the buffer
+		failedAt = total - len(m.buf)
+		err = m.checkSize()
+	}
+
+	if err != nil {
+		n := failedAt - buffered
+		if n < 0 {
+			n = 0
+		}
+		return n, err
+	}
+
+	return len(p), nil
+}
+
+// checkSize drop the incomplete document when it is larger than MaxBufferSize.
+func (m *MaskWriter) checkSize() error {
+	limit := m.MaxBufferSize
+	if limit <= 0 {
+		limit = DefaultMaxBufferSize
+	}
+
+	if len(m.buf) <= limit {
+		return nil
+	}
+
+	m.dropLine()
+	return fmt.Errorf("%w: incomplete document exceed %d bytes", ErrDocumentTooLarge, limit)
+}
+
+// Flush write the remaining buffered document, it is useful when the last document is a number
+// or literal without trailing newline, since it cannot be known whether the value is complete.
+func (m *MaskWriter) Flush() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.process(true)
+	return err
+}
+
+// process write every complete document in the buffer,
+// on error it returns the offset of the failed document relative to the buffer before the call.
+func (m *MaskWriter) process(flush bool) (int, error) {
+	offset := 0
+	for {
+		start := skipSpace(m.buf, 0)
+		if start >= len(m.buf) {
+			m.buf = m.buf[:0]
+			return 0, nil
+		}
+
+		end, err := scanValue(m.buf, start)
+		if errors.Is(err, ErrUnexpectedEnd) && !flush {
+			m.buf = m.buf[start:]
+			return 0, nil
+		}
+
+		if err != nil {
+			m.dropLine()
+			return offset + start, err
+		}
+
+		// number or literal may continue on the next Write
+		if end == len(m.buf) && !flush && m.buf[start] != '{' && m.buf[start] != '[' && m.buf[start] != '"' {
+			m.buf = m.buf[start:]
+			return 0, nil
+		}
+
+		out, err := m.processor.Process(context.Background(), m.buf[start:end])
+		if err != nil {
+			m.dropLine()
+			return offset + start, err
+		}
+
+		m.buf = m.buf[end:]
+		if _, err = m.w.Write(append(out, '\n')); err != nil {
+			return offset + start, err
+		}
+
+		offset += end
+	}
+}
+
+// dropLine discard the buffer until the next newline, which may come on the next Write.
+func (m *MaskWriter) dropLine() {
+	idx := bytes.IndexByte(m.buf, '\n')
+	if idx < 0 {
+		m.buf = m.buf[:0]
+		m.skip = true
+		return
+	}
+
+	m.buf = m.buf[idx+1:]
+}
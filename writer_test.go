@@ -0,0 +1,85 @@
+package jsonutil_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+func TestMaskWriter(t *testing.T) {
+	mask := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "password" {
+				return "xxx"
+			}
+
+			return info.Value
+		},
+	})
+
+	t.Run("ndjson and split document", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := jsonutil.NewMaskWriter(buf, mask)
+
+		n, err := w.Write([]byte("{\"password\":\"a\"}\n{\"pass"))
+		assert.NoError(t, err)
+		assert.Equal(t, 23, n)
+		assert.Equal(t, "{\"password\":\"xxx\"}\n", buf.String())
+
+		_, err = w.Write([]byte("word\":\"b\"}\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, "{\"password\":\"xxx\"}\n{\"password\":\"xxx\"}\n", buf.String())
+	})
+
+	t.Run("number needs flush", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := jsonutil.NewMaskWriter(buf, mask)
+
+		_, err := w.Write([]byte("12"))
+		assert.NoError(t, err)
+		_, err = w.Write([]byte("3"))
+		assert.NoError(t, err)
+		assert.Empty(t, buf.String())
+
+		assert.NoError(t, w.Flush())
+		assert.Equal(t, "123\n", buf.String())
+	})
+
+	t.Run("invalid line is dropped", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := jsonutil.NewMaskWriter(buf, mask)
+
+		_, err := w.Write([]byte("{\"password\" \"a\"}\n{\"password\":\"b\"}\n"))
+		assert.Error(t, err)
+		assert.Empty(t, buf.String())
+
+		assert.NoError(t, w.Flush())
+		assert.Equal(t, "{\"password\":\"xxx\"}\n", buf.String())
+
+		// n is the number of bytes before the failed document
+		buf.Reset()
+		n, err := w.Write([]byte("{\"password\":\"a\"}\n{\"password\" 1}\n"))
+		assert.Error(t, err)
+		assert.Equal(t, 17, n)
+		assert.Equal(t, "{\"password\":\"xxx\"}\n", buf.String())
+	})
+
+	t.Run("incomplete document exceed max buffer size", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := jsonutil.NewMaskWriter(buf, mask)
+		w.MaxBufferSize = 16
+
+		n, err := w.Write([]byte("1\n{\"password\":\"aaaaaaaaaa"))
+		assert.ErrorIs(t, err, jsonutil.ErrDocumentTooLarge)
+		assert.Equal(t, 2, n)
+
+		// the rest of dropped document is discarded until the next newline
+		n, err = w.Write([]byte("aaaaa\"}\n{\"password\":\"b\"}\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, 25, n)
+		assert.Equal(t, "1\n{\"password\":\"xxx\"}\n", buf.String())
+	})
+}
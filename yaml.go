@@ -0,0 +1,88 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLDocsToJSON read "---" separated YAML documents (i.e: Kubernetes manifests) and return them as JSON array,
+// one element per document. Empty document is skipped.
+func YAMLDocsToJSON(r io.Reader) ([]byte, error) {
+	dec := yaml.NewDecoder(r)
+	docs := make([]interface{}, 0)
+	for i := 0; ; i++ {
+		var v interface{}
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("jsonutil: YAML document %d: %w", i, err)
+		}
+
+		if v != nil {
+			docs = append(docs, stringKeys(v))
+		}
+	}
+
+	return json.Marshal(docs)
+}
+
+// JSONToYAMLDocs is the reverse of YAMLDocsToJSON, it write every element of JSON array as YAML document
+// separated by "---". JSON which is not array is written as single document.
+func JSONToYAMLDocs(doc []byte) ([]byte, error) {
+	var data interface{}
+	if err := decodeDocument(doc, &data); err != nil {
+		return nil, err
+	}
+
+	docs, ok := data.([]interface{})
+	if !ok {
+		docs = []interface{}{data}
+	}
+
+	var buf bytes.Buffer
+	for i, d := range docs {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+
+		b, err := yaml.Marshal(yamlNumbers(d))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// yamlNumbers convert json.Number into int64 or float64, otherwise YAML encoder write it as string.
+func yamlNumbers(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			val[k] = yamlNumbers(child)
+		}
+
+	case []interface{}:
+		for i, child := range val {
+			val[i] = yamlNumbers(child)
+		}
+
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+
+		f, _ := val.Float64()
+		return f
+	}
+
+	return v
+}
@@ -0,0 +1,58 @@
+package jsonutil_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+const sampleManifests = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+data:
+  LOG_LEVEL: debug
+---
+apiVersion: apps/v1
+kind: Deployment
+spec:
+  replicas: 3
+  paused: false
+---
+`
+
+func TestYAMLDocsToJSON(t *testing.T) {
+	out, err := jsonutil.YAMLDocsToJSON(strings.NewReader(sampleManifests))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "app"}, "data": {"LOG_LEVEL": "debug"}},
+		{"apiVersion": "apps/v1", "kind": "Deployment", "spec": {"replicas": 3, "paused": false}}
+	]`, string(out))
+
+	out, err = jsonutil.YAMLDocsToJSON(strings.NewReader(""))
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, string(out))
+}
+
+func TestJSONToYAMLDocs(t *testing.T) {
+	doc := `[{"kind": "ConfigMap", "data": {"replicas": 3, "ratio": 0.5}}, {"kind": "Secret"}]`
+	out, err := jsonutil.JSONToYAMLDocs([]byte(doc))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(out), "---\n"))
+
+	// round trip
+	back, err := jsonutil.YAMLDocsToJSON(strings.NewReader(string(out)))
+	assert.NoError(t, err)
+	assert.JSONEq(t, doc, string(back))
+
+	out, err = jsonutil.JSONToYAMLDocs([]byte(`{"a": 1}`))
+	assert.NoError(t, err)
+	back, err = jsonutil.YAMLDocsToJSON(strings.NewReader(string(out)))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"a": 1}]`, string(back))
+
+	_, err = jsonutil.JSONToYAMLDocs([]byte(`[`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+package zapjson
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/yusufsyaifudin/jsonutil"
+	"go.uber.org/zap"
+)
+
+type options struct {
+	processors []jsonutil.Processor
+}
+
+// Option configure the Field.
+type Option func(*options)
+
+// WithProcessor add processor (i.e: masking or truncation) to sanitize the JSON.
+// Processors are applied in the order they are added.
+func WithProcessor(p jsonutil.Processor) Option {
+	return func(o *options) {
+		o.processors = append(o.processors, p)
+	}
+}
+
+// Field return zap.Field which embed b as structured JSON object instead of escaped string,
+// so the log backend can index the sanitized fields.
+// Sanitization is done only when the entry is encoded, so it is skipped for filtered-out logs.
+// When b is not a valid JSON, the error is logged instead of the original value.
+func Field(key string, b []byte, opts ...Option) zap.Field {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return zap.Reflect(key, &safeJSON{b: b, processor: jsonutil.NewPipeline(o.processors...)})
+}
+
+type safeJSON struct {
+	b         []byte
+	processor jsonutil.Processor
+}
+
+var _ json.Marshaler = (*safeJSON)(nil)
+
+func (s *safeJSON) MarshalJSON() ([]byte, error) {
+	out, err := s.processor.Process(context.Background(), s.b)
+	if err != nil {
+		return json.Marshal("!ERROR: " + err.Error())
+	}
+
+	if !json.Valid(out) {
+		return json.Marshal("!ERROR: invalid JSON")
+	}
+
+	return out, nil
+}
@@ -0,0 +1,41 @@
+package zapjson_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"github.com/yusufsyaifudin/jsonutil/zapjson"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestField(t *testing.T) {
+	mask := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			if info.Key == "password" {
+				return "xxx"
+			}
+
+			return info.Value
+		},
+	})
+
+	buf := &bytes.Buffer{}
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"}),
+		zapcore.AddSync(buf),
+		zap.InfoLevel,
+	)
+	logger := zap.New(core)
+
+	logger.Info("request", zapjson.Field("body", []byte(`{"user":"a","password":"secret"}`), zapjson.WithProcessor(mask)))
+	assert.Equal(t, `{"msg":"request","body":{"password":"xxx","user":"a"}}`+"\n", buf.String())
+
+	buf.Reset()
+	logger.Info("request", zapjson.Field("body", []byte(`{"password":`), zapjson.WithProcessor(mask)))
+	assert.Contains(t, buf.String(), `"body":"!ERROR: `)
+	assert.NotContains(t, buf.String(), "password")
+}
@@ -0,0 +1,50 @@
+package zerologjson
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/rs/zerolog"
+	"github.com/yusufsyaifudin/jsonutil"
+)
+
+type options struct {
+	processors []jsonutil.Processor
+}
+
+// Option configure the RawSafe.
+type Option func(*options)
+
+// WithProcessor add processor (i.e: masking or truncation) to sanitize the JSON.
+// Processors are applied in the order they are added.
+func WithProcessor(p jsonutil.Processor) Option {
+	return func(o *options) {
+		o.processors = append(o.processors, p)
+	}
+}
+
+// RawSafe add the sanitized b to event e as raw JSON object instead of escaped string,
+// so the log backend can index the sanitized fields.
+// When b is not a valid JSON, the error is added instead of the original value.
+// Calling RawSafe on nil (disabled) event is no-op and the sanitization is skipped.
+func RawSafe(e *zerolog.Event, key string, b []byte, opts ...Option) *zerolog.Event {
+	if e == nil || !e.Enabled() {
+		return e
+	}
+
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	out, err := jsonutil.NewPipeline(o.processors...).Process(context.Background(), b)
+	if err != nil {
+		return e.Str(key, "!ERROR: "+err.Error())
+	}
+
+	if !json.Valid(out) {
+		return e.Str(key, "!ERROR: invalid JSON")
+	}
+
+	return e.RawJSON(key, out)
+}
@@ -0,0 +1,42 @@
+package zerologjson_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/yusufsyaifudin/jsonutil"
+	"github.com/yusufsyaifudin/jsonutil/zerologjson"
+)
+
+func TestRawSafe(t *testing.T) {
+	called := 0
+	mask := jsonutil.NewTransformer(jsonutil.Config{
+		StringTransformer: func(ctx context.Context, info jsonutil.KVInfo) string {
+			called++
+			if info.Key == "password" {
+				return "xxx"
+			}
+
+			return info.Value
+		},
+	})
+
+	buf := &bytes.Buffer{}
+	logger := zerolog.New(buf).Level(zerolog.InfoLevel)
+	body := []byte(`{"user":"a","password":"secret"}`)
+
+	zerologjson.RawSafe(logger.Debug(), "body", body, zerologjson.WithProcessor(mask)).Msg("filtered")
+	assert.Equal(t, 0, called)
+	assert.Empty(t, buf.String())
+
+	zerologjson.RawSafe(logger.Info(), "body", body, zerologjson.WithProcessor(mask)).Msg("request")
+	assert.Equal(t, `{"level":"info","body":{"password":"xxx","user":"a"},"message":"request"}`+"\n", buf.String())
+
+	buf.Reset()
+	zerologjson.RawSafe(logger.Info(), "body", []byte(`{"password":`), zerologjson.WithProcessor(mask)).Msg("request")
+	assert.Contains(t, buf.String(), `"body":"!ERROR: `)
+	assert.NotContains(t, buf.String(), "password")
+}